@@ -0,0 +1,67 @@
+// Copyright © 2022 Vulcanize, Inc
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	s "github.com/cerc-io/ipld-eth-server/v4/pkg/serve"
+)
+
+var gapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "find gaps in the eth index",
+	Long: `This command reports the ranges of block heights which are missing from the eth index,
+or whose headers have been validated fewer times than the given validation level`,
+	Run: func(cmd *cobra.Command, args []string) {
+		subCommand = cmd.CalledAs()
+		logWithCommand = *log.WithField("SubCommand", subCommand)
+		findGaps()
+	},
+}
+
+func findGaps() {
+	config, err := s.NewConfig()
+	if err != nil {
+		logWithCommand.Fatal(err)
+	}
+
+	retriever, err := eth.NewCIDRetriever(config.DB)
+	if err != nil {
+		logWithCommand.Fatal(err)
+	}
+	gaps, err := retriever.RetrieveGapsInData(viper.GetInt("validationLevel"))
+	if err != nil {
+		logWithCommand.Fatal(err)
+	}
+
+	for _, gap := range gaps {
+		logWithCommand.Infof("gap from %d to %d", gap.Start, gap.Stop)
+	}
+	logWithCommand.Infof("found %d gaps", len(gaps))
+}
+
+func init() {
+	rootCmd.AddCommand(gapsCmd)
+
+	addDatabaseFlags(gapsCmd)
+
+	gapsCmd.PersistentFlags().Int("validation-level", 1, "number of times a header must have been validated for its height not to be reported")
+	viper.BindPFlag("validationLevel", gapsCmd.PersistentFlags().Lookup("validation-level"))
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/mailgun/groupcache/v2"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -124,7 +125,7 @@ func startServers(server s.Server, settings *s.Config) error {
 
 	if settings.WSEnabled {
 		logWithCommand.Info("starting up WS server")
-		_, _, err := srpc.StartWSEndpoint(settings.WSEndpoint, server.APIs(), []string{"vdb", "net"}, nil)
+		_, _, err := srpc.StartWSEndpoint(settings.WSEndpoint, server.APIs(), []string{"vdb", "eth", "net"}, nil, rpc.HTTPTimeouts{})
 		if err != nil {
 			return err
 		}
@@ -134,7 +135,12 @@ func startServers(server s.Server, settings *s.Config) error {
 
 	if settings.HTTPEnabled {
 		logWithCommand.Info("starting up HTTP server")
-		_, err := srpc.StartHTTPEndpoint(settings.HTTPEndpoint, server.APIs(), []string{"vdb", "eth", "debug", "net"}, nil, []string{"*"}, rpc.HTTPTimeouts{})
+		var nodeHead srpc.NodeHeadRetriever
+		if settings.Client != nil {
+			nodeHead = ethclient.NewClient(settings.Client)
+		}
+		health := srpc.NewHealthHandler(server.Backend().Retriever, nodeHead, settings.HealthMaxLag)
+		_, err := srpc.StartHTTPEndpoint(settings.HTTPEndpoint, server.APIs(), []string{"vdb", "eth", "debug", "net"}, nil, []string{"*"}, rpc.HTTPTimeouts{}, health)
 		if err != nil {
 			return err
 		}
@@ -150,7 +156,15 @@ func startEthGraphQL(server s.Server, settings *s.Config) (graphQLServer *graphq
 		logWithCommand.Info("starting up ETH GraphQL server")
 		endPoint := settings.EthGraphqlEndpoint
 		if endPoint != "" {
-			graphQLServer, err = graphql.New(server.Backend(), endPoint, nil, []string{"*"}, rpc.HTTPTimeouts{})
+			graphQLServer, err = graphql.New(server.Backend(), graphql.Config{
+				Endpoint:        endPoint,
+				CORS:            settings.EthGraphqlCORS,
+				CORSMaxAge:      settings.EthGraphqlCORSMaxAge,
+				VHosts:          []string{"*"},
+				BlocksLogsLimit: settings.EthGraphqlBlocksLogsLimit,
+				FieldTimeout:    settings.EthGraphqlFieldTimeout,
+				LenientInput:    settings.EthGraphqlLenientInput,
+			})
 			if err != nil {
 				return
 			}
@@ -322,12 +336,24 @@ func init() {
 	// eth graphql and json-rpc parameters
 	serveCmd.PersistentFlags().Bool("eth-server-graphql", false, "turn on the eth graphql server")
 	serveCmd.PersistentFlags().String("eth-server-graphql-path", "", "endpoint url for eth graphql server (host:port)")
+	serveCmd.PersistentFlags().StringSlice("eth-server-graphql-cors", []string{}, "allowed CORS origins for the eth graphql server")
+	serveCmd.PersistentFlags().Int("eth-server-graphql-cors-max-age", 600, "seconds browsers may cache eth graphql CORS preflight results for (0 = not cached)")
+	serveCmd.PersistentFlags().Int64("eth-server-graphql-blocks-logs-limit", 0, "max number of blocks in a graphql blocks query multiplied by the logs selections on each block (0 = unlimited)")
+	serveCmd.PersistentFlags().String("eth-server-graphql-field-timeout", "0s", "max duration of each eth graphql resolver field, including the fields selected beneath it (0s = unbounded)")
+	serveCmd.PersistentFlags().Bool("eth-server-graphql-lenient-input", false, "ignore the unknown fields of eth graphql input objects instead of rejecting the query")
 	serveCmd.PersistentFlags().Bool("eth-server-http", true, "turn on the eth http json-rpc server")
 	serveCmd.PersistentFlags().String("eth-server-http-path", "", "endpoint url for eth http json-rpc server (host:port)")
 	serveCmd.PersistentFlags().Bool("eth-server-ws", false, "turn on the eth websocket json-rpc server")
 	serveCmd.PersistentFlags().String("eth-server-ws-path", "", "endpoint url for eth websocket json-rpc server (host:port)")
 	serveCmd.PersistentFlags().Bool("eth-server-ipc", false, "turn on the eth ipc json-rpc server")
 	serveCmd.PersistentFlags().String("eth-server-ipc-path", "", "path for eth ipc json-rpc server")
+	serveCmd.PersistentFlags().Bool("server-disable-state-subscriptions", false, "reject subscriptions requesting state data")
+	serveCmd.PersistentFlags().Bool("server-disable-storage-subscriptions", false, "reject subscriptions requesting storage data")
+	serveCmd.PersistentFlags().Bool("server-warm-up-head", false, "prefetch the head header and receipts on startup")
+	serveCmd.PersistentFlags().Int64("server-health-max-lag", 0, "max number of blocks the index can lag behind the proxied node before the health check fails (0 = unbounded)")
+	serveCmd.PersistentFlags().String("server-drain-timeout", "5s", "max time to wait on shutdown for in-flight subscription backfills to finish (0s = close them immediately)")
+	serveCmd.PersistentFlags().String("server-slow-consumer-policy", "block", "how subscriptions with a full channel are handled, closed immediately or after blocking for the timeout (close or block)")
+	serveCmd.PersistentFlags().String("server-slow-consumer-timeout", "1s", "max time a send to a full subscription channel blocks under the block policy")
 
 	// ipld and tracing graphql parameters
 	serveCmd.PersistentFlags().Bool("ipld-server-graphql", false, "turn on the ipld graphql server")
@@ -348,6 +374,25 @@ func init() {
 	serveCmd.PersistentFlags().Bool("eth-supports-state-diff", false, "whether the proxy ethereum client supports statediffing endpoints")
 	serveCmd.PersistentFlags().Bool("eth-forward-eth-calls", false, "whether to immediately forward eth_calls to proxy client")
 	serveCmd.PersistentFlags().Bool("eth-proxy-on-error", true, "whether to forward all failed calls to proxy client")
+	serveCmd.PersistentFlags().Int("eth-trace-max-call-depth", 0, "max call depth allowed in debug_traceCall (0 = unlimited)")
+	serveCmd.PersistentFlags().Uint64("eth-trace-max-opcodes", 0, "max number of opcodes executed in debug_traceCall (0 = unlimited)")
+	serveCmd.PersistentFlags().Uint64("eth-logs-max-age", 0, "max number of blocks behind head the fromBlock of an eth_getLogs query can be (0 = unlimited)")
+	serveCmd.PersistentFlags().Int("eth-logs-max-topics-per-position", 0, "max number of topics at each position of a log filter (0 = unlimited)")
+	serveCmd.PersistentFlags().Int("eth-logs-max-topics", 0, "max total number of topics in a log filter (0 = unlimited)")
+	serveCmd.PersistentFlags().Int64("eth-logs-max-block-range", 10000, "max number of blocks spanned by the block range of a graphql getLogs or other range query (0 = unlimited)")
+	serveCmd.PersistentFlags().Int("eth-logs-max-results", 0, "max number of logs served by a single query or retrieved from a single block, graphql getLogsPage truncates to it (0 = unlimited)")
+	serveCmd.PersistentFlags().String("eth-logs-order", "asc", "order in which logs are served, by block number and log index (asc or desc)")
+	serveCmd.PersistentFlags().Int("eth-txs-max-results", 0, "max number of transactions served by a graphql transactionsByAddress query (0 = unlimited)")
+	serveCmd.PersistentFlags().Int64("eth-blocks-max-open-range", 0, "max number of blocks in a graphql blocks range without an end (0 = unlimited)")
+	serveCmd.PersistentFlags().Bool("eth-blocks-truncate-open-range", false, "whether to truncate a graphql blocks range without an end to the limit instead of rejecting it")
+	serveCmd.PersistentFlags().String("eth-retriever-query-timeout", "0s", "maximum duration of a single retriever query (0s = no timeout)")
+	serveCmd.PersistentFlags().Bool("eth-balance-only-account-decode", false, "whether eth_getBalance decodes only the balance field of the account")
+	serveCmd.PersistentFlags().Bool("eth-proxy-uncle-blocks", false, "whether the full blocks of uncles are served from the proxy node")
+
+	// database replica flags
+	serveCmd.PersistentFlags().StringSlice("database-replicas", []string{}, "connection strings of read replicas to spread database connections across")
+	serveCmd.PersistentFlags().String("database-replica-mode", "round-robin", "how connections are spread across the database and its replicas (round-robin or primary-fallback)")
+	serveCmd.PersistentFlags().String("database-replica-retry-interval", "30s", "how long an unavailable database is dropped from rotation")
 
 	// groupcache flags
 	serveCmd.PersistentFlags().Bool("gcache-pool-enabled", false, "turn on the groupcache pool")
@@ -356,6 +401,7 @@ func init() {
 	serveCmd.PersistentFlags().Int("gcache-statedb-cache-size", 16, "state DB cache size in MB")
 	serveCmd.PersistentFlags().Int("gcache-statedb-cache-expiry", 60, "state DB cache expiry time in mins")
 	serveCmd.PersistentFlags().Int("gcache-statedb-log-stats-interval", 60, "state DB cache stats log interval in secs")
+	serveCmd.PersistentFlags().Int("gcache-header-cache-size", 1024, "number of decoded headers to cache (0 = disabled)")
 
 	// state validator flags
 	serveCmd.PersistentFlags().Bool("validator-enabled", false, "turn on the state validator")
@@ -365,6 +411,11 @@ func init() {
 	// eth graphql server
 	viper.BindPFlag("eth.server.graphql", serveCmd.PersistentFlags().Lookup("eth-server-graphql"))
 	viper.BindPFlag("eth.server.graphqlPath", serveCmd.PersistentFlags().Lookup("eth-server-graphql-path"))
+	viper.BindPFlag("eth.server.graphqlCors", serveCmd.PersistentFlags().Lookup("eth-server-graphql-cors"))
+	viper.BindPFlag("eth.server.graphqlCorsMaxAge", serveCmd.PersistentFlags().Lookup("eth-server-graphql-cors-max-age"))
+	viper.BindPFlag("eth.server.graphqlBlocksLogsLimit", serveCmd.PersistentFlags().Lookup("eth-server-graphql-blocks-logs-limit"))
+	viper.BindPFlag("eth.server.graphqlFieldTimeout", serveCmd.PersistentFlags().Lookup("eth-server-graphql-field-timeout"))
+	viper.BindPFlag("eth.server.graphqlLenientInput", serveCmd.PersistentFlags().Lookup("eth-server-graphql-lenient-input"))
 
 	// eth http json-rpc server
 	viper.BindPFlag("eth.server.http", serveCmd.PersistentFlags().Lookup("eth-server-http"))
@@ -377,6 +428,13 @@ func init() {
 	// eth ipc json-rpc server
 	viper.BindPFlag("eth.server.ipc", serveCmd.PersistentFlags().Lookup("eth-server-ipc"))
 	viper.BindPFlag("eth.server.ipcPath", serveCmd.PersistentFlags().Lookup("eth-server-ipc-path"))
+	viper.BindPFlag("server.disableStateSubscriptions", serveCmd.PersistentFlags().Lookup("server-disable-state-subscriptions"))
+	viper.BindPFlag("server.disableStorageSubscriptions", serveCmd.PersistentFlags().Lookup("server-disable-storage-subscriptions"))
+	viper.BindPFlag("server.warmUpHead", serveCmd.PersistentFlags().Lookup("server-warm-up-head"))
+	viper.BindPFlag("server.healthMaxLag", serveCmd.PersistentFlags().Lookup("server-health-max-lag"))
+	viper.BindPFlag("server.drainTimeout", serveCmd.PersistentFlags().Lookup("server-drain-timeout"))
+	viper.BindPFlag("server.slowConsumerPolicy", serveCmd.PersistentFlags().Lookup("server-slow-consumer-policy"))
+	viper.BindPFlag("server.slowConsumerTimeout", serveCmd.PersistentFlags().Lookup("server-slow-consumer-timeout"))
 
 	// ipld and tracing graphql parameters
 	viper.BindPFlag("ipld.server.graphql", serveCmd.PersistentFlags().Lookup("ipld-server-graphql"))
@@ -398,6 +456,25 @@ func init() {
 	viper.BindPFlag("ethereum.forwardEthCalls", serveCmd.PersistentFlags().Lookup("eth-forward-eth-calls"))
 	viper.BindPFlag("ethereum.forwardGetStorageAt", serveCmd.PersistentFlags().Lookup("eth-forward-get-storage-at"))
 	viper.BindPFlag("ethereum.proxyOnError", serveCmd.PersistentFlags().Lookup("eth-proxy-on-error"))
+	viper.BindPFlag("ethereum.traceMaxCallDepth", serveCmd.PersistentFlags().Lookup("eth-trace-max-call-depth"))
+	viper.BindPFlag("ethereum.traceMaxOpcodes", serveCmd.PersistentFlags().Lookup("eth-trace-max-opcodes"))
+	viper.BindPFlag("ethereum.logsMaxAge", serveCmd.PersistentFlags().Lookup("eth-logs-max-age"))
+	viper.BindPFlag("ethereum.logsMaxTopicsPerPosition", serveCmd.PersistentFlags().Lookup("eth-logs-max-topics-per-position"))
+	viper.BindPFlag("ethereum.logsMaxTopics", serveCmd.PersistentFlags().Lookup("eth-logs-max-topics"))
+	viper.BindPFlag("ethereum.logsMaxBlockRange", serveCmd.PersistentFlags().Lookup("eth-logs-max-block-range"))
+	viper.BindPFlag("ethereum.logsMaxResults", serveCmd.PersistentFlags().Lookup("eth-logs-max-results"))
+	viper.BindPFlag("ethereum.logsOrder", serveCmd.PersistentFlags().Lookup("eth-logs-order"))
+	viper.BindPFlag("ethereum.txsMaxResults", serveCmd.PersistentFlags().Lookup("eth-txs-max-results"))
+	viper.BindPFlag("ethereum.blocksMaxOpenRange", serveCmd.PersistentFlags().Lookup("eth-blocks-max-open-range"))
+	viper.BindPFlag("ethereum.blocksTruncateOpenRange", serveCmd.PersistentFlags().Lookup("eth-blocks-truncate-open-range"))
+	viper.BindPFlag("ethereum.retrieverQueryTimeout", serveCmd.PersistentFlags().Lookup("eth-retriever-query-timeout"))
+	viper.BindPFlag("ethereum.balanceOnlyAccountDecode", serveCmd.PersistentFlags().Lookup("eth-balance-only-account-decode"))
+	viper.BindPFlag("ethereum.proxyUncleBlocks", serveCmd.PersistentFlags().Lookup("eth-proxy-uncle-blocks"))
+
+	// database replica flags
+	viper.BindPFlag("database.replicas", serveCmd.PersistentFlags().Lookup("database-replicas"))
+	viper.BindPFlag("database.replicaMode", serveCmd.PersistentFlags().Lookup("database-replica-mode"))
+	viper.BindPFlag("database.replicaRetryInterval", serveCmd.PersistentFlags().Lookup("database-replica-retry-interval"))
 
 	// groupcache flags
 	viper.BindPFlag("groupcache.pool.enabled", serveCmd.PersistentFlags().Lookup("gcache-pool-enabled"))
@@ -406,6 +483,7 @@ func init() {
 	viper.BindPFlag("groupcache.statedb.cacheSizeInMB", serveCmd.PersistentFlags().Lookup("gcache-statedb-cache-size"))
 	viper.BindPFlag("groupcache.statedb.cacheExpiryInMins", serveCmd.PersistentFlags().Lookup("gcache-statedb-cache-expiry"))
 	viper.BindPFlag("groupcache.statedb.logStatsIntervalInSecs", serveCmd.PersistentFlags().Lookup("gcache-statedb-log-stats-interval"))
+	viper.BindPFlag("groupcache.headerCacheSize", serveCmd.PersistentFlags().Lookup("gcache-header-cache-size"))
 
 	// state validator flags
 	viper.BindPFlag("validator.enabled", serveCmd.PersistentFlags().Lookup("validator-enabled"))
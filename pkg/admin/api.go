@@ -0,0 +1,69 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+)
+
+// APIName is the namespace for the watcher's admin api
+const APIName = "admin"
+
+// APIVersion is the version of the watcher's admin api
+const APIVersion = "0.0.1"
+
+// RootVerification is the result of checking a root rebuilt from indexed data against the header
+type RootVerification struct {
+	Root  common.Hash `json:"root"`
+	Valid bool        `json:"valid"`
+}
+
+// PrivateAdminAPI is the admin namespace API; it exposes integrity checks over the indexed data
+type PrivateAdminAPI struct {
+	B *eth.Backend
+}
+
+// NewPrivateAdminAPI creates a new PrivateAdminAPI with the provided underlying Backend
+func NewPrivateAdminAPI(b *eth.Backend) *PrivateAdminAPI {
+	return &PrivateAdminAPI{
+		B: b,
+	}
+}
+
+// VerifyReceiptsRoot rebuilds the receipts trie of the block from its indexed receipts
+// and returns the computed root along with whether it matches the receipts root in the indexed header
+func (api *PrivateAdminAPI) VerifyReceiptsRoot(ctx context.Context, blockHash common.Hash) (*RootVerification, error) {
+	header, err := api.B.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.B.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	root := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	return &RootVerification{
+		Root:  root,
+		Valid: root == header.ReceiptHash,
+	}, nil
+}
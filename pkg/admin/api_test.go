@@ -0,0 +1,107 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package admin_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/jmoiron/sqlx"
+	"github.com/mailgun/groupcache/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/admin"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+var _ = Describe("API", func() {
+	var (
+		ctx = context.Background()
+		db  *sqlx.DB
+		api *admin.PrivateAdminAPI
+	)
+
+	BeforeEach(func() {
+		db = shared.SetupDB()
+		chainConfig := *params.TestChainConfig
+		indexer := shared.SetupTestStateDiffIndexer(ctx, &chainConfig, test_helpers.Genesis.Hash())
+		tx, err := indexer.PushBlock(test_helpers.MockBlock, test_helpers.MockReceipts, test_helpers.MockBlock.Difficulty())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tx.Submit(err)).To(Succeed())
+
+		backend, err := eth.NewEthBackend(db, &eth.Config{
+			ChainConfig: &chainConfig,
+			VMConfig:    vm.Config{},
+			RPCGasCap:   big.NewInt(10000000000),
+			GroupCacheConfig: &shared.GroupCacheConfig{
+				StateDB: shared.GroupConfig{
+					Name:              "admin_api_test",
+					CacheSizeInMB:     8,
+					CacheExpiryInMins: 60,
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		api = admin.NewPrivateAdminAPI(backend)
+	})
+	AfterEach(func() {
+		groupcache.DeregisterGroup("admin_api_test")
+		shared.TearDownDB(db)
+	})
+
+	Describe("VerifyReceiptsRoot", func() {
+		It("Returns the receipts root for a block whose indexed receipts are intact", func() {
+			res, err := api.VerifyReceiptsRoot(ctx, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Valid).To(BeTrue())
+			Expect(res.Root).To(Equal(test_helpers.MockBlock.ReceiptHash()))
+		})
+
+		It("Reports a mismatch for a block whose indexed receipts are corrupted", func() {
+			// overwrite the first receipt's leaf node with the second receipt's
+			var rctData []byte
+			err := db.Get(&rctData, `SELECT data FROM public.blocks
+					INNER JOIN eth.receipt_cids ON (
+						receipt_cids.leaf_mh_key = blocks.key
+						AND receipt_cids.block_number = blocks.block_number
+					)
+				WHERE receipt_cids.tx_id = $1`, test_helpers.MockTransactions[1].Hash().String())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = db.Exec(`UPDATE public.blocks SET data = $1
+				FROM eth.receipt_cids
+				WHERE receipt_cids.leaf_mh_key = blocks.key
+					AND receipt_cids.block_number = blocks.block_number
+					AND receipt_cids.tx_id = $2`, rctData, test_helpers.MockTransactions[0].Hash().String())
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := api.VerifyReceiptsRoot(ctx, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Valid).To(BeFalse())
+			Expect(res.Root).ToNot(Equal(test_helpers.MockBlock.ReceiptHash()))
+		})
+
+		It("Returns an error for a block which is not indexed", func() {
+			_, err := api.VerifyReceiptsRoot(ctx, test_helpers.MockChild.Hash())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,158 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+)
+
+const (
+	// defaultTraceTimeout is the amount of time a single trace is allowed to run, matching geth's default
+	defaultTraceTimeout = 5 * time.Second
+)
+
+// TraceCallConfig is the config for the debug_traceCall API
+type TraceCallConfig struct {
+	tracers.TraceConfig
+	StateOverrides *eth.StateOverride
+}
+
+// API is the debug namespace API; it exposes the geth tracers API with guards applied to debug_traceCall
+type API struct {
+	*tracers.API
+	backend *Backend
+	guard   GuardConfig
+}
+
+// NewAPI creates a new debug API which applies the provided guards to traced calls
+func NewAPI(b *Backend, guard GuardConfig) *API {
+	return &API{
+		API:     tracers.NewAPI(b),
+		backend: b,
+		guard:   guard,
+	}
+}
+
+// ReceiptsAPI exposes the raw receipts of the debug namespace
+type ReceiptsAPI struct {
+	backend *Backend
+}
+
+// NewReceiptsAPI creates a new ReceiptsAPI
+func NewReceiptsAPI(b *Backend) *ReceiptsAPI {
+	return &ReceiptsAPI{backend: b}
+}
+
+// GetRawReceipts returns the consensus encoded receipts of a block, as they are indexed.
+func (api *ReceiptsAPI) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
+	return api.backend.GetRawReceipts(ctx, blockNrOrHash)
+}
+
+// APIs returns the debug namespace rpc descriptors; the guarded API is only used when a guard is configured
+func APIs(b *Backend, guard GuardConfig) []rpc.API {
+	receiptsAPI := rpc.API{
+		Namespace: "debug",
+		Service:   NewReceiptsAPI(b),
+	}
+	if !guard.Enabled() {
+		return append(tracers.APIs(b), receiptsAPI)
+	}
+	return []rpc.API{
+		{
+			Namespace: "debug",
+			Service:   NewAPI(b, guard),
+		},
+		receiptsAPI,
+	}
+}
+
+// TraceCall lets you trace a given eth_call. It collects the structured logs
+// created during the execution of EVM if the given transaction was added on
+// top of the provided block and returns them as a JSON object.
+// Execution is aborted with an error if the configured call depth or opcode guards are exceeded.
+func (api *API) TraceCall(ctx context.Context, args eth.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		return nil, errors.New("tracing on top of pending is not supported")
+	}
+	statedb, header, err := api.backend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if statedb == nil || header == nil {
+		return nil, errors.New("state or header not found")
+	}
+	if config == nil {
+		config = &TraceCallConfig{}
+	}
+	if err := config.StateOverrides.Apply(statedb); err != nil {
+		return nil, err
+	}
+	msg, err := args.ToMessage(api.backend.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+
+	// Default tracer is the struct logger
+	var tracer tracers.Tracer = logger.NewStructLogger(config.Config)
+	if config.Tracer != nil {
+		tracer, err = tracers.New(*config.Tracer, new(tracers.Context), config.TracerConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	guard := NewGuardTracer(tracer, api.guard)
+
+	timeout := defaultTraceTimeout
+	if config.Timeout != nil {
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			tracer.Stop(errors.New("execution timeout"))
+		}
+	}()
+	defer cancel()
+
+	vmConfig := api.backend.Config.VMConfig
+	vmConfig.Debug = true
+	vmConfig.Tracer = guard
+	vmctx := core.NewEVMBlockContext(header, &api.backend.Backend, nil)
+	vmenv := vm.NewEVM(vmctx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vmConfig)
+	if _, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	if err := guard.Err(); err != nil {
+		return nil, err
+	}
+	return tracer.GetResult()
+}
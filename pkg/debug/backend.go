@@ -19,7 +19,9 @@ package debug
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -28,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
 )
 
 var _ tracers.Backend = &Backend{}
@@ -52,3 +55,42 @@ func (b *Backend) StateAtBlock(ctx context.Context, block *types.Block, reexec u
 func (b *Backend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (core.Message, vm.BlockContext, *state.StateDB, error) {
 	return nil, vm.BlockContext{}, nil, errMethodNotSupported
 }
+
+// GetRawReceipts returns the consensus encoded receipts of the block with the provided number or hash, as they are
+// stored in the receipt IPLDs, without decoding them. A block with no receipts returns an empty slice.
+func (b *Backend) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
+	header, err := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %s not found", blockNrOrHash.String())
+	}
+
+	var rcts [][]byte
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		tx, err := b.DB.Beginx()
+		if err != nil {
+			return nil, err
+		}
+		_, rcts, _, err = b.IPLDRetriever.RetrieveReceiptsByBlockHash(tx, hash)
+		if err != nil {
+			shared.Rollback(tx)
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	} else {
+		_, rcts, err = b.IPLDRetriever.RetrieveReceiptsByBlockNumberFast(header.Number.Uint64())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := make([]hexutil.Bytes, len(rcts))
+	for i, rct := range rcts {
+		ret[i] = rct
+	}
+	return ret, nil
+}
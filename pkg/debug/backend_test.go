@@ -0,0 +1,124 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package debug_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/jmoiron/sqlx"
+	"github.com/mailgun/groupcache/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/debug"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+var _ = Describe("Backend", func() {
+	var (
+		ctx        = context.Background()
+		db         *sqlx.DB
+		backend    *debug.Backend
+		emptyBlock *types.Block
+	)
+	BeforeEach(func() {
+		db = shared.SetupDB()
+		ethBackend, err := eth.NewEthBackend(db, &eth.Config{
+			ChainConfig: params.TestChainConfig,
+			VMConfig:    vm.Config{},
+			RPCGasCap:   big.NewInt(10000000000),
+			GroupCacheConfig: &shared.GroupCacheConfig{
+				StateDB: shared.GroupConfig{
+					Name:              "debug_backend_test",
+					CacheSizeInMB:     8,
+					CacheExpiryInMins: 60,
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		backend = &debug.Backend{Backend: *ethBackend}
+
+		// a child of MockBlock without transactions
+		emptyBlock = types.NewBlock(&types.Header{
+			ParentHash: test_helpers.MockBlock.Hash(),
+			Number:     big.NewInt(2),
+			Difficulty: big.NewInt(1),
+			Extra:      []byte("empty"),
+		}, nil, nil, nil, new(trie.Trie))
+
+		indexer := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+		for _, block := range []*types.Block{test_helpers.MockBlock, emptyBlock} {
+			var rcts types.Receipts
+			if block == test_helpers.MockBlock {
+				rcts = test_helpers.MockReceipts
+			}
+			tx, err := indexer.PushBlock(block, rcts, block.Difficulty())
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+	AfterEach(func() {
+		groupcache.DeregisterGroup("debug_backend_test")
+		shared.TearDownDB(db)
+	})
+
+	Describe("GetRawReceipts", func() {
+		It("Retrieves the encoded receipts of a block by hash and by number, matching the re-encoded decoded receipts", func() {
+			receipts, err := backend.GetReceipts(ctx, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(receipts)).To(Equal(len(test_helpers.MockReceipts)))
+
+			byHash, err := backend.GetRawReceipts(ctx, rpc.BlockNumberOrHashWithHash(test_helpers.MockBlock.Hash(), false))
+			Expect(err).ToNot(HaveOccurred())
+			byNumber, err := backend.GetRawReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(test_helpers.MockBlock.Number().Int64())))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(byNumber).To(Equal(byHash))
+
+			Expect(len(byHash)).To(Equal(len(receipts)))
+			for i, receipt := range receipts {
+				encoded, err := receipt.MarshalBinary()
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]byte(byHash[i])).To(Equal(encoded))
+			}
+		})
+
+		It("Retrieves no receipts for a block without transactions", func() {
+			rcts, err := backend.GetRawReceipts(ctx, rpc.BlockNumberOrHashWithHash(emptyBlock.Hash(), false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rcts).ToNot(BeNil())
+			Expect(rcts).To(BeEmpty())
+		})
+
+		It("Returns an error for a block that is not indexed", func() {
+			_, err := backend.GetRawReceipts(ctx, rpc.BlockNumberOrHashWithHash(common.HexToHash("0x01"), false))
+			Expect(err).To(HaveOccurred())
+
+			_, err = backend.GetRawReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(100)))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
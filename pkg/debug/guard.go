@@ -0,0 +1,107 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+// GuardConfig holds the limits applied to traced executions; a zero value disables the respective guard
+type GuardConfig struct {
+	MaxCallDepth int
+	MaxOpcodes   uint64
+}
+
+// Enabled returns true if any of the guards are set
+func (gc GuardConfig) Enabled() bool {
+	return gc.MaxCallDepth > 0 || gc.MaxOpcodes > 0
+}
+
+// GuardTracer wraps a tracer and aborts the traced execution once the configured
+// call depth or number of executed opcodes is exceeded
+type GuardTracer struct {
+	tracers.Tracer
+	config GuardConfig
+
+	env     *vm.EVM
+	depth   int
+	opcodes uint64
+	err     error
+}
+
+// NewGuardTracer wraps the provided tracer with the provided guards
+func NewGuardTracer(tracer tracers.Tracer, config GuardConfig) *GuardTracer {
+	return &GuardTracer{Tracer: tracer, config: config}
+}
+
+// Err returns the error for the guard that was tripped, if any
+func (t *GuardTracer) Err() error {
+	return t.err
+}
+
+// CaptureStart implements vm.EVMLogger
+func (t *GuardTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.depth = 1
+	t.Tracer.CaptureStart(env, from, to, create, input, gas, value)
+}
+
+// CaptureEnter implements vm.EVMLogger
+func (t *GuardTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.depth++
+	if t.config.MaxCallDepth > 0 && t.depth > t.config.MaxCallDepth {
+		t.abort(fmt.Errorf("trace exceeded max call depth of %d", t.config.MaxCallDepth))
+	}
+	t.Tracer.CaptureEnter(typ, from, to, input, gas, value)
+}
+
+// CaptureExit implements vm.EVMLogger
+func (t *GuardTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.depth--
+	t.Tracer.CaptureExit(output, gasUsed, err)
+}
+
+// CaptureState implements vm.EVMLogger
+func (t *GuardTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	t.opcodes++
+	if t.config.MaxOpcodes > 0 && t.opcodes > t.config.MaxOpcodes {
+		t.abort(fmt.Errorf("trace exceeded max opcode count of %d", t.config.MaxOpcodes))
+	}
+	if t.err != nil {
+		// the EVM only checks for cancellation on jumps, so drain the gas of the
+		// executing frame to unwind the call stack at the next operation
+		scope.Contract.Gas = 0
+		return
+	}
+	t.Tracer.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+}
+
+func (t *GuardTracer) abort(err error) {
+	if t.err != nil {
+		return
+	}
+	t.err = err
+	t.Tracer.Stop(err)
+	if t.env != nil {
+		t.env.Cancel()
+	}
+}
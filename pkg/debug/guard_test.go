@@ -0,0 +1,84 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package debug_test
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/params"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/debug"
+)
+
+var (
+	callerAddr    = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	recursiveAddr = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	// PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 ADDRESS GAS CALL STOP
+	// calls itself until the call depth or gas is exhausted
+	recursiveCode = common.Hex2Bytes("60006000600060006000305af100")
+)
+
+func traceRecursiveCall(config debug.GuardConfig) (*debug.GuardTracer, error) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		return nil, err
+	}
+	statedb.SetCode(recursiveAddr, recursiveCode)
+
+	tracer := debug.NewGuardTracer(logger.NewStructLogger(nil), config)
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		BlockNumber: big.NewInt(1),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    30000000,
+	}
+	evm := vm.NewEVM(blockCtx, vm.TxContext{Origin: callerAddr, GasPrice: big.NewInt(0)}, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	_, _, err = evm.Call(vm.AccountRef(callerAddr), recursiveAddr, nil, 30000000, big.NewInt(0))
+	return tracer, err
+}
+
+var _ = Describe("GuardTracer", func() {
+	It("Aborts a recursive call once the max call depth is exceeded", func() {
+		tracer, err := traceRecursiveCall(debug.GuardConfig{MaxCallDepth: 8})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tracer.Err()).To(HaveOccurred())
+		Expect(tracer.Err().Error()).To(ContainSubstring("max call depth of 8"))
+	})
+
+	It("Aborts a recursive call once the max opcode count is exceeded", func() {
+		tracer, err := traceRecursiveCall(debug.GuardConfig{MaxOpcodes: 100})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tracer.Err()).To(HaveOccurred())
+		Expect(tracer.Err().Error()).To(ContainSubstring("max opcode count of 100"))
+	})
+
+	It("Does not interfere with executions within the configured limits", func() {
+		tracer, err := traceRecursiveCall(debug.GuardConfig{MaxCallDepth: 2048, MaxOpcodes: 1000000})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tracer.Err()).ToNot(HaveOccurred())
+	})
+})
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -24,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"sort"
 	"strconv"
 	"time"
 
@@ -33,11 +35,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -48,6 +53,17 @@ import (
 
 const (
 	defaultEVMTimeout = 30 * time.Second
+
+	// maxFeeHistory is the maximum number of blocks returned by eth_feeHistory, matching geth's default
+	maxFeeHistory = 1024
+
+	// maxAccountStateSlots is the maximum number of storage slots retrieved by a single eth_getAccountState call
+	maxAccountStateSlots = 1024
+
+	// maxBalanceMultiAddresses is the maximum number of addresses whose balances are retrieved by a single eth_getBalanceMulti call
+	maxBalanceMultiAddresses = 1024
+
+	pendingTxsChanBufferSize = 128
 )
 
 // APIName is the namespace for the watcher's eth api
@@ -64,6 +80,8 @@ type APIConfig struct {
 	ProxyOnError        bool // turn on regular proxy fall-through on errors; needed to test difference between direct and indirect fall-through
 
 	StateDiffTimeout time.Duration
+
+	LogsMaxAge uint64 // if non-zero, reject eth_getLogs queries with a fromBlock more than this many blocks behind head
 }
 
 // PublicEthAPI is the eth namespace API
@@ -175,10 +193,12 @@ func (pea *PublicEthAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockN
 		return pea.rpcMarshalBlock(block, true, fullTx)
 	}
 
+	// a block missing from the index has no indexed total difficulty either, so the proxy's response is served as is
 	if pea.config.ProxyOnError {
-		if block, err := pea.ethClient.BlockByNumber(ctx, big.NewInt(number.Int64())); block != nil && err == nil {
+		var res map[string]interface{}
+		if err := pea.rpc.CallContext(ctx, &res, "eth_getBlockByNumber", number, fullTx); res != nil && err == nil {
 			go pea.writeStateDiffAt(number.Int64())
-			return pea.rpcMarshalBlock(block, true, fullTx)
+			return res, nil
 		}
 	}
 
@@ -193,10 +213,12 @@ func (pea *PublicEthAPI) GetBlockByHash(ctx context.Context, hash common.Hash, f
 		return pea.rpcMarshalBlock(block, true, fullTx)
 	}
 
+	// the proxy's response is served as is, like GetBlockByNumber's
 	if pea.config.ProxyOnError {
-		if block, err := pea.ethClient.BlockByHash(ctx, hash); block != nil && err == nil {
+		var res map[string]interface{}
+		if err := pea.rpc.CallContext(ctx, &res, "eth_getBlockByHash", hash, fullTx); res != nil && err == nil {
 			go pea.writeStateDiffFor(hash)
-			return pea.rpcMarshalBlock(block, true, fullTx)
+			return res, nil
 		}
 	}
 
@@ -413,8 +435,8 @@ func (pea *PublicEthAPI) GetTransactionByBlockHashAndIndex(ctx context.Context,
 
 // GetRawTransactionByBlockNumberAndIndex returns the bytes of the transaction for the given block number and index.
 func (pea *PublicEthAPI) GetRawTransactionByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) hexutil.Bytes {
-	if block, _ := pea.B.BlockByNumber(ctx, blockNr); block != nil {
-		return newRPCRawTransactionFromBlockIndex(block, uint64(index))
+	if header, _ := pea.B.HeaderByNumber(ctx, blockNr); header != nil {
+		return pea.localGetRawTransactionByBlockHashAndIndex(header.Hash(), uint64(index))
 	}
 	if pea.config.ProxyOnError {
 		var tx hexutil.Bytes
@@ -428,8 +450,8 @@ func (pea *PublicEthAPI) GetRawTransactionByBlockNumberAndIndex(ctx context.Cont
 
 // GetRawTransactionByBlockHashAndIndex returns the bytes of the transaction for the given block hash and index.
 func (pea *PublicEthAPI) GetRawTransactionByBlockHashAndIndex(ctx context.Context, blockHash common.Hash, index hexutil.Uint) hexutil.Bytes {
-	if block, _ := pea.B.BlockByHash(ctx, blockHash); block != nil {
-		return newRPCRawTransactionFromBlockIndex(block, uint64(index))
+	if header, _ := pea.B.HeaderByHash(ctx, blockHash); header != nil {
+		return pea.localGetRawTransactionByBlockHashAndIndex(blockHash, uint64(index))
 	}
 	if pea.config.ProxyOnError {
 		var tx hexutil.Bytes
@@ -441,6 +463,19 @@ func (pea *PublicEthAPI) GetRawTransactionByBlockHashAndIndex(ctx context.Contex
 	return nil
 }
 
+// localGetRawTransactionByBlockHashAndIndex returns the indexed bytes of the transaction at the index of an indexed block,
+// or nil if the block has no transaction at that index
+func (pea *PublicEthAPI) localGetRawTransactionByBlockHashAndIndex(blockHash common.Hash, index uint64) hexutil.Bytes {
+	_, raw, err := pea.B.IPLDRetriever.RetrieveTransactionByBlockHashAndIndex(blockHash, index)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Errorf("error retrieving transaction %d of block %s: %s", index, blockHash.Hex(), err)
+		}
+		return nil
+	}
+	return raw
+}
+
 // GetTransactionByHash returns the transaction for the given hash
 // eth ipld-eth-server cannot currently handle pending/tx_pool txs
 func (pea *PublicEthAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (*RPCTransaction, error) {
@@ -465,10 +500,10 @@ func (pea *PublicEthAPI) GetTransactionByHash(ctx context.Context, hash common.H
 
 // GetRawTransactionByHash returns the bytes of the transaction for the given hash.
 func (pea *PublicEthAPI) GetRawTransactionByHash(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
-	// Retrieve a finalized transaction, or a pooled otherwise
-	tx, _, _, _, err := pea.B.GetTransaction(ctx, hash)
-	if tx != nil && err == nil {
-		return rlp.EncodeToBytes(tx)
+	// Retrieve a finalized transaction, as it was indexed
+	raw, err := pea.B.GetRawTransaction(ctx, hash)
+	if raw != nil && err == nil {
+		return raw, nil
 	}
 	if pea.config.ProxyOnError {
 		var tx hexutil.Bytes
@@ -481,7 +516,7 @@ func (pea *PublicEthAPI) GetRawTransactionByHash(ctx context.Context, hash commo
 }
 
 // accessListResult returns an optional accesslist
-// Its the result of the `debug_createAccessList` RPC call.
+// Its the result of the `eth_createAccessList` RPC call.
 // It contains an error if the transaction itself failed.
 type accessListResult struct {
 	Accesslist *types.AccessList `json:"accessList"`
@@ -489,17 +524,35 @@ type accessListResult struct {
 	GasUsed    hexutil.Uint64    `json:"gasUsed"`
 }
 
-// CreateAccessList creates a EIP-2930 type AccessList for the given transaction.
-// Reexec and BlockNrOrHash can be specified to create the accessList on top of a certain state.
-func (pea *PublicEthAPI) CreateAccessList(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*accessListResult, error) {
-	if pea.rpc != nil {
+// CreateAccessList creates an EIP-2930 type AccessList for the given transaction, on top of the state
+// of the provided block or of the latest block if none is provided.
+// If the transaction reverts, the access list of the reverted execution is returned along with the error.
+func (pea *PublicEthAPI) CreateAccessList(ctx context.Context, args CallArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*accessListResult, error) {
+	if pea.config.ForwardEthCalls {
 		var res *accessListResult
-		if err := pea.rpc.CallContext(ctx, &res, "eth_createAccessList", args, blockNrOrHash); err != nil {
-			return nil, err
+		err := pea.rpc.CallContext(ctx, &res, "eth_createAccessList", args, blockNrOrHash)
+		return res, err
+	}
+
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	acl, gasUsed, vmErr, err := AccessList(ctx, pea.B, bNrOrHash, args, defaultEVMTimeout, pea.B.Config.RPCGasCap.Uint64())
+	if err != nil {
+		if pea.config.ProxyOnError {
+			var res *accessListResult
+			if err := pea.rpc.CallContext(ctx, &res, "eth_createAccessList", args, blockNrOrHash); res != nil && err == nil {
+				return res, nil
+			}
 		}
-		return res, nil
+		return nil, err
+	}
+	result := &accessListResult{Accesslist: &acl, GasUsed: hexutil.Uint64(gasUsed)}
+	if vmErr != nil {
+		result.Error = vmErr.Error()
 	}
-	return nil, RequiresProxyError{method: "eth_createAccessList"}
+	return result, nil
 }
 
 type feeHistoryResult struct {
@@ -510,15 +563,157 @@ type feeHistoryResult struct {
 }
 
 // FeeHistory returns the fee market history.
+// The base fees, gas used ratios and reward percentiles are computed from the indexed canonical blocks,
+// the reward percentiles are weighted by the gas used by each transaction as in geth.
 func (pea *PublicEthAPI) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
-	if pea.rpc != nil {
+	res, err := pea.localFeeHistory(ctx, uint64(blockCount), lastBlock, rewardPercentiles)
+	if err == nil {
+		return res, nil
+	}
+	if pea.config.ProxyOnError {
 		var res *feeHistoryResult
-		if err := pea.rpc.CallContext(ctx, &res, "eth_feeHistory", blockCount, lastBlock, rewardPercentiles); err != nil {
+		if err := pea.rpc.CallContext(ctx, &res, "eth_feeHistory", blockCount, lastBlock, rewardPercentiles); err == nil {
+			return res, nil
+		}
+	}
+	return nil, err
+}
+
+func (pea *PublicEthAPI) localFeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("%w: %f", errInvalidPercentile, p)
+		}
+		if i > 0 && p < rewardPercentiles[i-1] {
+			return nil, fmt.Errorf("%w: #%d:%f > #%d:%f", errInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
+		}
+	}
+	if blockCount == 0 {
+		return &feeHistoryResult{OldestBlock: (*hexutil.Big)(new(big.Int))}, nil
+	}
+	if blockCount > maxFeeHistory {
+		blockCount = maxFeeHistory
+	}
+	lastHeader, err := pea.B.HeaderByNumber(ctx, lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	last := lastHeader.Number.Uint64()
+	if blockCount > last+1 {
+		blockCount = last + 1
+	}
+	oldest := last + 1 - blockCount
+
+	// Begin tx
+	tx, err := pea.B.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			shared.Rollback(tx)
+			panic(p)
+		} else if err != nil {
+			shared.Rollback(tx)
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	res := &feeHistoryResult{
+		OldestBlock:  (*hexutil.Big)(new(big.Int).SetUint64(oldest)),
+		BaseFee:      make([]*hexutil.Big, blockCount+1),
+		GasUsedRatio: make([]float64, blockCount),
+	}
+	if len(rewardPercentiles) != 0 {
+		res.Reward = make([][]*hexutil.Big, blockCount)
+	}
+	config := pea.B.ChainConfig()
+	for i := uint64(0); i < blockCount; i++ {
+		header := lastHeader
+		if number := oldest + i; number != last {
+			header, err = pea.B.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		baseFee := new(big.Int)
+		if header.BaseFee != nil {
+			baseFee = header.BaseFee
+		}
+		res.BaseFee[i] = (*hexutil.Big)(baseFee)
+		if header.GasLimit > 0 {
+			res.GasUsedRatio[i] = float64(header.GasUsed) / float64(header.GasLimit)
+		}
+		if i == blockCount-1 {
+			nextBaseFee := new(big.Int)
+			if config.IsLondon(new(big.Int).Add(header.Number, common.Big1)) {
+				nextBaseFee = misc.CalcBaseFee(config, header)
+			}
+			res.BaseFee[i+1] = (*hexutil.Big)(nextBaseFee)
+		}
+
+		if len(rewardPercentiles) == 0 {
+			continue
+		}
+		var txs types.Transactions
+		txs, err = pea.B.GetTransactionsByBlockHashAndNumber(tx, header.Hash(), header.Number.Uint64())
+		if err != nil {
 			return nil, err
 		}
-		return res, nil
+		var rcts types.Receipts
+		rcts, err = pea.B.GetReceiptsByBlockHashAndNumber(tx, header.Hash(), header.Number.Uint64())
+		if err != nil {
+			return nil, err
+		}
+		if len(rcts) != len(txs) {
+			err = fmt.Errorf("block %d has %d transactions but %d receipts", header.Number.Uint64(), len(txs), len(rcts))
+			return nil, err
+		}
+		res.Reward[i] = feeHistoryRewards(header, txs, rcts, rewardPercentiles)
 	}
-	return nil, RequiresProxyError{method: "eth_feeHistory"}
+	return res, nil
+}
+
+// feeHistoryRewards returns the effective tips at the provided percentiles of the gas used in the block,
+// with the transactions sorted by ascending effective tip
+func feeHistoryRewards(header *types.Header, txs types.Transactions, rcts types.Receipts, percentiles []float64) []*hexutil.Big {
+	rewards := make([]*hexutil.Big, len(percentiles))
+	if len(txs) == 0 {
+		for i := range rewards {
+			rewards[i] = (*hexutil.Big)(new(big.Int))
+		}
+		return rewards
+	}
+
+	type txGasAndReward struct {
+		gasUsed uint64
+		reward  *big.Int
+	}
+	sorted := make([]txGasAndReward, len(txs))
+	var cumulativeGasUsed uint64
+	for i, tx := range txs {
+		reward, _ := tx.EffectiveGasTip(header.BaseFee)
+		// the receipts only carry the cumulative gas used
+		sorted[i] = txGasAndReward{gasUsed: rcts[i].CumulativeGasUsed - cumulativeGasUsed, reward: reward}
+		cumulativeGasUsed = rcts[i].CumulativeGasUsed
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].reward.Cmp(sorted[j].reward) < 0
+	})
+
+	var txIndex int
+	sumGasUsed := sorted[0].gasUsed
+	for i, p := range percentiles {
+		thresholdGasUsed := uint64(float64(header.GasUsed) * p / 100)
+		for sumGasUsed < thresholdGasUsed && txIndex < len(sorted)-1 {
+			txIndex++
+			sumGasUsed += sorted[txIndex].gasUsed
+		}
+		rewards[i] = (*hexutil.Big)(sorted[txIndex].reward)
+	}
+	return rewards
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
@@ -603,18 +798,18 @@ func (pea *PublicEthAPI) localGetTransactionReceipt(ctx context.Context, hash co
 	if len(receipts) <= int(index) {
 		return nil, nil
 	}
-	receipt := receipts[index]
+	return marshalReceipt(receipts[index], blockHash, blockNumber, tx, index), nil
+}
 
-	var signer types.Signer = types.FrontierSigner{}
-	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainId())
-	}
+// marshalReceipt returns the rpc representation of the provided receipt of the tx at the provided index of the block
+func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, tx *types.Transaction, index uint64) map[string]interface{} {
+	signer := types.LatestSignerForChainID(tx.ChainId())
 	from, _ := types.Sender(signer, tx)
 
 	fields := map[string]interface{}{
 		"blockHash":         blockHash,
 		"blockNumber":       hexutil.Uint64(blockNumber),
-		"transactionHash":   hash,
+		"transactionHash":   tx.Hash(),
 		"transactionIndex":  hexutil.Uint64(index),
 		"from":              from,
 		"to":                tx.To(),
@@ -638,13 +833,99 @@ func (pea *PublicEthAPI) localGetTransactionReceipt(ctx context.Context, hash co
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	return fields
+}
+
+// GetBlockReceipts returns the receipts of all the transactions in the block with the provided number or hash,
+// in transaction index order
+func (pea *PublicEthAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	header, err := pea.B.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return pea.localGetBlockReceipts(header)
+}
+
+func (pea *PublicEthAPI) localGetBlockReceipts(header *types.Header) ([]map[string]interface{}, error) {
+	// Begin tx
+	tx, err := pea.B.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			shared.Rollback(tx)
+			panic(p)
+		} else if err != nil {
+			shared.Rollback(tx)
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	blockHash := header.Hash()
+	blockNumber := header.Number.Uint64()
+	txCIDs, err := pea.B.Retriever.RetrieveTxCIDsByHeaderID(tx, blockHash.String(), int64(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	txHashes := make([]string, len(txCIDs))
+	for i, txCID := range txCIDs {
+		txHashes[i] = txCID.TxHash
+	}
+	rctCIDs, err := pea.B.Retriever.RetrieveReceiptCIDsByByHeaderIDAndTxIDs(tx, blockHash.String(), txHashes, int64(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if len(rctCIDs) != len(txCIDs) {
+		err = fmt.Errorf("found %d receipts for the %d transactions of block %s", len(rctCIDs), len(txCIDs), blockHash.Hex())
+		return nil, err
+	}
+
+	txIPLDs, err := pea.B.Fetcher.FetchTrxs(tx, txCIDs)
+	if err != nil {
+		return nil, err
+	}
+	rctIPLDs, err := pea.B.Fetcher.FetchRcts(tx, rctCIDs)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make(types.Transactions, len(txIPLDs))
+	for i, txIPLD := range txIPLDs {
+		transactions[i] = new(types.Transaction)
+		if err = transactions[i].UnmarshalBinary(txIPLD.Data); err != nil {
+			return nil, err
+		}
+	}
+	receipts := make(types.Receipts, len(rctIPLDs))
+	for i, rctIPLD := range rctIPLDs {
+		var nodeVal []byte
+		nodeVal, err = DecodeLeafNode(rctIPLD.Data)
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = new(types.Receipt)
+		if err = receipts[i].UnmarshalBinary(nodeVal); err != nil {
+			return nil, err
+		}
+	}
+	if err = receipts.DeriveFields(pea.B.Config.ChainConfig, blockHash, blockNumber, transactions); err != nil {
+		return nil, err
+	}
+
+	fields := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		fields[i] = marshalReceipt(receipt, blockHash, blockNumber, transactions[i], uint64(i))
+	}
 	return fields, nil
 }
 
 func (pea *PublicEthAPI) remoteGetTransactionReceipt(ctx context.Context, hash common.Hash) map[string]interface{} {
 	var rct *RPCReceipt
 	if err := pea.rpc.CallContext(ctx, &rct, "eth_getTransactionReceipt", hash); rct != nil && err == nil {
-		return map[string]interface{}{
+		fields := map[string]interface{}{
 			"blockHash":         rct.BlockHash,
 			"blockNumber":       rct.BlockNumber,
 			"transactionHash":   rct.TransactionHash,
@@ -656,52 +937,108 @@ func (pea *PublicEthAPI) remoteGetTransactionReceipt(ctx context.Context, hash c
 			"contractAddress":   rct.ContractAddress,
 			"logs":              rct.Logs,
 			"logsBloom":         rct.Bloom,
-			"root":              rct.Root,
-			"status":            rct.Status,
 		}
+
+		// Assign receipt status or post state, pre-Byzantium receipts have a post state root instead of a status
+		if len(rct.Root) > 0 {
+			fields["root"] = rct.Root
+		} else if rct.Status != nil {
+			fields["status"] = *rct.Status
+		}
+		return fields
 	}
 	return nil
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
+// Besides the standard criteria, the logs of a list of blocks can be retrieved by their blockHashes, optionally
+// bounded by the minBlock and maxBlock numbers of the listed blocks
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getlogs
-func (pea *PublicEthAPI) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, error) {
-	logs, err := pea.localGetLogs(crit)
-	if err != nil && pea.config.ProxyOnError {
+func (pea *PublicEthAPI) GetLogs(ctx context.Context, crit LogFilterCriteria) ([]*types.Log, error) {
+	if err := pea.B.CheckLogsTopics(crit.Topics); err != nil {
+		return nil, err
+	}
+	if crit.BlockHashes != nil {
+		return pea.localGetLogsByBlockHashes(crit)
+	}
+	if err := pea.checkLogsMaxAge(crit.FilterCriteria); err != nil {
+		return nil, err
+	}
+	logs, err := pea.localGetLogs(ctx, crit.FilterCriteria)
+	// a query over the cap, or the retriever's row limit, is rejected rather than forwarded
+	if err != nil && pea.config.ProxyOnError && !errors.Is(err, ErrLogResultSetTooLarge) {
 		var res []*types.Log
-		if err := pea.rpc.CallContext(ctx, &res, "eth_getLogs", crit); err == nil {
-			go pea.writeStateDiffWithCriteria(crit)
+		if err := pea.rpc.CallContext(ctx, &res, "eth_getLogs", crit.FilterCriteria); err == nil {
+			go pea.writeStateDiffWithCriteria(crit.FilterCriteria)
 			return res, nil
 		}
 	}
 	return logs, err
 }
 
-func (pea *PublicEthAPI) localGetLogs(crit filters.FilterCriteria) ([]*types.Log, error) {
-	// TODO: this can be optimized away from using the old cid retriever and ipld fetcher interfaces
-	// Convert FilterQuery into ReceiptFilter
-	addrStrs := make([]string, len(crit.Addresses))
-	for i, addr := range crit.Addresses {
-		addrStrs[i] = addr.String()
+// localGetLogsByBlockHashes retrieves the indexed logs of a list of blocks; the list is not understood by the proxy,
+// so the logs of blocks that are not indexed are not served
+func (pea *PublicEthAPI) localGetLogsByBlockHashes(crit LogFilterCriteria) ([]*types.Log, error) {
+	if max := pea.B.Config.LogsMaxBlockRange; max > 0 && int64(len(crit.BlockHashes)) > max {
+		return nil, fmt.Errorf("log filter lists %d block hashes, exceeding the limit of %d", len(crit.BlockHashes), max)
+	}
+	if len(crit.BlockHashes) == 0 {
+		return []*types.Log{}, nil
 	}
 
-	topicStrSets := make([][]string, len(crit.Topics))
-	for i, topicSet := range crit.Topics {
-		if i > 3 {
-			topicStrSets = topicStrSets[:4]
-			// don't allow more than 4 topics
-			break
-		}
-		for _, topic := range topicSet {
-			topicStrSets[i] = append(topicStrSets[i], topic.String())
-		}
+	tx, err := pea.B.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	filteredLogs, err := pea.B.Retriever.RetrieveFilteredLogsByBlockHashes(tx, NewReceiptFilter(crit.FilterCriteria), crit.BlockHashes, crit.MinBlock, crit.MaxBlock)
+	if err == nil {
+		err = pea.B.CheckLogsResultCount(len(filteredLogs))
+	}
+	if err != nil {
+		shared.Rollback(tx)
+		return nil, err
 	}
-	filter := ReceiptFilter{
-		LogAddresses: addrStrs,
-		Topics:       topicStrSets,
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
+	logs, err := decomposeLogs(filteredLogs)
+	if err != nil {
+		return nil, err
+	}
+	pea.B.OrderLogs(logs)
+	return logs, nil
+}
+
+// checkLogsMaxAge returns an error if the fromBlock of the query is further behind head than the configured retention window
+func (pea *PublicEthAPI) checkLogsMaxAge(crit filters.FilterCriteria) error {
+	if pea.config.LogsMaxAge == 0 || crit.BlockHash != nil {
+		return nil
+	}
+	var from int64
+	if crit.FromBlock != nil {
+		from = crit.FromBlock.Int64()
+	}
+	// negative values are block tags (latest, pending) which are always within the window
+	if from < 0 {
+		return nil
+	}
+	head, err := pea.B.Retriever.RetrieveLastBlockNumber()
+	if err != nil {
+		return err
+	}
+	if head-from > int64(pea.config.LogsMaxAge) {
+		return fmt.Errorf("fromBlock %d is older than the retention window of %d blocks behind head %d", from, pea.config.LogsMaxAge, head)
+	}
+	return nil
+}
+
+func (pea *PublicEthAPI) localGetLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, error) {
+	// TODO: this can be optimized away from using the old cid retriever and ipld fetcher interfaces
+	// Convert FilterQuery into ReceiptFilter
+	filter := NewReceiptFilter(crit)
+
 	// Begin tx
 	tx, err := pea.B.DB.Beginx()
 	if err != nil {
@@ -725,7 +1062,16 @@ func (pea *PublicEthAPI) localGetLogs(crit filters.FilterCriteria) ([]*types.Log
 			return nil, err
 		}
 
-		return decomposeLogs(filteredLogs)
+		if err := pea.B.CheckLogsResultCount(len(filteredLogs)); err != nil {
+			return nil, err
+		}
+
+		logs, err := decomposeLogs(filteredLogs)
+		if err != nil {
+			return nil, err
+		}
+		pea.B.OrderLogs(logs)
+		return logs, nil
 	}
 
 	// Otherwise, create block range from criteria
@@ -744,22 +1090,20 @@ func (pea *PublicEthAPI) localGetLogs(crit filters.FilterCriteria) ([]*types.Log
 		endingBlock = big.NewInt(endingBlockInt)
 	}
 
-	start := startingBlock.Int64()
-	end := endingBlock.Int64()
-	var logs []*types.Log
-	for i := start; i <= end; i++ {
-		filteredLogs, err := pea.B.Retriever.RetrieveFilteredLog(tx, filter, i, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		logCIDs, err := decomposeLogs(filteredLogs)
-		if err != nil {
-			return nil, err
-		}
+	// only the logs of canonical blocks are served over a block range
+	filteredLogs, overflow, err := pea.B.Retriever.RetrieveFilteredCanonicalLogsInRange(ctx, tx, filter, startingBlock.Int64(), endingBlock.Int64(), pea.B.Config.LogsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	if overflow != 0 {
+		return nil, pea.B.LogsResultCountError()
+	}
 
-		logs = append(logs, logCIDs...)
+	logs, err := decomposeLogs(filteredLogs)
+	if err != nil {
+		return nil, err
 	}
+	pea.B.OrderLogs(logs)
 
 	if err := tx.Commit(); err != nil {
 		return nil, err
@@ -770,6 +1114,55 @@ func (pea *PublicEthAPI) localGetLogs(crit filters.FilterCriteria) ([]*types.Log
 
 /*
 
+Subscriptions
+
+*/
+
+// NewPendingTransactions relays the pending transaction hashes of the proxy node to the subscriber
+// The relay ends when the proxy subscription fails, e.g. because the proxy node disconnected
+func (pea *PublicEthAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	if pea.rpc == nil {
+		return nil, errNoProxyForPendingTxs
+	}
+	// ensure that the RPC connection supports subscriptions
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	// the proxy subscription outlives the request, so it can't use the request context
+	txHashes := make(chan common.Hash, pendingTxsChanBufferSize)
+	proxySub, err := pea.rpc.EthSubscribe(context.Background(), txHashes, "newPendingTransactions")
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		defer proxySub.Unsubscribe()
+		for {
+			select {
+			case txHash := <-txHashes:
+				if err := notifier.Notify(rpcSub.ID, txHash); err != nil {
+					log.Errorf("failed to relay pending transaction %s: %v", txHash.Hex(), err)
+					return
+				}
+			case err := <-proxySub.Err():
+				log.Errorf("proxy newPendingTransactions subscription closed: %v", err)
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+/*
+
 State and Storage
 
 */
@@ -796,6 +1189,13 @@ func (pea *PublicEthAPI) GetBalance(ctx context.Context, address common.Address,
 }
 
 func (pea *PublicEthAPI) localGetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	if pea.B.Config.BalanceOnlyAccountDecode {
+		balance, err := pea.B.GetBalanceByNumberOrHash(ctx, address, blockNrOrHash)
+		if err != nil {
+			return nil, err
+		}
+		return (*hexutil.Big)(balance), nil
+	}
 	account, err := pea.B.GetAccountByNumberOrHash(ctx, address, blockNrOrHash)
 	if err != nil {
 		return nil, err
@@ -803,6 +1203,37 @@ func (pea *PublicEthAPI) localGetBalance(ctx context.Context, address common.Add
 	return (*hexutil.Big)(account.Balance), nil
 }
 
+// GetBalanceMulti returns the amount of wei for each of the given addresses in the state of the given block,
+// retrieving all of their accounts in a single query. Addresses without an account have a balance of 0.
+func (pea *PublicEthAPI) GetBalanceMulti(ctx context.Context, addresses []common.Address, blockNrOrHash rpc.BlockNumberOrHash) (map[common.Address]*hexutil.Big, error) {
+	if len(addresses) > maxBalanceMultiAddresses {
+		return nil, fmt.Errorf("too many addresses requested: %d, the maximum is %d", len(addresses), maxBalanceMultiAddresses)
+	}
+	hash, err := pea.B.BlockHashByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := pea.B.IPLDRetriever.RetrieveAccountsByAddressesAndBlockHash(addresses, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[common.Address]*hexutil.Big, len(addresses))
+	for _, address := range addresses {
+		account, ok := accounts[address]
+		if !ok {
+			balances[address] = (*hexutil.Big)(big.NewInt(0))
+			continue
+		}
+		balance, err := DecodeAccountBalance(account.Data)
+		if err != nil {
+			return nil, err
+		}
+		balances[address] = (*hexutil.Big)(balance)
+	}
+	return balances, nil
+}
+
 // GetStorageAt returns the storage from the state at the given address, key and
 // block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta block
 // numbers are also allowed.
@@ -816,17 +1247,12 @@ func (pea *PublicEthAPI) GetStorageAt(ctx context.Context, address common.Addres
 
 	storageVal, err := pea.B.GetStorageByNumberOrHash(ctx, address, common.HexToHash(key), blockNrOrHash)
 	if storageVal != nil && err == nil {
-		var value common.Hash
-		_, content, _, err := rlp.Split(storageVal)
-		if err == io.ErrUnexpectedEOF {
-			return hexutil.Bytes{}, nil
-		}
-		if err != nil {
-			return nil, err
-		}
-		value.SetBytes(content)
-
-		return value[:], nil
+		return decodeStorageValue(storageVal)
+	}
+	// No rows for the slot at an indexed block means the slot is genuinely empty, which the proxy could only confirm;
+	// a block whose header is not indexed (or any other failure) is left for the proxy to answer
+	if err == sql.ErrNoRows {
+		return make([]byte, 32), nil
 	}
 	if pea.config.ProxyOnError {
 		log.Warnxf(ctx, "Missing eth_getStorageAt(%s, %s, %s)", address.Hash().String(), key, blockNrOrHash.String())
@@ -835,12 +1261,24 @@ func (pea *PublicEthAPI) GetStorageAt(ctx context.Context, address common.Addres
 			return res, nil
 		}
 	}
-	if err == sql.ErrNoRows {
-		return make([]byte, 32), nil
-	}
 	return nil, err
 }
 
+// decodeStorageValue decodes the rlp encoded value of a storage leaf into the 32 byte slot value
+func decodeStorageValue(storageVal []byte) (hexutil.Bytes, error) {
+	var value common.Hash
+	_, content, _, err := rlp.Split(storageVal)
+	if err == io.ErrUnexpectedEOF {
+		return hexutil.Bytes{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	value.SetBytes(content)
+
+	return value[:], nil
+}
+
 // GetCode returns the code stored at the given address in the state for the given block number.
 func (pea *PublicEthAPI) GetCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
 	code, err := pea.B.GetCodeByNumberOrHash(ctx, address, blockNrOrHash)
@@ -860,6 +1298,74 @@ func (pea *PublicEthAPI) GetCode(ctx context.Context, address common.Address, bl
 	return nil, err
 }
 
+// GetAccountState returns the balance, nonce and code hash of the account at the given address together with the values
+// of the given storage slots, and the account's code if includeCode is set, in a single response.
+// The block is resolved once and the account is read from a single state leaf, saving the round trips of
+// separate eth_getBalance, eth_getTransactionCount, eth_getCode and eth_getStorageAt calls.
+func (pea *PublicEthAPI) GetAccountState(ctx context.Context, address common.Address, slots []string, blockNrOrHash rpc.BlockNumberOrHash, includeCode *bool) (*AccountStateResult, error) {
+	if len(slots) > maxAccountStateSlots {
+		return nil, fmt.Errorf("too many storage slots requested: %d, the maximum is %d", len(slots), maxAccountStateSlots)
+	}
+	hash, err := pea.B.BlockHashByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AccountStateResult{
+		Address:  address,
+		Balance:  (*hexutil.Big)(big.NewInt(0)),
+		CodeHash: crypto.Keccak256Hash(nil),
+		Storage:  make([]StorageValueResult, len(slots)),
+	}
+	_, accountRLP, err := pea.B.IPLDRetriever.RetrieveAccountByAddressAndBlockHash(address, hash)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	// an account which does not exist, or was removed, holds nothing
+	if err == sql.ErrNoRows || bytes.Equal(accountRLP, EmptyNodeValue) {
+		for i, slot := range slots {
+			res.Storage[i] = StorageValueResult{Key: slot, Value: common.CopyBytes(EmptyNodeValue)}
+		}
+		if includeCode != nil && *includeCode {
+			res.Code = hexutil.Bytes{}
+		}
+		return res, nil
+	}
+
+	account := new(types.StateAccount)
+	if err := rlp.DecodeBytes(accountRLP, account); err != nil {
+		return nil, err
+	}
+	res.Balance = (*hexutil.Big)(account.Balance)
+	res.Nonce = hexutil.Uint64(account.Nonce)
+	res.CodeHash = common.BytesToHash(account.CodeHash)
+	if includeCode != nil && *includeCode {
+		if res.Code, err = pea.B.IPLDRetriever.RetrieveCodeByCodeHash(res.CodeHash); err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]common.Hash, len(slots))
+	for i, slot := range slots {
+		keys[i] = common.HexToHash(slot)
+	}
+	storage, err := pea.B.IPLDRetriever.RetrieveStorageAtByAddressAndStorageSlotsAndBlockHash(address, keys, hash)
+	if err != nil {
+		return nil, err
+	}
+	for i, slot := range slots {
+		res.Storage[i].Key = slot
+		storageVal, ok := storage[keys[i]]
+		if !ok {
+			res.Storage[i].Value = common.CopyBytes(EmptyNodeValue)
+			continue
+		}
+		if res.Storage[i].Value, err = decodeStorageValue(storageVal); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
 // GetProof returns the Merkle-proof for a given account and optionally some storage keys.
 func (pea *PublicEthAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*AccountResult, error) {
 	proof, err := pea.localGetProof(ctx, address, storageKeys, blockNrOrHash)
@@ -885,37 +1391,35 @@ func (pea *PublicEthAPI) localGetProof(ctx context.Context, address common.Addre
 	storageHash := types.EmptyRootHash
 	codeHash := state.GetCodeHash(address)
 	storageProof := make([]StorageResult, len(storageKeys))
+	accountProof := []string{}
 
 	// if we have a storageTrie, (which means the account exists), we can update the storagehash
+	// and create the proofs for the storageKeys from a single traversal of the trie
 	if storageTrie != nil {
 		storageHash = storageTrie.Hash()
+		storageProof, err = GetStorageProofs(storageTrie, storageKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		// create the accountProof from the state nodes along the path to the account's leaf
+		proof, err := state.GetProof(address)
+		if err != nil {
+			return nil, err
+		}
+		accountProof = toHexSlice(proof)
 	} else {
-		// no storageTrie means the account does not exist, so the codeHash is the hash of an empty bytearray.
+		// no storageTrie means the account does not exist, so the codeHash is the hash of an empty bytearray
+		// and the account and storage proofs are left empty.
 		codeHash = crypto.Keccak256Hash(nil)
-	}
-
-	// create the proof for the storageKeys
-	for i, key := range storageKeys {
-		if storageTrie != nil {
-			proof, storageError := state.GetStorageProof(address, common.HexToHash(key))
-			if storageError != nil {
-				return nil, storageError
-			}
-			storageProof[i] = StorageResult{key, (*hexutil.Big)(state.GetState(address, common.HexToHash(key)).Big()), toHexSlice(proof)}
-		} else {
+		for i, key := range storageKeys {
 			storageProof[i] = StorageResult{key, &hexutil.Big{}, []string{}}
 		}
 	}
 
-	// create the accountProof
-	accountProof, proofErr := state.GetProof(address)
-	if proofErr != nil {
-		return nil, proofErr
-	}
-
 	return &AccountResult{
 		Address:      address,
-		AccountProof: toHexSlice(accountProof),
+		AccountProof: accountProof,
 		Balance:      (*hexutil.Big)(state.GetBalance(address)),
 		CodeHash:     codeHash,
 		Nonce:        hexutil.Uint64(state.GetNonce(address)),
@@ -1110,6 +1614,74 @@ func DoCall(ctx context.Context, b *Backend, args CallArgs, blockNrOrHash rpc.Bl
 	return result, nil
 }
 
+// AccessList creates an access list for the given transaction by re-executing it with an access list tracer
+// until the access list it touches no longer changes.
+// If the access list creation fails an error is returned, if the transaction itself fails a vmErr is returned.
+func AccessList(ctx context.Context, b *Backend, blockNrOrHash rpc.BlockNumberOrHash, args CallArgs, timeout time.Duration, globalGasCap uint64) (acl types.AccessList, gasUsed uint64, vmErr error, err error) {
+	db, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if db == nil || err != nil {
+		return nil, 0, nil, err
+	}
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	// The recipient of a contract creation is the address the contract would be deployed at
+	from := args.from()
+	var to common.Address
+	if args.To != nil {
+		to = *args.To
+	} else {
+		to = crypto.CreateAddress(from, db.GetNonce(from))
+	}
+	// Precompiles are always warm, so they don't need to be added to the access list
+	isPostMerge := header.Difficulty.Cmp(common.Big0) == 0
+	precompiles := vm.ActivePrecompiles(b.Config.ChainConfig.Rules(header.Number, isPostMerge))
+
+	prevTracer := logger.NewAccessListTracer(nil, from, to, precompiles)
+	if args.AccessList != nil {
+		prevTracer = logger.NewAccessListTracer(*args.AccessList, from, to, precompiles)
+	}
+	for {
+		// Execute on a copy of the state with the access list found so far, until it stops growing
+		accessList := prevTracer.AccessList()
+		statedb := db.Copy()
+		args.AccessList = &accessList
+		msg, err := args.ToMessage(globalGasCap, header.BaseFee)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		tracer := logger.NewAccessListTracer(accessList, from, to, precompiles)
+		config := vm.Config{Tracer: tracer, Debug: true, NoBaseFee: true}
+		evm := vm.NewEVM(core.NewEVMBlockContext(header, b, nil), core.NewEVMTxContext(msg), statedb, b.Config.ChainConfig, config)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+		res, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas()))
+		if evm.Cancelled() {
+			return nil, 0, nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to apply transaction: %w", err)
+		}
+		if tracer.Equal(prevTracer) {
+			vmErr = res.Err
+			if len(res.Revert()) > 0 {
+				vmErr = newRevertError(res)
+			}
+			return accessList, res.UsedGas, vmErr, nil
+		}
+		prevTracer = tracer
+	}
+}
+
 // writeStateDiffAtOrFor calls out to the proxy statediffing geth client to fill in a gap in the index
 func (pea *PublicEthAPI) writeStateDiffAtOrFor(blockNrOrHash rpc.BlockNumberOrHash) {
 	// short circuit right away if the proxy doesn't support diffing
@@ -1254,6 +1826,7 @@ func decomposeLogs(logCIDs []LogResult) ([]*types.Log, error) {
 			TxIndex:     uint(l.TxnIndex),
 			BlockHash:   common.HexToHash(l.BlockHash),
 			Index:       uint(l.Index),
+			Removed:     l.Removed,
 		}
 	}
 
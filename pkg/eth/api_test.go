@@ -18,11 +18,15 @@ package eth_test
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -30,8 +34,10 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/statediff"
 	"github.com/ethereum/go-ethereum/statediff/indexer/interfaces"
 	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/jmoiron/sqlx"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -181,6 +187,63 @@ var (
 	}
 )
 
+// mockBlockAPI is a proxy node eth API serving a single block by hash or number, counting the calls it receives
+type mockBlockAPI struct {
+	block *types.Block
+	td    *big.Int
+	calls int
+}
+
+func (api *mockBlockAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	api.calls++
+	if hash != api.block.Hash() {
+		return nil, nil
+	}
+	return api.marshalBlock(fullTx)
+}
+
+func (api *mockBlockAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	api.calls++
+	if number.Int64() != api.block.Number().Int64() {
+		return nil, nil
+	}
+	return api.marshalBlock(fullTx)
+}
+
+func (api *mockBlockAPI) marshalBlock(fullTx bool) (map[string]interface{}, error) {
+	fields, err := eth.RPCMarshalBlock(api.block, true, fullTx)
+	if err != nil {
+		return nil, err
+	}
+	fields["totalDifficulty"] = (*hexutil.Big)(api.td)
+	return fields, nil
+}
+
+// mockStateDiffAPI is a proxy node statediff API recording the gap fills it is asked for
+type mockStateDiffAPI struct {
+	heights chan uint64
+	hashes  chan common.Hash
+}
+
+func (api *mockStateDiffAPI) WriteStateDiffAt(ctx context.Context, blockNumber uint64, params statediff.Params) error {
+	api.heights <- blockNumber
+	return nil
+}
+
+func (api *mockStateDiffAPI) WriteStateDiffFor(ctx context.Context, blockHash common.Hash, params statediff.Params) error {
+	api.hashes <- blockHash
+	return nil
+}
+
+// mockReceiptAPI is a proxy node eth API serving receipts by tx hash
+type mockReceiptAPI struct {
+	receipts map[common.Hash]*eth.RPCReceipt
+}
+
+func (api *mockReceiptAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*eth.RPCReceipt, error) {
+	return api.receipts[hash], nil
+}
+
 var _ = Describe("API", func() {
 	var (
 		db          *sqlx.DB
@@ -212,7 +275,7 @@ var _ = Describe("API", func() {
 			},
 		})
 		Expect(err).ToNot(HaveOccurred())
-		api, _ = eth.NewPublicEthAPI(backend, nil, eth.APIConfig{false, false, false, false, shared.DefaultStateDiffTimeout})
+		api, _ = eth.NewPublicEthAPI(backend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
 		tx, err = indexAndPublisher.PushBlock(test_helpers.MockBlock, test_helpers.MockReceipts, test_helpers.MockBlock.Difficulty())
 		Expect(err).ToNot(HaveOccurred())
 
@@ -386,6 +449,81 @@ var _ = Describe("API", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(block["baseFee"].(*big.Int)).To(Equal(baseFee))
 		})
+		Describe("with proxyOnError", func() {
+			var (
+				unindexed    *types.Block
+				proxyAPI     *mockBlockAPI
+				statediffAPI *mockStateDiffAPI
+				proxyServer  *rpc.Server
+				proxiedAPI   *eth.PublicEthAPI
+			)
+			BeforeEach(func() {
+				// no block is indexed at the height of the unindexed block either
+				header := types.CopyHeader(test_helpers.MockBlock.Header())
+				header.Number = big.NewInt(100)
+				header.Extra = []byte("unindexed")
+				unindexed = types.NewBlock(header, test_helpers.MockTransactions, nil, test_helpers.MockReceipts, new(trie.Trie))
+				proxyAPI = &mockBlockAPI{block: unindexed, td: big.NewInt(1337)}
+				statediffAPI = &mockStateDiffAPI{heights: make(chan uint64, 1), hashes: make(chan common.Hash, 1)}
+				proxyServer = rpc.NewServer()
+				err := proxyServer.RegisterName(eth.APIName, proxyAPI)
+				Expect(err).ToNot(HaveOccurred())
+				err = proxyServer.RegisterName("statediff", statediffAPI)
+				Expect(err).ToNot(HaveOccurred())
+				proxiedAPI, err = eth.NewPublicEthAPI(api.B, rpc.DialInProc(proxyServer), eth.APIConfig{
+					ProxyOnError:      true,
+					SupportsStateDiff: true,
+					StateDiffTimeout:  shared.DefaultStateDiffTimeout,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				proxyServer.Stop()
+			})
+			It("Serves a block which is not indexed from the proxy node", func() {
+				block, err := proxiedAPI.GetBlockByHash(ctx, unindexed.Hash(), false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(block).ToNot(BeNil())
+				Expect(block["hash"]).To(Equal(unindexed.Hash().Hex()))
+				Expect(block["number"]).To(Equal(hexutil.EncodeBig(unindexed.Number())))
+				Expect(block["totalDifficulty"]).To(Equal(hexutil.EncodeBig(big.NewInt(1337))))
+				Expect(block["transactions"]).To(HaveLen(len(test_helpers.MockTransactions)))
+				Expect(proxyAPI.calls).To(Equal(1))
+
+				// the proxy node is asked to fill the gap in the index
+				Eventually(statediffAPI.hashes).Should(Receive(Equal(unindexed.Hash())))
+				localBlock, err := api.GetBlockByHash(ctx, unindexed.Hash(), false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(localBlock).To(BeNil())
+			})
+			It("Serves a block which is not indexed from the proxy node by number", func() {
+				number := rpc.BlockNumber(unindexed.Number().Int64())
+				block, err := proxiedAPI.GetBlockByNumber(ctx, number, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(block).ToNot(BeNil())
+				Expect(block["hash"]).To(Equal(unindexed.Hash().Hex()))
+				Expect(block["totalDifficulty"]).To(Equal(hexutil.EncodeBig(big.NewInt(1337))))
+				Expect(proxyAPI.calls).To(Equal(1))
+
+				// the proxy node is asked to fill the gap in the index
+				Eventually(statediffAPI.heights).Should(Receive(Equal(unindexed.NumberU64())))
+				localBlock, err := api.GetBlockByNumber(ctx, number, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(localBlock).To(BeNil())
+			})
+			It("Returns `nil` if the proxy node cannot find the block either", func() {
+				block, err := proxiedAPI.GetBlockByHash(ctx, randomHash, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(block).To(BeNil())
+				Expect(proxyAPI.calls).To(Equal(1))
+			})
+			It("Serves an indexed block without forwarding", func() {
+				block, err := proxiedAPI.GetBlockByHash(ctx, test_helpers.MockBlock.Hash(), false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(block["hash"]).To(Equal(test_helpers.MockBlock.Hash()))
+				Expect(proxyAPI.calls).To(Equal(0))
+			})
+		})
 		It("Retrieves a block by hash with uncles in correct order", func() {
 			block, err := api.GetBlockByHash(ctx, test_helpers.MockLondonBlock.Hash(), false)
 			Expect(err).ToNot(HaveOccurred())
@@ -526,6 +664,12 @@ var _ = Describe("API", func() {
 			Expect(tx.GasTipCap).To(Equal((*hexutil.Big)(test_helpers.MockLondonTransactions[0].GasTipCap())))
 			Expect(tx).To(Equal(expectedLondonTransaction))
 		})
+		It("Returns nil for an index beyond the transactions of the block", func() {
+			count := api.GetBlockTransactionCountByNumber(ctx, number)
+			Expect(count).ToNot(BeNil())
+			tx := api.GetTransactionByBlockNumberAndIndex(ctx, number, *count)
+			Expect(tx).To(BeNil())
+		})
 	})
 
 	Describe("eth_getTransactionByBlockHashAndIndex", func() {
@@ -550,6 +694,12 @@ var _ = Describe("API", func() {
 			Expect(tx.GasTipCap).To(Equal((*hexutil.Big)(test_helpers.MockLondonTransactions[0].GasTipCap())))
 			Expect(tx).To(Equal(expectedLondonTransaction))
 		})
+		It("Returns nil for an index beyond the transactions of the block", func() {
+			count := api.GetBlockTransactionCountByHash(ctx, blockHash)
+			Expect(count).ToNot(BeNil())
+			tx := api.GetTransactionByBlockHashAndIndex(ctx, blockHash, *count)
+			Expect(tx).To(BeNil())
+		})
 	})
 
 	Describe("eth_getRawTransactionByBlockNumberAndIndex", func() {
@@ -566,6 +716,18 @@ var _ = Describe("API", func() {
 			Expect(tx).ToNot(BeNil())
 			Expect(tx).To(Equal(hexutil.Bytes(expectRawTx3)))
 		})
+		It("Retrieves the typed envelope of a dynamic fee transaction as it was indexed", func() {
+			expected, err := test_helpers.MockLondonTransactions[0].MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+			tx := api.GetRawTransactionByBlockNumberAndIndex(ctx, londonBlockNum, 0)
+			Expect(tx).To(Equal(hexutil.Bytes(expected)))
+		})
+		It("Returns nil for an index beyond the transactions of the block", func() {
+			count := api.GetBlockTransactionCountByNumber(ctx, number)
+			Expect(count).ToNot(BeNil())
+			tx := api.GetRawTransactionByBlockNumberAndIndex(ctx, number, *count)
+			Expect(tx).To(BeNil())
+		})
 	})
 
 	Describe("eth_getRawTransactionByBlockHashAndIndex", func() {
@@ -582,6 +744,18 @@ var _ = Describe("API", func() {
 			Expect(tx).ToNot(BeNil())
 			Expect(tx).To(Equal(hexutil.Bytes(expectRawTx3)))
 		})
+		It("Retrieves the typed envelope of a dynamic fee transaction as it was indexed", func() {
+			expected, err := test_helpers.MockLondonTransactions[0].MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+			tx := api.GetRawTransactionByBlockHashAndIndex(ctx, test_helpers.MockLondonBlock.Hash(), 0)
+			Expect(tx).To(Equal(hexutil.Bytes(expected)))
+		})
+		It("Returns nil for an index beyond the transactions of the block", func() {
+			count := api.GetBlockTransactionCountByHash(ctx, blockHash)
+			Expect(count).ToNot(BeNil())
+			tx := api.GetRawTransactionByBlockHashAndIndex(ctx, blockHash, *count)
+			Expect(tx).To(BeNil())
+		})
 	})
 
 	Describe("eth_getTransactionByHash", func() {
@@ -624,6 +798,13 @@ var _ = Describe("API", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(tx).To(Equal(hexutil.Bytes(expectRawTx3)))
 		})
+		It("Retrieves the typed envelope of a dynamic fee transaction as it was indexed", func() {
+			expected, err := test_helpers.MockLondonTransactions[0].MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+			tx, err := api.GetRawTransactionByHash(ctx, test_helpers.MockLondonTransactions[0].Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tx).To(Equal(hexutil.Bytes(expected)))
+		})
 		It("Throws an error if it cannot find a tx for the provided tx hash", func() {
 			_, err := api.GetRawTransactionByHash(ctx, randomHash)
 			Expect(err).To(HaveOccurred())
@@ -657,6 +838,86 @@ var _ = Describe("API", func() {
 			_, err := api.GetTransactionReceipt(ctx, randomHash)
 			Expect(err).To(HaveOccurred())
 		})
+		Describe("with proxyOnError", func() {
+			var (
+				preByzantiumHash  = crypto.Keccak256Hash([]byte("pre-byzantium"))
+				postByzantiumHash = crypto.Keccak256Hash([]byte("post-byzantium"))
+				root              = crypto.Keccak256Hash([]byte("root"))
+				status            = hexutil.Uint64(types.ReceiptStatusSuccessful)
+				proxyServer       *rpc.Server
+				proxiedAPI        *eth.PublicEthAPI
+			)
+			BeforeEach(func() {
+				proxyServer = rpc.NewServer()
+				err := proxyServer.RegisterName(eth.APIName, &mockReceiptAPI{
+					receipts: map[common.Hash]*eth.RPCReceipt{
+						preByzantiumHash:  {TransactionHash: &preByzantiumHash, GasUsed: 21000, Root: root.Bytes()},
+						postByzantiumHash: {TransactionHash: &postByzantiumHash, GasUsed: 21000, Status: &status},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				proxiedAPI, err = eth.NewPublicEthAPI(api.B, rpc.DialInProc(proxyServer), eth.APIConfig{
+					ProxyOnError:     true,
+					StateDiffTimeout: shared.DefaultStateDiffTimeout,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				proxyServer.Stop()
+			})
+			It("Serves a pre-Byzantium receipt from the proxy node with a post state root instead of a status", func() {
+				rct, err := proxiedAPI.GetTransactionReceipt(ctx, preByzantiumHash)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rct["transactionHash"]).To(Equal(&preByzantiumHash))
+				Expect(rct["gasUsed"]).To(Equal(hexutil.Uint64(21000)))
+				Expect(rct["root"]).To(Equal(hexutil.Bytes(root.Bytes())))
+				Expect(rct).ToNot(HaveKey("status"))
+			})
+			It("Serves a post-Byzantium receipt from the proxy node with a status", func() {
+				rct, err := proxiedAPI.GetTransactionReceipt(ctx, postByzantiumHash)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rct["transactionHash"]).To(Equal(&postByzantiumHash))
+				Expect(rct["status"]).To(Equal(status))
+				Expect(rct).ToNot(HaveKey("root"))
+			})
+		})
+	})
+
+	Describe("eth_getBlockReceipts", func() {
+		It("Retrieves the receipts of a block by hash, in tx index order", func() {
+			rcts, err := api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(blockHash, false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(rcts)).To(Equal(len(test_helpers.MockReceipts)))
+			Expect(rcts[0]).To(Equal(expectedReceipt))
+			Expect(rcts[1]).To(Equal(expectedReceipt2))
+			Expect(rcts[2]).To(Equal(expectedReceipt3))
+			for i, rct := range rcts {
+				Expect(rct["transactionHash"]).To(Equal(test_helpers.MockTransactions[i].Hash()))
+				Expect(rct["transactionIndex"]).To(Equal(hexutil.Uint64(i)))
+			}
+		})
+		It("Retrieves the receipts of a block by number, matching the receipts by tx hash", func() {
+			rcts, err := api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(number))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(rcts)).To(Equal(len(test_helpers.MockReceipts)))
+			for i, rct := range rcts {
+				expected, err := api.GetTransactionReceipt(ctx, test_helpers.MockTransactions[i].Hash())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rct).To(Equal(expected))
+			}
+		})
+		It("Recovers the sender of typed transactions", func() {
+			rcts, err := api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(test_helpers.MockLondonBlock.Hash(), false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(rcts)).To(Equal(len(test_helpers.MockLondonTransactions)))
+			Expect(test_helpers.MockLondonTransactions[0].Type()).To(Equal(uint8(types.DynamicFeeTxType)))
+			Expect(rcts[0]["from"]).To(Equal(expectedLondonTransaction.From))
+		})
+		It("Retrieves no receipts for a block that cannot be found", func() {
+			rcts, err := api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(randomHash, false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rcts).To(BeNil())
+		})
 	})
 
 	Describe("eth_getLogs", func() {
@@ -676,7 +937,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err := api.GetLogs(ctx, crit)
+			logs, err := api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(0))
 
@@ -695,7 +956,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(0))
 
@@ -714,7 +975,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog4}))
@@ -728,7 +989,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -743,7 +1004,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(2))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2}))
@@ -758,7 +1019,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -775,7 +1036,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(0))
 
@@ -791,7 +1052,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -808,7 +1069,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog2}))
@@ -826,7 +1087,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog2}))
@@ -845,7 +1106,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(2))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2}))
@@ -860,7 +1121,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog2}))
@@ -875,7 +1136,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -885,7 +1146,7 @@ var _ = Describe("API", func() {
 				FromBlock: test_helpers.MockBlock.Number(),
 				ToBlock:   test_helpers.MockBlock.Number(),
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(6))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2, test_helpers.MockLog3, test_helpers.MockLog4, test_helpers.MockLog5, test_helpers.MockLog6}))
@@ -902,7 +1163,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err := api.GetLogs(ctx, crit)
+			logs, err := api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -918,7 +1179,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -932,7 +1193,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog2}))
@@ -948,7 +1209,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog2}))
@@ -964,7 +1225,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(0))
 
@@ -980,7 +1241,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog2}))
@@ -994,7 +1255,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(2))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2}))
@@ -1012,7 +1273,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(2))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2}))
@@ -1021,7 +1282,7 @@ var _ = Describe("API", func() {
 				BlockHash: &hash,
 				Topics:    [][]common.Hash{},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(6))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2, test_helpers.MockLog3, test_helpers.MockLog4, test_helpers.MockLog5, test_helpers.MockLog6}))
@@ -1045,7 +1306,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err := api.GetLogs(ctx, crit)
+			logs, err := api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(1))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1}))
@@ -1068,7 +1329,7 @@ var _ = Describe("API", func() {
 					},
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(2))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2}))
@@ -1081,11 +1342,93 @@ var _ = Describe("API", func() {
 					test_helpers.AnotherAddress,
 				},
 			}
-			logs, err = api.GetLogs(ctx, crit)
+			logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(2))
 			Expect(logs).To(Equal([]*types.Log{test_helpers.MockLog1, test_helpers.MockLog2}))
 		})
+
+		It("Rejects queries with a fromBlock older than the configured max age", func() {
+			maxAgeAPI, err := eth.NewPublicEthAPI(api.B, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout, LogsMaxAge: 1})
+			Expect(err).ToNot(HaveOccurred())
+			crit := filters.FilterCriteria{
+				FromBlock: test_helpers.BlockNumber,
+				ToBlock:   test_helpers.LondonBlockNum,
+			}
+			_, err = maxAgeAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("retention window"))
+		})
+
+		It("Allows queries with a fromBlock within the configured max age", func() {
+			maxAgeAPI, err := eth.NewPublicEthAPI(api.B, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout, LogsMaxAge: 1})
+			Expect(err).ToNot(HaveOccurred())
+			crit := filters.FilterCriteria{
+				FromBlock: new(big.Int).Sub(test_helpers.LondonBlockNum, common.Big1),
+				ToBlock:   test_helpers.LondonBlockNum,
+			}
+			_, err = maxAgeAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Rejects queries with more topics than the configured limits", func() {
+			config := *api.B.Config
+			config.LogsMaxTopicsPerPosition = 2
+			config.LogsMaxTopics = 3
+			limitedBackend := *api.B
+			limitedBackend.Config = &config
+			limitedAPI, err := eth.NewPublicEthAPI(&limitedBackend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
+			Expect(err).ToNot(HaveOccurred())
+
+			crit := filters.FilterCriteria{
+				Topics: [][]common.Hash{
+					{common.HexToHash("0x04"), common.HexToHash("0x05")},
+					{common.HexToHash("0x06")},
+				},
+				FromBlock: test_helpers.MockBlock.Number(),
+				ToBlock:   test_helpers.MockBlock.Number(),
+			}
+			_, err = limitedAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).ToNot(HaveOccurred())
+
+			crit.Topics[0] = append(crit.Topics[0], common.HexToHash("0x07"))
+			_, err = limitedAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeding the limit of 2 per position"))
+
+			crit.Topics = [][]common.Hash{
+				{common.HexToHash("0x04"), common.HexToHash("0x05")},
+				{common.HexToHash("0x06"), common.HexToHash("0x07")},
+			}
+			_, err = limitedAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeding the limit of 3"))
+		})
+
+		It("Rejects queries matching more logs than the configured cap", func() {
+			crit := filters.FilterCriteria{
+				FromBlock: test_helpers.MockBlock.Number(),
+				ToBlock:   test_helpers.MockBlock.Number(),
+			}
+			logs, err := api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(BeNumerically(">", 1))
+
+			config := *api.B.Config
+			config.LogsMaxResults = len(logs)
+			limitedBackend := *api.B
+			limitedBackend.Config = &config
+			limitedAPI, err := eth.NewPublicEthAPI(&limitedBackend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = limitedAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).ToNot(HaveOccurred())
+
+			config.LogsMaxResults = len(logs) - 1
+			_, err = limitedAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("log filter matches more logs than the limit of"))
+		})
 	})
 
 	/*
@@ -1128,6 +1471,42 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Describe("eth_getBalanceMulti", func() {
+		addresses := []common.Address{test_helpers.AccountAddresss, test_helpers.ContractAddress, randomAddr}
+
+		It("Retrieves the eth balances of funded, empty and non-existing accounts at the block with the provided number or hash", func() {
+			for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+				rpc.BlockNumberOrHashWithNumber(number),
+				rpc.BlockNumberOrHashWithHash(blockHash, true),
+			} {
+				balances, err := api.GetBalanceMulti(ctx, addresses, blockNrOrHash)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(balances).To(HaveLen(len(addresses)))
+				Expect(balances[test_helpers.AccountAddresss].ToInt().Cmp(test_helpers.AccountBalance)).To(Equal(0))
+				Expect(balances[test_helpers.ContractAddress].ToInt().Sign()).To(Equal(0))
+				Expect(balances[randomAddr].ToInt().Sign()).To(Equal(0))
+
+				for _, address := range addresses {
+					bal, err := api.GetBalance(ctx, address, blockNrOrHash)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(balances[address].ToInt().Cmp(bal.ToInt())).To(Equal(0))
+				}
+			}
+		})
+		It("Throws an error for a non-existing block hash or number", func() {
+			_, err := api.GetBalanceMulti(ctx, addresses, rpc.BlockNumberOrHashWithHash(randomHash, true))
+			Expect(err).To(HaveOccurred())
+			_, err = api.GetBalanceMulti(ctx, addresses, rpc.BlockNumberOrHashWithNumber(wrongNumber))
+			Expect(err).To(HaveOccurred())
+		})
+		It("Rejects more addresses than the maximum", func() {
+			tooMany := make([]common.Address, 1025)
+			_, err := api.GetBalanceMulti(ctx, tooMany, rpc.BlockNumberOrHashWithNumber(number))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("too many addresses requested: 1025, the maximum is 1024"))
+		})
+	})
+
 	Describe("eth_getCode", func() {
 		It("Retrieves the code for the provided contract address at the block with the provided number", func() {
 			code, err := api.GetCode(ctx, test_helpers.ContractAddress, rpc.BlockNumberOrHashWithNumber(number))
@@ -1146,3 +1525,347 @@ var _ = Describe("API", func() {
 		})
 	})
 })
+
+var _ = Describe("eth_getLogs with reorged blocks", func() {
+	var (
+		db     *sqlx.DB
+		api    *eth.PublicEthAPI
+		orphan *types.Block
+		crit   = filters.FilterCriteria{
+			Addresses: []common.Address{test_helpers.Address},
+			FromBlock: test_helpers.MockBlock.Number(),
+			ToBlock:   test_helpers.MockBlock.Number(),
+		}
+	)
+	It("test init", func() {
+		db = shared.SetupDB()
+		indexAndPublisher := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+
+		backend, err := eth.NewEthBackend(db, &eth.Config{
+			ChainConfig: params.TestChainConfig,
+			VMConfig:    vm.Config{},
+			RPCGasCap:   big.NewInt(10000000000),
+			GroupCacheConfig: &shared.GroupCacheConfig{
+				StateDB: shared.GroupConfig{
+					Name:                   "api_reorg_test",
+					CacheSizeInMB:          8,
+					CacheExpiryInMins:      60,
+					LogStatsIntervalInSecs: 0,
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		api, _ = eth.NewPublicEthAPI(backend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
+
+		// MockBlock stays canonical as it has a child, the orphaned sibling emits the same logs
+		header := test_helpers.NewOrphanSibling(test_helpers.MockBlock.Header(), "orphan")
+		orphan = types.NewBlock(header, test_helpers.MockTransactions, nil, test_helpers.MockReceipts, new(trie.Trie))
+		for _, block := range []*types.Block{test_helpers.MockBlock, test_helpers.MockChild, orphan} {
+			tx, err := indexAndPublisher.PushBlock(block, test_helpers.MockReceipts, block.Difficulty())
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+	defer It("test teardown", func() { shared.TearDownDB(db) })
+
+	It("Serves the logs of canonical blocks only over a block range", func() {
+		logs, err := api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(1))
+		Expect(logs[0].BlockHash).To(Equal(test_helpers.MockBlock.Hash()))
+		Expect(logs[0].Removed).To(BeFalse())
+	})
+	It("Sets the removed flag when querying an orphaned block by hash", func() {
+		orphanHash := orphan.Hash()
+		logs, err := api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: filters.FilterCriteria{
+			Addresses: crit.Addresses,
+			BlockHash: &orphanHash,
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(1))
+		Expect(logs[0].Removed).To(BeTrue())
+
+		canonicalHash := test_helpers.MockBlock.Hash()
+		logs, err = api.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: filters.FilterCriteria{
+			Addresses: crit.Addresses,
+			BlockHash: &canonicalHash,
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(1))
+		Expect(logs[0].Removed).To(BeFalse())
+	})
+	It("Retrieves the logs of a list of block hashes bounded by a min and max block", func() {
+		listCrit := eth.LogFilterCriteria{
+			FilterCriteria: filters.FilterCriteria{Addresses: crit.Addresses},
+			BlockHashes:    []common.Hash{test_helpers.MockBlock.Hash(), orphan.Hash(), test_helpers.MockChild.Hash()},
+		}
+		logs, err := api.GetLogs(ctx, listCrit)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(3))
+
+		listCrit.MaxBlock = test_helpers.MockBlock.Number()
+		logs, err = api.GetLogs(ctx, listCrit)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(2))
+		hashes := []common.Hash{logs[0].BlockHash, logs[1].BlockHash}
+		Expect(hashes).To(ConsistOf(test_helpers.MockBlock.Hash(), orphan.Hash()))
+
+		listCrit.MinBlock = test_helpers.MockChild.Number()
+		listCrit.MaxBlock = nil
+		logs, err = api.GetLogs(ctx, listCrit)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(1))
+		Expect(logs[0].BlockHash).To(Equal(test_helpers.MockChild.Hash()))
+		Expect(logs[0].Removed).To(BeFalse())
+	})
+})
+
+var _ = Describe("LogFilterCriteria", func() {
+	It("Decodes a list of block hashes with min and max block bounds", func() {
+		var crit eth.LogFilterCriteria
+		err := json.Unmarshal([]byte(`{"blockHashes": ["`+test_helpers.MockBlock.Hash().Hex()+`"], "minBlock": "0x1", "maxBlock": "0x2", "address": "`+test_helpers.Address.Hex()+`"}`), &crit)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(crit.BlockHashes).To(Equal([]common.Hash{test_helpers.MockBlock.Hash()}))
+		Expect(crit.MinBlock).To(Equal(big.NewInt(1)))
+		Expect(crit.MaxBlock).To(Equal(big.NewInt(2)))
+		Expect(crit.Addresses).To(Equal([]common.Address{test_helpers.Address}))
+	})
+	It("Rejects block bounds without a list of block hashes", func() {
+		var crit eth.LogFilterCriteria
+		err := json.Unmarshal([]byte(`{"minBlock": "0x1"}`), &crit)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("only be specified along with blockHashes"))
+	})
+	It("Rejects a list of block hashes along with a block range", func() {
+		var crit eth.LogFilterCriteria
+		err := json.Unmarshal([]byte(`{"blockHashes": [], "fromBlock": "0x1"}`), &crit)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cannot specify both blockHashes"))
+	})
+})
+
+var _ = Describe("eth_getLogs order", func() {
+	var (
+		db      *sqlx.DB
+		ascAPI  *eth.PublicEthAPI
+		descAPI *eth.PublicEthAPI
+		crit    = filters.FilterCriteria{
+			Addresses: []common.Address{test_helpers.AnotherAddress1},
+			FromBlock: test_helpers.MockBlock.Number(),
+			ToBlock:   test_helpers.MockChild.Number(),
+		}
+	)
+	newAPI := func(groupName string, order eth.LogsOrder) *eth.PublicEthAPI {
+		backend, err := eth.NewEthBackend(db, &eth.Config{
+			ChainConfig: params.TestChainConfig,
+			VMConfig:    vm.Config{},
+			RPCGasCap:   big.NewInt(10000000000),
+			GroupCacheConfig: &shared.GroupCacheConfig{
+				StateDB: shared.GroupConfig{
+					Name:                   groupName,
+					CacheSizeInMB:          8,
+					CacheExpiryInMins:      60,
+					LogStatsIntervalInSecs: 0,
+				},
+			},
+			LogsOrder: order,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		api, _ := eth.NewPublicEthAPI(backend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
+		return api
+	}
+	It("test init", func() {
+		db = shared.SetupDB()
+		indexAndPublisher := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+		ascAPI = newAPI("api_logs_order_asc_test", eth.AscendingLogsOrder)
+		descAPI = newAPI("api_logs_order_desc_test", eth.DescendingLogsOrder)
+
+		// both blocks emit the three logs of the third receipt
+		for _, block := range []*types.Block{test_helpers.MockBlock, test_helpers.MockChild} {
+			tx, err := indexAndPublisher.PushBlock(block, test_helpers.MockReceipts, block.Difficulty())
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+	defer It("test teardown", func() { shared.TearDownDB(db) })
+
+	It("Serves logs in ascending block number and log index order", func() {
+		logs, err := ascAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(6))
+		for i, log := range logs {
+			if i < 3 {
+				Expect(log.BlockHash).To(Equal(test_helpers.MockBlock.Hash()))
+			} else {
+				Expect(log.BlockHash).To(Equal(test_helpers.MockChild.Hash()))
+			}
+			Expect(log.Index).To(Equal(uint(2 + i%3)))
+		}
+	})
+	It("Serves logs in descending block number and log index order", func() {
+		logs, err := descAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: crit})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(6))
+		for i, log := range logs {
+			if i < 3 {
+				Expect(log.BlockHash).To(Equal(test_helpers.MockChild.Hash()))
+			} else {
+				Expect(log.BlockHash).To(Equal(test_helpers.MockBlock.Hash()))
+			}
+			Expect(log.Index).To(Equal(uint(4 - i%3)))
+		}
+	})
+	It("Orders the logs of a single block queried by hash", func() {
+		hash := test_helpers.MockBlock.Hash()
+		logs, err := descAPI.GetLogs(ctx, eth.LogFilterCriteria{FilterCriteria: filters.FilterCriteria{
+			Addresses: crit.Addresses,
+			BlockHash: &hash,
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(logs)).To(Equal(3))
+		for i, log := range logs {
+			Expect(log.Index).To(Equal(uint(4 - i)))
+		}
+	})
+})
+
+var _ = Describe("eth_feeHistory", func() {
+	var (
+		db           *sqlx.DB
+		api          *eth.PublicEthAPI
+		londonConfig = *params.TestChainConfig
+		blocks       []*types.Block
+	)
+	It("test init", func() {
+		db = shared.SetupDB()
+		londonConfig.LondonBlock = big.NewInt(0)
+		indexAndPublisher := shared.SetupTestStateDiffIndexer(ctx, &londonConfig, test_helpers.Genesis.Hash())
+
+		backend, err := eth.NewEthBackend(db, &eth.Config{
+			ChainConfig: &londonConfig,
+			VMConfig:    vm.Config{},
+			RPCGasCap:   big.NewInt(10000000000),
+			GroupCacheConfig: &shared.GroupCacheConfig{
+				StateDB: shared.GroupConfig{
+					Name:                   "api_fee_history_test",
+					CacheSizeInMB:          8,
+					CacheExpiryInMins:      60,
+					LogStatsIntervalInSecs: 0,
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		api, _ = eth.NewPublicEthAPI(backend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
+
+		// each block but the third has a legacy tx tipping 10 * the block number and a dynamic fee tx tipping 5
+		signer := types.LatestSigner(&londonConfig)
+		var receipts []types.Receipts
+		blocks, receipts = core.GenerateChain(&londonConfig, test_helpers.Genesis, ethash.NewFaker(), test_helpers.Testdb, 5, func(i int, block *core.BlockGen) {
+			if i == 2 {
+				return
+			}
+			to := test_helpers.Account1Addr
+			legacyTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    block.TxNonce(test_helpers.TestBankAddress),
+				GasPrice: new(big.Int).Add(block.BaseFee(), new(big.Int).Mul(block.Number(), big.NewInt(10))),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(1),
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+			block.AddTx(legacyTx)
+			dynamicFeeTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   londonConfig.ChainID,
+				Nonce:     block.TxNonce(test_helpers.TestBankAddress),
+				GasTipCap: big.NewInt(5),
+				GasFeeCap: new(big.Int).Mul(block.BaseFee(), big.NewInt(2)),
+				Gas:       params.TxGas,
+				To:        &to,
+				Value:     big.NewInt(1),
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+			block.AddTx(dynamicFeeTx)
+		})
+		tx, err := indexAndPublisher.PushBlock(test_helpers.Genesis, types.Receipts{}, test_helpers.Genesis.Difficulty())
+		Expect(err).ToNot(HaveOccurred())
+		err = tx.Submit(err)
+		Expect(err).ToNot(HaveOccurred())
+		for i, block := range blocks {
+			tx, err := indexAndPublisher.PushBlock(block, receipts[i], block.Difficulty())
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+	defer It("test teardown", func() { shared.TearDownDB(db) })
+
+	It("Retrieves the base fees, gas used ratios and reward percentiles of the blocks in the range", func() {
+		res, err := api.FeeHistory(ctx, 5, rpc.BlockNumber(5), []float64{0, 50, 100})
+		Expect(err).ToNot(HaveOccurred())
+		Expect((*big.Int)(res.OldestBlock).Int64()).To(Equal(int64(1)))
+		Expect(len(res.BaseFee)).To(Equal(6))
+		Expect(len(res.GasUsedRatio)).To(Equal(5))
+		Expect(len(res.Reward)).To(Equal(5))
+
+		for i, block := range blocks {
+			Expect((*big.Int)(res.BaseFee[i])).To(Equal(block.BaseFee()))
+			Expect(res.GasUsedRatio[i]).To(Equal(float64(block.GasUsed()) / float64(block.GasLimit())))
+
+			// both txs use the same gas, so the median is the lower tip
+			expectedRewards := []int64{5, 5, 10 * block.Number().Int64()}
+			if len(block.Transactions()) == 0 {
+				expectedRewards = []int64{0, 0, 0}
+			}
+			Expect(len(res.Reward[i])).To(Equal(3))
+			for j, reward := range res.Reward[i] {
+				Expect((*big.Int)(reward).Int64()).To(Equal(expectedRewards[j]))
+			}
+		}
+		Expect((*big.Int)(res.BaseFee[5])).To(Equal(misc.CalcBaseFee(&londonConfig, blocks[4].Header())))
+	})
+
+	It("Caps the range at the first block and omits rewards without percentiles", func() {
+		res, err := api.FeeHistory(ctx, 2, rpc.LatestBlockNumber, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect((*big.Int)(res.OldestBlock).Int64()).To(Equal(int64(4)))
+		Expect(len(res.BaseFee)).To(Equal(3))
+		Expect(res.Reward).To(BeNil())
+
+		// the genesis block has the initial base fee
+		res, err = api.FeeHistory(ctx, 10, rpc.BlockNumber(2), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect((*big.Int)(res.OldestBlock).Int64()).To(Equal(int64(0)))
+		Expect(len(res.GasUsedRatio)).To(Equal(3))
+		Expect((*big.Int)(res.BaseFee[0])).To(Equal(big.NewInt(params.InitialBaseFee)))
+		Expect((*big.Int)(res.BaseFee[1])).To(Equal(blocks[0].BaseFee()))
+	})
+
+	It("Throws an error for invalid reward percentiles", func() {
+		_, err := api.FeeHistory(ctx, 5, rpc.BlockNumber(5), []float64{50, 10})
+		Expect(err).To(HaveOccurred())
+
+		_, err = api.FeeHistory(ctx, 5, rpc.BlockNumber(5), []float64{101})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("RPCReceipt", func() {
+	It("Decodes a pre-Byzantium receipt with a post state root instead of a status", func() {
+		root := crypto.Keccak256Hash([]byte("root"))
+		var rct eth.RPCReceipt
+		err := json.Unmarshal([]byte(`{"gasUsed":"0x5208","cumulativeGasUsed":"0x5208","logs":[],"root":"`+root.Hex()+`"}`), &rct)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rct.Root).To(Equal(hexutil.Bytes(root.Bytes())))
+		Expect(rct.Status).To(BeNil())
+		Expect(rct.GasUsed).To(Equal(hexutil.Uint64(21000)))
+	})
+	It("Decodes a post-Byzantium receipt with a status", func() {
+		var rct eth.RPCReceipt
+		err := json.Unmarshal([]byte(`{"gasUsed":"0x5208","cumulativeGasUsed":"0x5208","logs":[],"status":"0x1"}`), &rct)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rct.Root).To(BeEmpty())
+		Expect(*rct.Status).To(Equal(hexutil.Uint64(1)))
+	})
+})
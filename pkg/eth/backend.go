@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -38,13 +39,11 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
-	ethServerShared "github.com/ethereum/go-ethereum/statediff/indexer/shared"
 	sdtrie "github.com/ethereum/go-ethereum/statediff/trie_helpers"
 	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
 	"github.com/ethereum/go-ethereum/trie"
@@ -61,6 +60,8 @@ var (
 	errMultipleHeadersForHash = errors.New("more than one headers for the given hash")
 	errTxHashNotFound         = errors.New("transaction for hash not found")
 	errTxHashInMultipleBlocks = errors.New("transaction for hash found in more than one canonical block")
+	errNoProxyForPendingTxs   = errors.New("pending transaction subscriptions require a proxy node to be configured")
+	errInvalidPercentile      = errors.New("invalid reward percentile")
 
 	// errMissingSignature is returned if a block's extra-data section doesn't seem
 	// to contain a 65 byte secp256k1 signature.
@@ -117,15 +118,76 @@ type Backend struct {
 	EthDB         ethdb.Database
 	StateDatabase state.Database
 
+	// decoded headers, shared across requests
+	HeaderCache *HeaderCache
+
 	Config *Config
 }
 
 type Config struct {
+	// Proxy node used to resolve the pending, safe and finalized block tags (optional)
+	Client *rpc.Client
+
+	// Serve the full blocks of uncles, which are only indexed by their header, from the proxy node
+	ProxyUncleBlocks bool
+
 	ChainConfig      *params.ChainConfig
 	VMConfig         vm.Config
 	DefaultSender    *common.Address
 	RPCGasCap        *big.Int
 	GroupCacheConfig *shared.GroupCacheConfig
+
+	// Limits on the topics of a log filter, applied to both eth_getLogs and GraphQL logs queries (0 = unlimited)
+	LogsMaxTopicsPerPosition int
+	LogsMaxTopics            int
+
+	// Max number of blocks spanned by the block range of a GraphQL getLogs or other range query (0 = unlimited)
+	LogsMaxBlockRange int64
+
+	// Max number of logs served by a single query (0 = unlimited); eth_getLogs and GraphQL getLogs reject a query
+	// matching more logs, while GraphQL getLogsPage truncates the logs to the whole blocks within the cap. Logs are
+	// retrieved a block at a time, and the retrieval stops at the block taking them over the cap. The retriever's log
+	// queries are limited to the same number of rows, so a single block matching more logs is rejected by the database
+	LogsMaxResults int
+
+	// Order in which logs spanning multiple blocks are served, by block number and then log index
+	LogsOrder LogsOrder
+
+	// Max number of transactions served by a GraphQL transactionsByAddress query (0 = unlimited)
+	TxsMaxResults int
+
+	// Max number of blocks in a GraphQL blocks range without an end, which otherwise extends to the head (0 = unlimited);
+	// an open range over the limit is rejected, or truncated to the limit if BlocksTruncateOpenRange is set
+	BlocksMaxOpenRange      int64
+	BlocksTruncateOpenRange bool
+
+	// Maximum duration of a single retriever query (0 = no timeout)
+	RetrieverQueryTimeout time.Duration
+
+	// Serve eth_getBalance by decoding only the balance field of the account rather than the full account
+	BalanceOnlyAccountDecode bool
+}
+
+// LogsOrder determines whether logs are served in ascending or descending (block number, log index) order
+type LogsOrder string
+
+const (
+	// AscendingLogsOrder serves logs from the lowest block and log index to the highest
+	AscendingLogsOrder LogsOrder = "asc"
+	// DescendingLogsOrder serves logs from the highest block and log index to the lowest
+	DescendingLogsOrder LogsOrder = "desc"
+)
+
+// ParseLogsOrder parses the provided string into a LogsOrder, an empty string defaults to ascending
+func ParseLogsOrder(order string) (LogsOrder, error) {
+	switch LogsOrder(order) {
+	case "", AscendingLogsOrder:
+		return AscendingLogsOrder, nil
+	case DescendingLogsOrder:
+		return DescendingLogsOrder, nil
+	default:
+		return "", fmt.Errorf("unrecognized logs order: %s", order)
+	}
 }
 
 func NewEthBackend(db *sqlx.DB, c *Config) (*Backend, error) {
@@ -136,7 +198,14 @@ func NewEthBackend(db *sqlx.DB, c *Config) (*Backend, error) {
 		groupName = StateDBGroupCacheName
 	}
 
-	r := NewCIDRetriever(db)
+	r, err := NewCIDRetriever(db)
+	if err != nil {
+		return nil, err
+	}
+	r.QueryTimeout = c.RetrieverQueryTimeout
+	r.LogsMaxResults = c.LogsMaxResults
+	ipldRetriever := NewIPLDRetriever(db)
+	ipldRetriever.QueryTimeout = c.RetrieverQueryTimeout
 	ethDB := ipfsethdb.NewDatabase(db, ipfsethdb.CacheConfig{
 		Name:           groupName,
 		Size:           gcc.StateDB.CacheSizeInMB * 1024 * 1024,
@@ -145,13 +214,19 @@ func NewEthBackend(db *sqlx.DB, c *Config) (*Backend, error) {
 
 	logStateDBStatsOnTimer(ethDB.(*ipfsethdb.Database), gcc)
 
+	headerCache, err := NewHeaderCache(gcc.HeaderCacheSize, gcc.HeaderCacheFinalizedDepth)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Backend{
 		DB:            db,
 		Retriever:     r,
 		Fetcher:       NewIPLDFetcher(db),
-		IPLDRetriever: NewIPLDRetriever(db),
+		IPLDRetriever: ipldRetriever,
 		EthDB:         ethDB,
 		StateDatabase: state.NewDatabase(ethDB),
+		HeaderCache:   headerCache,
 		Config:        c,
 	}, nil
 }
@@ -183,17 +258,28 @@ func (b *Backend) HeaderByNumber(ctx context.Context, blockNumber rpc.BlockNumbe
 	if number < 0 {
 		return nil, errNegativeBlockNumber
 	}
+	if header, ok := b.HeaderCache.GetCanonical(uint64(number)); ok {
+		return header, nil
+	}
 	_, canonicalHeaderRLP, err := b.GetCanonicalHeader(uint64(number))
 	if err != nil {
 		return nil, err
 	}
 
 	header := new(types.Header)
-	return header, rlp.DecodeBytes(canonicalHeaderRLP, header)
+	if err := rlp.DecodeBytes(canonicalHeaderRLP, header); err != nil {
+		return nil, err
+	}
+	b.HeaderCache.AddCanonical(header)
+	return header, nil
 }
 
 // HeaderByHash gets the header for the provided block hash
 func (b *Backend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if header, ok := b.HeaderCache.Get(hash); ok {
+		return header, nil
+	}
+
 	// Begin tx
 	tx, err := b.DB.Beginx()
 	if err != nil {
@@ -215,32 +301,47 @@ func (b *Backend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.He
 		return nil, err
 	}
 	header := new(types.Header)
-	return header, rlp.DecodeBytes(headerRLP, header)
+	if err := rlp.DecodeBytes(headerRLP, header); err != nil {
+		return nil, err
+	}
+	b.HeaderCache.Add(header)
+	return header, nil
 }
 
 // HeaderByNumberOrHash gets the header for the provided block hash or number
+// Lookups by hash are served from the header cache unless the hash is required to be canonical,
+// and lookups by number are served from it once the block is finalized
 func (b *Backend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
-	if blockNr, ok := blockNrOrHash.Number(); ok {
-		return b.HeaderByNumber(ctx, blockNr)
+	if hash, ok := blockNrOrHash.Hash(); ok && !blockNrOrHash.RequireCanonical {
+		return b.HeaderByHash(ctx, hash)
 	}
-	if hash, ok := blockNrOrHash.Hash(); ok {
-		header, err := b.HeaderByHash(ctx, hash)
-		if err != nil {
-			return nil, err
-		}
-		if header == nil {
-			return nil, errors.New("header for hash not found")
+	// earliest is the first indexed block rather than block 0, and the other tags are negative
+	if number, ok := blockNrOrHash.Number(); ok && number > rpc.EarliestBlockNumber {
+		if header, ok := b.HeaderCache.GetCanonical(uint64(number)); ok {
+			return header, nil
 		}
-		canonicalHash, err := b.GetCanonicalHash(header.Number.Uint64())
-		if err != nil {
-			return nil, err
-		}
-		if blockNrOrHash.RequireCanonical && canonicalHash != hash {
-			return nil, errors.New("hash is not currently canonical")
-		}
-		return header, nil
 	}
-	return nil, errors.New("invalid arguments; neither block nor hash specified")
+	header, err := b.Retriever.RetrieveHeaderByNumberOrHash(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	b.HeaderCache.AddCanonical(header)
+	return header, nil
+}
+
+// WarmUp fetches the canonical head header and its receipts, so that the first queries for the head
+// are served from the header cache and find the receipt IPLDs in the database's buffer cache
+func (b *Backend) WarmUp(ctx context.Context) error {
+	header, err := b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return err
+	}
+	receipts, err := b.GetReceipts(ctx, header.Hash())
+	if err != nil {
+		return err
+	}
+	log.Infof("warmed up head block %d (%s) with %d receipts", header.Number.Uint64(), header.Hash().Hex(), len(receipts))
+	return nil
 }
 
 func (b *Backend) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
@@ -272,8 +373,69 @@ func (b *Backend) CurrentBlock() (*types.Block, error) {
 	return block, err
 }
 
+// ResolveBlockTag resolves the safe and finalized block tags to the number of the block they reference on the proxy node,
+// which they cannot be resolved without, and the pending block tag to the latest indexed block, as the pending block is
+// never indexed. Any other block number or hash is returned as is
+func (b *Backend) ResolveBlockTag(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (rpc.BlockNumberOrHash, error) {
+	blockNr, ok := blockNrOrHash.Number()
+	if !ok || (blockNr != rpc.PendingBlockNumber && blockNr != rpc.SafeBlockNumber && blockNr != rpc.FinalizedBlockNumber) {
+		return blockNrOrHash, nil
+	}
+	if blockNr == rpc.PendingBlockNumber {
+		return rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil
+	}
+	tag, _ := blockNr.MarshalText()
+	if b.Config.Client == nil {
+		return rpc.BlockNumberOrHash{}, fmt.Errorf("resolving the %s block requires a proxy node to be configured", tag)
+	}
+	var head struct {
+		Number *hexutil.Big `json:"number"`
+	}
+	if err := b.Config.Client.CallContext(ctx, &head, "eth_getBlockByNumber", blockNr, false); err != nil {
+		return rpc.BlockNumberOrHash{}, err
+	}
+	if head.Number == nil {
+		return rpc.BlockNumberOrHash{}, fmt.Errorf("%s block not found on the proxy node", tag)
+	}
+	return rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(head.Number.ToInt().Int64())), nil
+}
+
+// ProxiedBlockByHash retrieves the full block with the provided hash from the proxy node, including its transactions
+// but not its uncles; nil is returned if the proxy node does not know the block
+func (b *Backend) ProxiedBlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if b.Config.Client == nil {
+		return nil, errors.New("retrieving a proxied block requires a proxy node to be configured")
+	}
+	var raw json.RawMessage
+	if err := b.Config.Client.CallContext(ctx, &raw, "eth_getBlockByHash", hash, true); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	header := new(types.Header)
+	if err := json.Unmarshal(raw, header); err != nil {
+		return nil, err
+	}
+	if header.Hash() != hash {
+		return nil, fmt.Errorf("proxy node returned block %s for hash %s", header.Hash().Hex(), hash.Hex())
+	}
+	var body struct {
+		Transactions []*types.Transaction `json:"transactions"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(header).WithBody(body.Transactions, nil), nil
+}
+
 // BlockByNumberOrHash returns block by number or hash
+// The pending, safe and finalized tags are resolved as by ResolveBlockTag
 func (b *Backend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	blockNrOrHash, err := b.ResolveBlockTag(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.BlockByNumber(ctx, blockNr)
 	}
@@ -543,30 +705,48 @@ func (b *Backend) GetReceiptsByBlockHashAndNumber(tx *sqlx.Tx, hash common.Hash,
 // GetTransaction retrieves a tx by hash
 // It also returns the blockhash, blocknumber, and tx index associated with the transaction
 func (b *Backend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
-	type txRes struct {
-		Data        []byte `db:"data"`
-		HeaderID    string `db:"header_id"`
-		BlockNumber uint64 `db:"block_number"`
-		Index       uint64 `db:"index"`
+	res, err := b.getCanonicalTransaction(txHash)
+	if err != nil {
+		return nil, common.Hash{}, 0, 0, err
 	}
+
+	var transaction types.Transaction
+	if err := transaction.UnmarshalBinary(res.Data); err != nil {
+		return nil, common.Hash{}, 0, 0, err
+	}
+
+	return &transaction, common.HexToHash(res.HeaderID), res.BlockNumber, res.Index, nil
+}
+
+// GetRawTransaction retrieves the binary encoding of a canonical transaction by hash, exactly as it was indexed
+func (b *Backend) GetRawTransaction(ctx context.Context, txHash common.Hash) (hexutil.Bytes, error) {
+	res, err := b.getCanonicalTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+type txRes struct {
+	Data        []byte `db:"data"`
+	HeaderID    string `db:"header_id"`
+	BlockNumber uint64 `db:"block_number"`
+	Index       uint64 `db:"index"`
+}
+
+func (b *Backend) getCanonicalTransaction(txHash common.Hash) (*txRes, error) {
 	var res = make([]txRes, 0)
 	if err := b.DB.Select(&res, RetrieveRPCTransaction, txHash.String()); err != nil {
-		return nil, common.Hash{}, 0, 0, err
+		return nil, err
 	}
 
 	if len(res) == 0 {
-		return nil, common.Hash{}, 0, 0, errTxHashNotFound
+		return nil, errTxHashNotFound
 	} else if len(res) > 1 {
 		// a transaction can be part of a only one canonical block
-		return nil, common.Hash{}, 0, 0, errTxHashInMultipleBlocks
-	}
-
-	var transaction types.Transaction
-	if err := transaction.UnmarshalBinary(res[0].Data); err != nil {
-		return nil, common.Hash{}, 0, 0, err
+		return nil, errTxHashInMultipleBlocks
 	}
-
-	return &transaction, common.HexToHash(res[0].HeaderID), res[0].BlockNumber, res[0].Index, nil
+	return &res[0], nil
 }
 
 // GetReceipts retrieves receipts for provided block hash
@@ -596,6 +776,88 @@ func (b *Backend) GetReceipts(ctx context.Context, hash common.Hash) (types.Rece
 	return b.GetReceiptsByBlockHashAndNumber(tx, hash, blockNumber)
 }
 
+// CheckLogsTopics returns an error if the topics of a log filter exceed the configured
+// per position or total limits
+func (b *Backend) CheckLogsTopics(topics [][]common.Hash) error {
+	total := 0
+	for i, position := range topics {
+		if b.Config.LogsMaxTopicsPerPosition > 0 && len(position) > b.Config.LogsMaxTopicsPerPosition {
+			return fmt.Errorf("log filter has %d topics at position %d, exceeding the limit of %d per position", len(position), i, b.Config.LogsMaxTopicsPerPosition)
+		}
+		total += len(position)
+	}
+	if b.Config.LogsMaxTopics > 0 && total > b.Config.LogsMaxTopics {
+		return fmt.Errorf("log filter has %d topics, exceeding the limit of %d", total, b.Config.LogsMaxTopics)
+	}
+	return nil
+}
+
+// CheckLogsBlockRange returns an error if the block range (inclusive) of a log filter is invalid
+// or exceeds the configured limit
+func (b *Backend) CheckLogsBlockRange(from, to int64) error {
+	if from > to {
+		return fmt.Errorf("log filter fromBlock %d is after toBlock %d", from, to)
+	}
+	if b.Config.LogsMaxBlockRange > 0 && to-from+1 > b.Config.LogsMaxBlockRange {
+		return fmt.Errorf("log filter spans %d blocks, exceeding the limit of %d", to-from+1, b.Config.LogsMaxBlockRange)
+	}
+	return nil
+}
+
+// CheckGasUsedRatioRange returns an error if the block range (inclusive) of a gas used ratio query is invalid
+// or exceeds the configured LogsMaxBlockRange; like a log filter's, every block in the range is read and decoded
+func (b *Backend) CheckGasUsedRatioRange(from, to int64) error {
+	return b.CheckBlockRange("gas used ratio", from, to)
+}
+
+// CheckBlockRange returns an error if the block range (inclusive) of the named query is invalid
+// or exceeds the configured LogsMaxBlockRange
+func (b *Backend) CheckBlockRange(name string, from, to int64) error {
+	if from > to {
+		return fmt.Errorf("%s range from %d is after to %d", name, from, to)
+	}
+	if b.Config.LogsMaxBlockRange > 0 && to-from+1 > b.Config.LogsMaxBlockRange {
+		return fmt.Errorf("%s range spans %d blocks, exceeding the limit of %d", name, to-from+1, b.Config.LogsMaxBlockRange)
+	}
+	return nil
+}
+
+// CheckLogsResultCount returns an error if the number of logs matched by a log filter exceeds the configured cap
+func (b *Backend) CheckLogsResultCount(count int) error {
+	if b.Config.LogsMaxResults > 0 && count > b.Config.LogsMaxResults {
+		return b.LogsResultCountError()
+	}
+	return nil
+}
+
+// LogsResultCountError returns the error rejecting a log filter which matches more logs than the configured cap
+func (b *Backend) LogsResultCountError() error {
+	return fmt.Errorf("%w: log filter matches more logs than the limit of %d; narrow the block range or filter", ErrLogResultSetTooLarge, b.Config.LogsMaxResults)
+}
+
+// BoundOpenBlockRange returns the end of a block range starting at from that was left open to extend to the head,
+// erroring or truncating the range per the configured limit
+func (b *Backend) BoundOpenBlockRange(from, head int64) (int64, error) {
+	limit := b.Config.BlocksMaxOpenRange
+	if limit <= 0 || head-from+1 <= limit {
+		return head, nil
+	}
+	if b.Config.BlocksTruncateOpenRange {
+		return from + limit - 1, nil
+	}
+	return 0, fmt.Errorf("blocks range from %d to head %d spans %d blocks, exceeding the limit of %d; provide an explicit end", from, head, head-from+1, limit)
+}
+
+// OrderLogs puts logs that are in ascending (block number, log index) order into the configured order, in place
+func (b *Backend) OrderLogs(logs []*types.Log) {
+	if b.Config.LogsOrder != DescendingLogsOrder {
+		return
+	}
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+}
+
 // GetLogs returns all the logs for the given block hash
 func (b *Backend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
 	// Begin tx
@@ -699,6 +961,8 @@ func (b *Backend) GetCanonicalHeader(number uint64) (string, []byte, error) {
 }
 
 // GetEVM constructs and returns a vm.EVM
+// The fork rules of the EVM, and with them the set of active precompiles and their gas costs,
+// are selected using the number of the provided header so historical calls match the behavior at that block
 func (b *Backend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header) (*vm.EVM, func() error, error) {
 	vmError := func() error { return nil }
 	txContext := core.NewEVMTxContext(msg)
@@ -706,40 +970,68 @@ func (b *Backend) GetEVM(ctx context.Context, msg core.Message, state *state.Sta
 	return vm.NewEVM(context, txContext, state, b.Config.ChainConfig, b.Config.VMConfig), vmError, nil
 }
 
-// GetAccountByNumberOrHash returns the account object for the provided address at the block corresponding to the provided number or hash
-func (b *Backend) GetAccountByNumberOrHash(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*types.StateAccount, error) {
-	if blockNr, ok := blockNrOrHash.Number(); ok {
-		return b.GetAccountByNumber(ctx, address, blockNr)
-	}
+// BlockHashByNumberOrHash returns the hash of the indexed block corresponding to the provided number or hash,
+// a number being resolved to the canonical block at that height
+func (b *Backend) BlockHashByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (common.Hash, error) {
 	if hash, ok := blockNrOrHash.Hash(); ok {
-		return b.GetAccountByHash(ctx, address, hash)
+		_, err := b.HeaderByHash(ctx, hash)
+		if err == sql.ErrNoRows {
+			return common.Hash{}, errHeaderHashNotFound
+		}
+		return hash, err
 	}
-	return nil, errors.New("invalid arguments; neither block nor hash specified")
+	blockNumber, ok := blockNrOrHash.Number()
+	if !ok {
+		return common.Hash{}, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	return b.canonicalHashByNumber(blockNumber)
 }
 
-// GetAccountByNumber returns the account object for the provided address at the canonical block at the provided height
-func (b *Backend) GetAccountByNumber(ctx context.Context, address common.Address, blockNumber rpc.BlockNumber) (*types.StateAccount, error) {
+// canonicalHashByNumber returns the hash of the canonical block at the provided height, resolving the latest and
+// earliest tags to the indexed head and first block; the pending block is not indexed
+func (b *Backend) canonicalHashByNumber(blockNumber rpc.BlockNumber) (common.Hash, error) {
 	var err error
 	number := blockNumber.Int64()
 	if blockNumber == rpc.LatestBlockNumber {
 		number, err = b.Retriever.RetrieveLastBlockNumber()
 		if err != nil {
-			return nil, err
+			return common.Hash{}, err
 		}
 	}
 	if blockNumber == rpc.EarliestBlockNumber {
 		number, err = b.Retriever.RetrieveFirstBlockNumber()
 		if err != nil {
-			return nil, err
+			return common.Hash{}, err
 		}
 	}
 	if blockNumber == rpc.PendingBlockNumber {
-		return nil, errPendingBlockNumber
+		return common.Hash{}, errPendingBlockNumber
+	}
+	if number < 0 {
+		return common.Hash{}, errNegativeBlockNumber
 	}
 	hash, err := b.GetCanonicalHash(uint64(number))
 	if err == sql.ErrNoRows {
-		return nil, errHeaderNotFound
-	} else if err != nil {
+		return common.Hash{}, errHeaderNotFound
+	}
+	return hash, err
+}
+
+// GetAccountByNumberOrHash returns the account object for the provided address at the block corresponding to the provided number or hash
+func (b *Backend) GetAccountByNumberOrHash(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*types.StateAccount, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok {
+		return b.GetAccountByNumber(ctx, address, blockNr)
+	}
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return b.GetAccountByHash(ctx, address, hash)
+	}
+	return nil, errors.New("invalid arguments; neither block nor hash specified")
+}
+
+// GetAccountByNumber returns the account object for the provided address at the canonical block at the provided height
+func (b *Backend) GetAccountByNumber(ctx context.Context, address common.Address, blockNumber rpc.BlockNumber) (*types.StateAccount, error) {
+	hash, err := b.canonicalHashByNumber(blockNumber)
+	if err != nil {
 		return nil, err
 	}
 
@@ -764,6 +1056,45 @@ func (b *Backend) GetAccountByHash(ctx context.Context, address common.Address,
 	return acct, rlp.DecodeBytes(accountRlp, acct)
 }
 
+// GetBalanceByNumberOrHash returns the balance of the provided address at the block corresponding to the provided number or hash,
+// decoding only the balance field of the account
+func (b *Backend) GetBalanceByNumberOrHash(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*big.Int, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok {
+		return b.GetBalanceByNumber(ctx, address, blockNr)
+	}
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return b.GetBalanceByHash(ctx, address, hash)
+	}
+	return nil, errors.New("invalid arguments; neither block nor hash specified")
+}
+
+// GetBalanceByNumber returns the balance of the provided address at the canonical block at the provided height
+func (b *Backend) GetBalanceByNumber(ctx context.Context, address common.Address, blockNumber rpc.BlockNumber) (*big.Int, error) {
+	hash, err := b.canonicalHashByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.GetBalanceByHash(ctx, address, hash)
+}
+
+// GetBalanceByHash returns the balance of the provided address at the block with the provided hash
+func (b *Backend) GetBalanceByHash(ctx context.Context, address common.Address, hash common.Hash) (*big.Int, error) {
+	_, err := b.HeaderByHash(context.Background(), hash)
+	if err == sql.ErrNoRows {
+		return nil, errHeaderHashNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, accountRlp, err := b.IPLDRetriever.RetrieveAccountByAddressAndBlockHash(address, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeAccountBalance(accountRlp)
+}
+
 // GetCodeByNumberOrHash returns the byte code for the contract deployed at the provided address at the block with the provided hash or block number
 func (b *Backend) GetCodeByNumberOrHash(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) ([]byte, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
@@ -777,64 +1108,16 @@ func (b *Backend) GetCodeByNumberOrHash(ctx context.Context, address common.Addr
 
 // GetCodeByNumber returns the byte code for the contract deployed at the provided address at the canonical block with the provided block number
 func (b *Backend) GetCodeByNumber(ctx context.Context, address common.Address, blockNumber rpc.BlockNumber) ([]byte, error) {
-	var err error
-	number := blockNumber.Int64()
-	if blockNumber == rpc.LatestBlockNumber {
-		number, err = b.Retriever.RetrieveLastBlockNumber()
-		if err != nil {
-			return nil, err
-		}
-	}
-	if blockNumber == rpc.EarliestBlockNumber {
-		number, err = b.Retriever.RetrieveFirstBlockNumber()
-		if err != nil {
-			return nil, err
-		}
-	}
-	if blockNumber == rpc.PendingBlockNumber {
-		return nil, errPendingBlockNumber
-	}
-	hash, err := b.GetCanonicalHash(uint64(number))
+	hash, err := b.canonicalHashByNumber(blockNumber)
 	if err != nil {
 		return nil, err
 	}
-	if hash == (common.Hash{}) {
-		return nil, fmt.Errorf("no canoncial block hash found for provided height (%d)", number)
-	}
 	return b.GetCodeByHash(ctx, address, hash)
 }
 
 // GetCodeByHash returns the byte code for the contract deployed at the provided address at the block with the provided hash
 func (b *Backend) GetCodeByHash(ctx context.Context, address common.Address, hash common.Hash) ([]byte, error) {
-	codeHash := make([]byte, 0)
-	leafKey := crypto.Keccak256Hash(address.Bytes())
-	// Begin tx
-	tx, err := b.DB.Beginx()
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if p := recover(); p != nil {
-			shared.Rollback(tx)
-			panic(p)
-		} else if err != nil {
-			shared.Rollback(tx)
-		} else {
-			err = tx.Commit()
-		}
-	}()
-	err = tx.Get(&codeHash, RetrieveCodeHashByLeafKeyAndBlockHash, leafKey.Hex(), hash.Hex())
-	if err != nil {
-		return nil, err
-	}
-	var mhKey string
-	mhKey, err = ethServerShared.MultihashKeyFromKeccak256(common.BytesToHash(codeHash))
-	if err != nil {
-		return nil, err
-	}
-	code := make([]byte, 0)
-	err = tx.Get(&code, RetrieveCodeByMhKey, mhKey)
-	return code, err
+	return b.IPLDRetriever.RetrieveCodeByAddressAndBlockHash(address, hash)
 }
 
 // GetStorageByNumberOrHash returns the storage value for the provided contract address an storage key at the block corresponding to the provided number or hash
@@ -850,27 +1133,8 @@ func (b *Backend) GetStorageByNumberOrHash(ctx context.Context, address common.A
 
 // GetStorageByNumber returns the storage value for the provided contract address an storage key at the block corresponding to the provided number
 func (b *Backend) GetStorageByNumber(ctx context.Context, address common.Address, key common.Hash, blockNumber rpc.BlockNumber) (hexutil.Bytes, error) {
-	var err error
-	number := blockNumber.Int64()
-	if blockNumber == rpc.LatestBlockNumber {
-		number, err = b.Retriever.RetrieveLastBlockNumber()
-		if err != nil {
-			return nil, err
-		}
-	}
-	if blockNumber == rpc.EarliestBlockNumber {
-		number, err = b.Retriever.RetrieveFirstBlockNumber()
-		if err != nil {
-			return nil, err
-		}
-	}
-	if blockNumber == rpc.PendingBlockNumber {
-		return nil, errPendingBlockNumber
-	}
-	hash, err := b.GetCanonicalHash(uint64(number))
-	if err == sql.ErrNoRows {
-		return nil, errHeaderNotFound
-	} else if err != nil {
+	hash, err := b.canonicalHashByNumber(blockNumber)
+	if err != nil {
 		return nil, err
 	}
 
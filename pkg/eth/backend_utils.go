@@ -266,16 +266,6 @@ func getBlockAndUncleHashes(cli *rpc.Client, ctx context.Context, method string,
 	return types.NewBlockWithHeader(head).WithBody(txs, nil), body.UncleHashes, nil
 }
 
-// newRPCRawTransactionFromBlockIndex returns the bytes of a transaction given a block and a transaction index.
-func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.Bytes {
-	txs := b.Transactions()
-	if index >= uint64(len(txs)) {
-		return nil
-	}
-	blob, _ := rlp.EncodeToBytes(txs[index])
-	return blob
-}
-
 // newRPCTransactionFromBlockIndex returns a transaction that will serialize to the RPC representation.
 func newRPCTransactionFromBlockIndex(b *types.Block, index uint64) *RPCTransaction {
 	txs := b.Transactions()
@@ -313,6 +303,70 @@ func toBlockNumArg(number *big.Int) string {
 	return hexutil.EncodeBig(number)
 }
 
+// proofList implements ethdb.KeyValueWriter and collects the nodes of a merkle proof
+type proofList [][]byte
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
+
+func (n *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// GetStorageProofs assembles the values and merkle proofs for the provided storage keys from a single storage trie.
+// Each lookup resolves the nodes along its path into the trie, so nodes shared between the paths of the keys
+// are only fetched once and the proofs are then generated from the resolved nodes in memory.
+func GetStorageProofs(storageTrie state.Trie, storageKeys []string) ([]StorageResult, error) {
+	storageProof := make([]StorageResult, len(storageKeys))
+	for i, key := range storageKeys {
+		slot := common.HexToHash(key)
+		enc, err := storageTrie.TryGet(slot.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		value := new(big.Int)
+		if len(enc) > 0 {
+			_, content, _, err := rlp.Split(enc)
+			if err != nil {
+				return nil, err
+			}
+			value.SetBytes(content)
+		}
+		var proof proofList
+		if err := storageTrie.Prove(crypto.Keccak256(slot.Bytes()), 0, &proof); err != nil {
+			return nil, err
+		}
+		storageProof[i] = StorageResult{key, (*hexutil.Big)(value), toHexSlice(proof)}
+	}
+	return storageProof, nil
+}
+
+// DecodeAccountBalance decodes only the balance of an RLP encoded state account,
+// skipping over the nonce and leaving the storage root and code hash undecoded
+func DecodeAccountBalance(accountRLP []byte) (*big.Int, error) {
+	fields, _, err := rlp.SplitList(accountRLP)
+	if err != nil {
+		return nil, err
+	}
+	_, _, rest, err := rlp.Split(fields) // nonce
+	if err != nil {
+		return nil, err
+	}
+	kind, balance, _, err := rlp.Split(rest)
+	if err != nil {
+		return nil, err
+	}
+	if kind == rlp.List {
+		return nil, fmt.Errorf("expected account balance to be an rlp string, got %v", kind)
+	}
+	if len(balance) > 0 && balance[0] == 0 {
+		return nil, rlp.ErrCanonInt
+	}
+	return new(big.Int).SetBytes(balance), nil
+}
+
 func getIteratorAtPath(t state.Trie, startKey []byte) (trie.NodeIterator, int64) {
 	startTime := makeTimestamp()
 	var it trie.NodeIterator
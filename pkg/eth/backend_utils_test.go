@@ -0,0 +1,139 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+)
+
+// countingDB counts the node reads hitting the underlying key-value store
+type countingDB struct {
+	ethdb.KeyValueStore
+	reads int
+}
+
+func (db *countingDB) Get(key []byte) ([]byte, error) {
+	db.reads++
+	return db.KeyValueStore.Get(key)
+}
+
+var _ = Describe("GetStorageProofs", func() {
+	It("Assembles valid proofs for many keys while fetching each trie node at most once", func() {
+		diskdb := rawdb.NewMemoryDatabase()
+		tr, err := trie.NewStateTrie(common.Hash{}, common.Hash{}, trie.NewDatabase(diskdb))
+		Expect(err).ToNot(HaveOccurred())
+		keys := make([]string, 0, 64)
+		for i := int64(1); i <= 64; i++ {
+			slot := common.BigToHash(big.NewInt(i))
+			enc, err := rlp.EncodeToBytes(big.NewInt(i * 100).Bytes())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tr.TryUpdate(slot.Bytes(), enc)).To(Succeed())
+			keys = append(keys, slot.Hex())
+		}
+		// include a key that does not exist in the trie
+		keys = append(keys, common.BigToHash(big.NewInt(1000)).Hex())
+		root, nodes, err := tr.Commit(false)
+		Expect(err).ToNot(HaveOccurred())
+		trieDB := trie.NewDatabase(diskdb)
+		Expect(trieDB.Update(trie.NewWithNodeSet(nodes))).To(Succeed())
+		Expect(trieDB.Commit(root, false, nil)).To(Succeed())
+
+		counter := &countingDB{KeyValueStore: diskdb}
+		storageTrie, err := trie.NewStateTrie(common.Hash{}, root, trie.NewDatabase(counter))
+		Expect(err).ToNot(HaveOccurred())
+		counter.reads = 0
+		proofs, err := eth.GetStorageProofs(storageTrie, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(proofs)).To(Equal(len(keys)))
+
+		uniqueNodes := make(map[string]struct{})
+		for i, result := range proofs {
+			Expect(result.Key).To(Equal(keys[i]))
+			proofDB := memorydb.New()
+			for _, node := range result.Proof {
+				enc := common.FromHex(node)
+				Expect(proofDB.Put(crypto.Keccak256(enc), enc)).To(Succeed())
+				uniqueNodes[node] = struct{}{}
+			}
+			value, err := trie.VerifyProof(root, crypto.Keccak256(common.HexToHash(result.Key).Bytes()), proofDB)
+			Expect(err).ToNot(HaveOccurred())
+			if i < 64 {
+				var content []byte
+				Expect(rlp.DecodeBytes(value, &content)).To(Succeed())
+				Expect(new(big.Int).SetBytes(content)).To(Equal(big.NewInt(int64(i+1) * 100)))
+				Expect(result.Value.ToInt()).To(Equal(big.NewInt(int64(i+1) * 100)))
+			} else {
+				Expect(value).To(BeNil())
+				Expect(result.Value.ToInt().Sign()).To(Equal(0))
+			}
+		}
+		// the root is resolved when opening the trie, every other node on the proof paths is fetched exactly once
+		Expect(counter.reads).To(BeNumerically("<=", len(uniqueNodes)))
+	})
+})
+
+var _ = Describe("DecodeAccountBalance", func() {
+	It("Decodes the same balance as the full account decode", func() {
+		balances := []*big.Int{
+			big.NewInt(0),
+			big.NewInt(1),
+			big.NewInt(127),
+			big.NewInt(128),
+			new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil),
+		}
+		for i, balance := range balances {
+			accountRLP, err := rlp.EncodeToBytes(&types.StateAccount{
+				Nonce:    uint64(i * 1000),
+				Balance:  balance,
+				Root:     crypto.Keccak256Hash([]byte{byte(i)}),
+				CodeHash: crypto.Keccak256(nil),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			account := new(types.StateAccount)
+			Expect(rlp.DecodeBytes(accountRLP, account)).To(Succeed())
+			decoded, err := eth.DecodeAccountBalance(accountRLP)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded.Cmp(account.Balance)).To(Equal(0))
+			Expect(decoded.Cmp(balance)).To(Equal(0))
+		}
+	})
+	It("Throws an error for input which is not an encoded account", func() {
+		_, err := eth.DecodeAccountBalance(eth.EmptyNodeValue)
+		Expect(err).To(HaveOccurred())
+
+		_, err = eth.DecodeAccountBalance([]byte{})
+		Expect(err).To(HaveOccurred())
+
+		// a balance with a leading zero byte is not canonical
+		_, err = eth.DecodeAccountBalance(common.FromHex("0xc401820001"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -17,13 +17,22 @@
 package eth
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/statediff/indexer/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -44,8 +53,18 @@ type Retriever interface {
 type CIDRetriever struct {
 	db     *sqlx.DB
 	gormDB *gorm.DB
+
+	// QueryTimeout bounds how long a single query may run, 0 disables the timeout
+	QueryTimeout time.Duration
+
+	// LogsMaxResults bounds the number of logs a single log query may return, 0 disables the limit; it is the
+	// backend's Config.LogsMaxResults, as a query over more logs than can be served is wasted
+	LogsMaxResults int
 }
 
+// ErrLogResultSetTooLarge is returned when a log query or filter matches more logs than the configured LogsMaxResults
+var ErrLogResultSetTooLarge = errors.New("log result set too large")
+
 type IPLDModelRecord struct {
 	models.IPLDModel
 }
@@ -68,6 +87,8 @@ type HeaderCIDRecord struct {
 	UncleRoot       string
 	Bloom           []byte
 	MhKey           string
+	Coinbase        string
+	NodeID          string `gorm:"column:node_id"`
 
 	// gorm doesn't check if foreign key exists in database.
 	// It is required to eager load relations using preload.
@@ -98,37 +119,257 @@ func (TransactionCIDRecord) TableName() string {
 }
 
 // NewCIDRetriever returns a pointer to a new CIDRetriever which supports the CIDRetriever interface
-func NewCIDRetriever(db *sqlx.DB) *CIDRetriever {
+func NewCIDRetriever(db *sqlx.DB) (*CIDRetriever, error) {
 	gormDB, err := gorm.Open(postgres.New(postgres.Config{
 		Conn: db,
 	}), &gorm.Config{})
 
 	if err != nil {
-		log.Error(err)
-		return nil
+		return nil, fmt.Errorf("unable to open gorm connection for CID retriever: %w", err)
 	}
 
 	return &CIDRetriever{
 		db:     db,
 		gormDB: gormDB,
+	}, nil
+}
+
+// RetrieveHeadHeader retrieves the canonical header at the highest indexed block number
+func (ecr *CIDRetriever) RetrieveHeadHeader() (*types.Header, error) {
+	log.Debug("retrieving head header")
+	pgStr := `SELECT data FROM eth.header_cids
+			INNER JOIN public.blocks ON (
+				header_cids.mh_key = blocks.key
+				AND header_cids.block_number = blocks.block_number
+			)
+			WHERE header_cids.block_hash = (SELECT canonical_header_hash((SELECT MAX(block_number) FROM eth.header_cids)))`
+	var headerRLP []byte
+	if err := getWithTimeout(ecr.QueryTimeout, ecr.db, &headerRLP, pgStr); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errHeaderNotFound
+		}
+		return nil, err
+	}
+	header := new(types.Header)
+	return header, rlp.DecodeBytes(headerRLP, header)
+}
+
+// RetrieveHeaderByNumberOrHash retrieves the header for the provided block number or hash,
+// the canonical header is returned when retrieving by number
+func (ecr *CIDRetriever) RetrieveHeaderByNumberOrHash(numberOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	var headerRLP []byte
+	if blockNumber, ok := numberOrHash.Number(); ok {
+		number := blockNumber.Int64()
+		var err error
+		switch blockNumber {
+		case rpc.LatestBlockNumber:
+			if number, err = ecr.RetrieveLastBlockNumber(); err != nil {
+				return nil, err
+			}
+		case rpc.EarliestBlockNumber:
+			if number, err = ecr.RetrieveFirstBlockNumber(); err != nil {
+				return nil, err
+			}
+		case rpc.PendingBlockNumber:
+			return nil, errPendingBlockNumber
+		}
+		if number < 0 {
+			return nil, errNegativeBlockNumber
+		}
+		log.Debug("retrieving canonical header for block ", number)
+		pgStr := `SELECT data FROM eth.header_cids
+			INNER JOIN public.blocks ON (
+				header_cids.mh_key = blocks.key
+				AND header_cids.block_number = blocks.block_number
+			)
+			WHERE header_cids.block_hash = (SELECT canonical_header_hash($1))`
+		if err := getWithTimeout(ecr.QueryTimeout, ecr.db, &headerRLP, pgStr, number); err != nil {
+			return nil, err
+		}
+	} else if hash, ok := numberOrHash.Hash(); ok {
+		log.Debug("retrieving header for block hash ", hash.Hex())
+		pgStr := `SELECT data, header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number)) AS canonical
+			FROM eth.header_cids
+			INNER JOIN public.blocks ON (
+				header_cids.mh_key = blocks.key
+				AND header_cids.block_number = blocks.block_number
+			)
+			WHERE header_cids.block_hash = $1`
+		var res struct {
+			Data      []byte `db:"data"`
+			Canonical bool   `db:"canonical"`
+		}
+		if err := getWithTimeout(ecr.QueryTimeout, ecr.db, &res, pgStr, hash.Hex()); err != nil {
+			return nil, err
+		}
+		if numberOrHash.RequireCanonical && !res.Canonical {
+			return nil, errors.New("hash is not currently canonical")
+		}
+		headerRLP = res.Data
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	header := new(types.Header)
+	return header, rlp.DecodeBytes(headerRLP, header)
+}
+
+// RetrieveHeaderWithTdByHash retrieves the header for the provided block hash along with its total difficulty
+func (ecr *CIDRetriever) RetrieveHeaderWithTdByHash(hash common.Hash) (*types.Header, *big.Int, error) {
+	log.Debug("retrieving header and total difficulty for block hash ", hash.Hex())
+	pgStr := `SELECT data, CAST(td AS TEXT) AS td
+		FROM eth.header_cids
+		INNER JOIN public.blocks ON (
+			header_cids.mh_key = blocks.key
+			AND header_cids.block_number = blocks.block_number
+		)
+		WHERE header_cids.block_hash = $1`
+	var res struct {
+		Data []byte `db:"data"`
+		TD   string `db:"td"`
+	}
+	if err := getWithTimeout(ecr.QueryTimeout, ecr.db, &res, pgStr, hash.Hex()); err != nil {
+		return nil, nil, err
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(res.Data, header); err != nil {
+		return nil, nil, err
+	}
+	td, ok := new(big.Int).SetString(res.TD, 10)
+	if !ok {
+		return nil, nil, errors.New("total difficulty retrieved from Postgres cannot be converted to an integer")
+	}
+	return header, td, nil
+}
+
+// RetrieveBlockNumberByHash returns the number of the block with the provided hash without decoding its header;
+// it errors if the block is not indexed or is not canonical
+func (ecr *CIDRetriever) RetrieveBlockNumberByHash(hash common.Hash) (int64, error) {
+	log.Debug("retrieving block number for block hash ", hash.Hex())
+	pgStr := `SELECT block_number,
+				COALESCE(block_hash = (SELECT canonical_header_hash(block_number)), false) AS canonical
+			FROM eth.header_cids
+			WHERE block_hash = $1`
+	var res struct {
+		BlockNumber int64 `db:"block_number"`
+		Canonical   bool  `db:"canonical"`
+	}
+	if err := getWithTimeout(ecr.QueryTimeout, ecr.db, &res, pgStr, hash.Hex()); err != nil {
+		return 0, err
+	}
+	if !res.Canonical {
+		return 0, fmt.Errorf("block %s at height %d is not canonical", hash.Hex(), res.BlockNumber)
+	}
+	return res.BlockNumber, nil
+}
+
+// RetrieveAllBlockHashesByNumber returns the hashes of every header indexed at the provided height, the canonical
+// one first, each flagged with whether it is the canonical header at that height
+func (ecr *CIDRetriever) RetrieveAllBlockHashesByNumber(number int64) ([]BlockHashResult, error) {
+	log.Debug("retrieving all block hashes for block number ", number)
+	pgStr := `SELECT block_hash,
+				COALESCE(block_hash = (SELECT canonical_header_hash($1)), false) AS canonical
+			FROM eth.header_cids
+			WHERE block_number = $1
+			ORDER BY canonical DESC, block_hash`
+	hashes := make([]BlockHashResult, 0)
+	return hashes, selectWithTimeout(ecr.QueryTimeout, ecr.db, &hashes, pgStr, number)
+}
+
+// RetrieveHeaderChain retrieves the canonical headers from the block with fromHash up to and including
+// the block with toHash, ordered by block number
+// it errors if the two blocks are not connected on the canonical chain
+func (ecr *CIDRetriever) RetrieveHeaderChain(fromHash, toHash common.Hash) ([]*types.Header, error) {
+	log.Debugf("retrieving header chain from %s to %s", fromHash.Hex(), toHash.Hex())
+	pgStr := `SELECT data FROM eth.header_cids
+			INNER JOIN public.blocks ON (
+				header_cids.mh_key = blocks.key
+				AND header_cids.block_number = blocks.block_number
+			)
+			WHERE header_cids.block_number BETWEEN (SELECT block_number FROM eth.header_cids WHERE block_hash = $1 LIMIT 1)
+				AND (SELECT block_number FROM eth.header_cids WHERE block_hash = $2 LIMIT 1)
+			AND header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number))
+			ORDER BY header_cids.block_number`
+	var headerRLPs [][]byte
+	if err := selectWithTimeout(ecr.QueryTimeout, ecr.db, &headerRLPs, pgStr, fromHash.Hex(), toHash.Hex()); err != nil {
+		return nil, err
+	}
+
+	notConnected := fmt.Errorf("blocks %s and %s are not connected on the canonical chain", fromHash.Hex(), toHash.Hex())
+	headers := make([]*types.Header, len(headerRLPs))
+	for i, headerRLP := range headerRLPs {
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(headerRLP, header); err != nil {
+			return nil, err
+		}
+		if i > 0 && header.ParentHash != headers[i-1].Hash() {
+			return nil, notConnected
+		}
+		headers[i] = header
+	}
+	if len(headers) == 0 || headers[0].Hash() != fromHash || headers[len(headers)-1].Hash() != toHash {
+		return nil, notConnected
 	}
+	return headers, nil
 }
 
 // RetrieveFirstBlockNumber is used to retrieve the first block number in the db
 func (ecr *CIDRetriever) RetrieveFirstBlockNumber() (int64, error) {
 	var blockNumber int64
-	err := ecr.db.Get(&blockNumber, "SELECT block_number FROM eth.header_cids ORDER BY block_number ASC LIMIT 1")
+	err := getWithTimeout(ecr.QueryTimeout, ecr.db, &blockNumber, "SELECT block_number FROM eth.header_cids ORDER BY block_number ASC LIMIT 1")
 	return blockNumber, err
 }
 
+// RetrieveGapsInData is used to find the block heights at which data is missing from the db
+// it returns the gaps where no headers exist, followed by the gaps where the headers have been validated
+// fewer than validationLevel times
+func (ecr *CIDRetriever) RetrieveGapsInData(validationLevel int) ([]shared.Gap, error) {
+	log.Info("searching for gaps in the eth ipld database")
+	startingBlock, err := ecr.RetrieveFirstBlockNumber()
+	if err != nil {
+		return nil, fmt.Errorf("eth CIDRetriever RetrieveFirstBlockNumber error: %v", err)
+	}
+	gaps := make([]shared.Gap, 0)
+	if startingBlock != 0 {
+		stop := uint64(startingBlock - 1)
+		log.Infof("found gap at the beginning of the eth sync from 0 to %d", stop)
+		gaps = append(gaps, shared.Gap{Start: 0, Stop: stop})
+	}
+
+	// a gap starts after each height which has no header at the next height, and stops before the next height with a header
+	pgStr := `SELECT header_cids.block_number + 1 AS start, MIN(fr.block_number) - 1 AS stop
+			FROM eth.header_cids
+				LEFT JOIN eth.header_cids r ON header_cids.block_number = r.block_number - 1
+				LEFT JOIN eth.header_cids fr ON header_cids.block_number < fr.block_number
+			WHERE r.block_number IS NULL AND fr.block_number IS NOT NULL
+			GROUP BY header_cids.block_number, r.block_number
+			ORDER BY start`
+	results := make([]shared.Gap, 0)
+	if err := selectWithTimeout(ecr.QueryTimeout, ecr.db, &results, pgStr); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	gaps = append(gaps, results...)
+
+	// the heights below the validation level, there is no overlap between these and the empty gaps above
+	pgStr = `SELECT DISTINCT block_number
+			FROM eth.header_cids
+			WHERE times_validated < $1
+			ORDER BY block_number`
+	var heights []uint64
+	if err := selectWithTimeout(ecr.QueryTimeout, ecr.db, &heights, pgStr, validationLevel); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return append(gaps, shared.MissingHeightsToGaps(heights)...), nil
+}
+
 // RetrieveLastBlockNumber is used to retrieve the latest block number in the db
 func (ecr *CIDRetriever) RetrieveLastBlockNumber() (int64, error) {
 	var blockNumber int64
-	err := ecr.db.Get(&blockNumber, "SELECT block_number FROM eth.header_cids ORDER BY block_number DESC LIMIT 1")
+	err := getWithTimeout(ecr.QueryTimeout, ecr.db, &blockNumber, "SELECT block_number FROM eth.header_cids ORDER BY block_number DESC LIMIT 1")
 	return blockNumber, err
 }
 
 // Retrieve is used to retrieve all of the CIDs which conform to the passed StreamFilters
+// Only the canonical block at the height is retrieved, the block is empty if there is none
 func (ecr *CIDRetriever) Retrieve(filter SubscriptionSettings, blockNumber int64) ([]CIDWrapper, bool, error) {
 	log.Debug("retrieving cids")
 
@@ -148,13 +389,18 @@ func (ecr *CIDRetriever) Retrieve(filter SubscriptionSettings, blockNumber int64
 		}
 	}()
 
-	// Retrieve cached header CIDs at this block height
-	var headers []models.HeaderModel
-	headers, err = ecr.RetrieveHeaderCIDs(tx, blockNumber)
+	// Retrieve the cached canonical header CID at this block height
+	var header models.HeaderModel
+	header, err = ecr.RetrieveCanonicalHeaderCIDByNumber(tx, blockNumber)
+	if err == sql.ErrNoRows {
+		err = nil
+		return nil, true, nil
+	}
 	if err != nil {
 		log.Error("header cid retrieval error", err)
 		return nil, true, err
 	}
+	headers := []models.HeaderModel{header}
 	cws := make([]CIDWrapper, len(headers))
 	empty := true
 	for i, header := range headers {
@@ -236,7 +482,20 @@ func (ecr *CIDRetriever) RetrieveHeaderCIDs(tx *sqlx.Tx, blockNumber int64) ([]m
 				CAST(reward as Text), state_root, uncle_root,tx_root, receipt_root, bloom, timestamp, times_validated, coinbase
 				FROM eth.header_cids
 				WHERE block_number = $1`
-	return headers, tx.Select(&headers, pgStr, blockNumber)
+	return headers, selectWithTimeout(ecr.QueryTimeout, tx, &headers, pgStr, blockNumber)
+}
+
+// RetrieveCanonicalHeaderCIDByNumber retrieves and returns the header cid of the canonical block at the provided
+// blockheight, returning sql.ErrNoRows if there is none
+func (ecr *CIDRetriever) RetrieveCanonicalHeaderCIDByNumber(tx *sqlx.Tx, blockNumber int64) (models.HeaderModel, error) {
+	log.Debug("retrieving canonical header cid for block ", blockNumber)
+	pgStr := `SELECT CAST(block_number as Text), block_hash, parent_hash, cid, mh_key, CAST(td as Text), node_id,
+				CAST(reward as Text), state_root, uncle_root,tx_root, receipt_root, bloom, timestamp, times_validated, coinbase
+				FROM eth.header_cids
+				WHERE block_number = $1
+				AND block_hash = (SELECT canonical_header_hash($1))`
+	var headerCID models.HeaderModel
+	return headerCID, getWithTimeout(ecr.QueryTimeout, tx, &headerCID, pgStr, blockNumber)
 }
 
 // RetrieveUncleCIDsByHeaderID retrieves and returns all of the uncle cids for the provided header
@@ -246,14 +505,14 @@ func (ecr *CIDRetriever) RetrieveUncleCIDsByHeaderID(tx *sqlx.Tx, headerID strin
 	pgStr := `SELECT CAST(block_number as Text), header_id, block_hash, parent_hash, cid, mh_key, CAST(reward as text)
 				FROM eth.uncle_cids
 				WHERE header_id = $1`
-	return headers, tx.Select(&headers, pgStr, headerID)
+	return headers, selectWithTimeout(ecr.QueryTimeout, tx, &headers, pgStr, headerID)
 }
 
 // RetrieveTxCIDs retrieves and returns all of the trx cids at the provided blockheight that conform to the provided filter parameters
 // also returns the ids for the returned transaction cids
 func (ecr *CIDRetriever) RetrieveTxCIDs(tx *sqlx.Tx, txFilter TxFilter, headerID string) ([]models.TxModel, error) {
 	log.Debug("retrieving transaction cids for header id ", headerID)
-	args := make([]interface{}, 0, 3)
+	args := make([]interface{}, 0, 4)
 	results := make([]models.TxModel, 0)
 	id := 1
 	pgStr := fmt.Sprintf(`SELECT CAST(transaction_cids.block_number as Text), transaction_cids.tx_hash,
@@ -275,9 +534,23 @@ func (ecr *CIDRetriever) RetrieveTxCIDs(tx *sqlx.Tx, txFilter TxFilter, headerID
 	if len(txFilter.Src) > 0 {
 		pgStr += fmt.Sprintf(` AND transaction_cids.src = ANY($%d::VARCHAR(66)[])`, id)
 		args = append(args, pq.Array(txFilter.Src))
+		id++
+	}
+	if len(txFilter.Types) > 0 {
+		pgStr += fmt.Sprintf(` AND transaction_cids.tx_type = ANY($%d::INTEGER[])`, id)
+		args = append(args, pq.Array(txTypes(txFilter.Types)))
 	}
 	pgStr += ` ORDER BY transaction_cids.index`
-	return results, tx.Select(&results, pgStr, args...)
+	return results, selectWithTimeout(ecr.QueryTimeout, tx, &results, pgStr, args...)
+}
+
+// txTypes converts the transaction types into a slice pq can encode as an array; a []uint8 would be encoded as bytea
+func txTypes(types []uint8) []int64 {
+	ints := make([]int64, len(types))
+	for i, t := range types {
+		ints[i] = int64(t)
+	}
+	return ints
 }
 
 func topicFilterCondition(id *int, topics [][]string, args []interface{}, pgStr string, first bool) (string, []interface{}) {
@@ -388,33 +661,34 @@ func (ecr *CIDRetriever) RetrieveFilteredGQLLogs(tx *sqlx.Tx, rctFilter ReceiptF
 	pgStr, args = logFilterCondition(&id, pgStr, args, rctFilter)
 	pgStr += ` ORDER BY log_cids.index`
 
-	logCIDs := make([]LogResult, 0)
-	err := tx.Select(&logCIDs, pgStr, args...)
-	if err != nil {
-		return nil, err
-	}
-
-	return logCIDs, nil
+	return ecr.selectLimitedLogs(tx, id, pgStr, args)
 }
 
+// filteredLogsPgStr selects the logs of indexed blocks along with their receipt and transaction context, and whether
+// their block is no longer canonical
+const filteredLogsPgStr = `SELECT CAST(eth.log_cids.block_number as Text), eth.log_cids.leaf_cid, eth.log_cids.index, eth.log_cids.rct_id,
+			eth.log_cids.address, eth.log_cids.topic0, eth.log_cids.topic1, eth.log_cids.topic2, eth.log_cids.topic3,
+			eth.log_cids.log_data, eth.transaction_cids.tx_hash, eth.transaction_cids.index as txn_index,
+			eth.receipt_cids.leaf_cid as cid, eth.receipt_cids.post_status, header_cids.block_hash,
+			header_cids.block_hash <> (SELECT canonical_header_hash(header_cids.block_number)) AS removed, blocks.data
+						FROM eth.log_cids, eth.receipt_cids, eth.transaction_cids, eth.header_cids, public.blocks
+						WHERE eth.log_cids.rct_id = receipt_cids.tx_id
+						AND log_cids.leaf_mh_key = blocks.key
+						AND log_cids.block_number = blocks.block_number
+						AND eth.log_cids.header_id = eth.receipt_cids.header_id
+						AND eth.log_cids.block_number = eth.receipt_cids.block_number
+						AND receipt_cids.tx_id = transaction_cids.tx_hash
+						AND receipt_cids.header_id = transaction_cids.header_id
+						AND receipt_cids.block_number = transaction_cids.block_number
+						AND transaction_cids.header_id = header_cids.block_hash
+						AND transaction_cids.block_number = header_cids.block_number`
+
 // RetrieveFilteredLog retrieves and returns all the log CIDs provided blockHeight or blockHash that conform to the provided
-// filter parameters.
+// filter parameters. Logs of non-canonical blocks are included, with their removed flag set.
 func (ecr *CIDRetriever) RetrieveFilteredLog(tx *sqlx.Tx, rctFilter ReceiptFilter, blockNumber int64, blockHash *common.Hash) ([]LogResult, error) {
 	log.Debug("retrieving log cids for receipt ids")
 	args := make([]interface{}, 0, 4)
-	pgStr := `SELECT CAST(eth.log_cids.block_number as Text), eth.log_cids.leaf_cid, eth.log_cids.index, eth.log_cids.rct_id,
-			eth.log_cids.address, eth.log_cids.topic0, eth.log_cids.topic1, eth.log_cids.topic2, eth.log_cids.topic3,
-			eth.log_cids.log_data, eth.transaction_cids.tx_hash, eth.transaction_cids.index as txn_index,
-			eth.receipt_cids.leaf_cid as cid, eth.receipt_cids.post_status, header_cids.block_hash
-							FROM eth.log_cids, eth.receipt_cids, eth.transaction_cids, eth.header_cids
-							WHERE eth.log_cids.rct_id = receipt_cids.tx_id
-							AND eth.log_cids.header_id = eth.receipt_cids.header_id
-							AND eth.log_cids.block_number = eth.receipt_cids.block_number
-							AND receipt_cids.tx_id = transaction_cids.tx_hash
-							AND receipt_cids.header_id = transaction_cids.header_id
-							AND receipt_cids.block_number = transaction_cids.block_number
-							AND transaction_cids.header_id = header_cids.block_hash
-							AND transaction_cids.block_number = header_cids.block_number`
+	pgStr := filteredLogsPgStr
 	id := 1
 	if blockNumber > 0 {
 		pgStr += fmt.Sprintf(` AND header_cids.block_number = $%d`, id)
@@ -427,18 +701,186 @@ func (ecr *CIDRetriever) RetrieveFilteredLog(tx *sqlx.Tx, rctFilter ReceiptFilte
 		id++
 	}
 
+	// skip the blocks whose logs bloom guarantees that none of their logs match the filter
+	if (blockNumber > 0 || blockHash != nil) && (len(rctFilter.LogAddresses) > 0 || hasTopics(rctFilter.Topics)) {
+		candidates, err := ecr.retrieveHeaderBlooms(tx, blockNumber, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		blockHashes := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			if bloomMayMatchFilter(candidate.Bloom, rctFilter) {
+				blockHashes = append(blockHashes, candidate.BlockHash)
+			}
+		}
+		if len(blockHashes) == 0 {
+			return []LogResult{}, nil
+		}
+		if len(blockHashes) < len(candidates) {
+			pgStr += fmt.Sprintf(` AND header_cids.block_hash = ANY ($%d)`, id)
+			args = append(args, pq.Array(blockHashes))
+			id++
+		}
+	}
+
+	pgStr, args = logFilterCondition(&id, pgStr, args, rctFilter)
+	pgStr += ` ORDER BY header_cids.block_number, log_cids.index`
+
+	return ecr.selectLimitedLogs(tx, id, pgStr, args)
+}
+
+// RetrieveFilteredLogsByBlockHashes retrieves the logs matching the receipt filter that were emitted by the blocks with
+// the provided hashes, bounded to the blocks numbered from minBlock and/or up to maxBlock when they are provided.
+// As for a single block hash, logs of non-canonical blocks are included, with their removed flag set.
+func (ecr *CIDRetriever) RetrieveFilteredLogsByBlockHashes(tx *sqlx.Tx, rctFilter ReceiptFilter, blockHashes []common.Hash, minBlock, maxBlock *big.Int) ([]LogResult, error) {
+	log.Debug("retrieving log cids for a list of block hashes")
+	hashStrs := make([]string, len(blockHashes))
+	for i, hash := range blockHashes {
+		hashStrs[i] = hash.String()
+	}
+	args := []interface{}{pq.Array(hashStrs)}
+	pgStr := filteredLogsPgStr + ` AND header_cids.block_hash = ANY ($1)`
+	id := 2
+	if minBlock != nil {
+		pgStr += fmt.Sprintf(` AND header_cids.block_number >= $%d`, id)
+		args = append(args, minBlock.Int64())
+		id++
+	}
+	if maxBlock != nil {
+		pgStr += fmt.Sprintf(` AND header_cids.block_number <= $%d`, id)
+		args = append(args, maxBlock.Int64())
+		id++
+	}
+
 	pgStr, args = logFilterCondition(&id, pgStr, args, rctFilter)
-	pgStr += ` ORDER BY log_cids.index`
+	pgStr += ` ORDER BY header_cids.block_number, log_cids.index`
+
+	return ecr.selectLimitedLogs(tx, id, pgStr, args)
+}
+
+// RetrieveFilteredCanonicalLog retrieves the logs matching the receipt filter that were emitted by the canonical block
+// at the provided height, or none if there is no canonical block at the height
+func (ecr *CIDRetriever) RetrieveFilteredCanonicalLog(tx *sqlx.Tx, rctFilter ReceiptFilter, blockNumber int64) ([]LogResult, error) {
+	log.Debug("retrieving canonical log cids at block ", blockNumber)
+	var canonicalHash sql.NullString
+	if err := getWithTimeout(ecr.QueryTimeout, tx, &canonicalHash, `SELECT canonical_header_hash($1)`, blockNumber); err != nil {
+		return nil, err
+	}
+	if !canonicalHash.Valid {
+		return []LogResult{}, nil
+	}
+	blockHash := common.HexToHash(canonicalHash.String)
+	return ecr.RetrieveFilteredLog(tx, rctFilter, blockNumber, &blockHash)
+}
+
+// RetrieveFilteredCanonicalLogsInRange retrieves the logs matching the receipt filter that were emitted by the canonical
+// blocks over a block range (inclusive), a block at a time, stopping between blocks once the context is done.
+// With a limit on the number of logs (0 = unlimited), the retrieval stops at the first block taking the logs over it,
+// returning the logs of the blocks before it along with its number; the number is 0 if the logs are within the limit
+func (ecr *CIDRetriever) RetrieveFilteredCanonicalLogsInRange(ctx context.Context, tx *sqlx.Tx, rctFilter ReceiptFilter, from, to int64, limit int) ([]LogResult, int64, error) {
+	// the genesis block has no logs, and a block number of 0 is not filtered on
+	if from < 1 {
+		from = 1
+	}
+	logs := make([]LogResult, 0)
+	for i := from; i <= to; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		blockLogs, err := ecr.RetrieveFilteredCanonicalLog(tx, rctFilter, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		if limit > 0 && len(logs)+len(blockLogs) > limit {
+			return logs, i, nil
+		}
+		logs = append(logs, blockLogs...)
+	}
+	return logs, 0, nil
+}
+
+// selectLimitedLogs runs the log query, returning ErrLogResultSetTooLarge if it matches more than LogsMaxResults logs;
+// the query is limited to one log over the limit, which is enough to detect the overflow
+func (ecr *CIDRetriever) selectLimitedLogs(tx *sqlx.Tx, id int, pgStr string, args []interface{}) ([]LogResult, error) {
+	if ecr.LogsMaxResults > 0 {
+		pgStr += fmt.Sprintf(` LIMIT $%d`, id)
+		args = append(args, ecr.LogsMaxResults+1)
+	}
 
 	logCIDs := make([]LogResult, 0)
-	err := tx.Select(&logCIDs, pgStr, args...)
+	err := selectWithTimeout(ecr.QueryTimeout, tx, &logCIDs, pgStr, args...)
 	if err != nil {
 		return nil, err
 	}
+	if ecr.LogsMaxResults > 0 && len(logCIDs) > ecr.LogsMaxResults {
+		return nil, fmt.Errorf("%w: more than %d logs match the filter", ErrLogResultSetTooLarge, ecr.LogsMaxResults)
+	}
 
 	return logCIDs, nil
 }
 
+// headerBloom is the logs bloom of an indexed header
+type headerBloom struct {
+	BlockHash string `db:"block_hash"`
+	Bloom     []byte `db:"bloom"`
+}
+
+// retrieveHeaderBlooms retrieves the logs blooms of the headers at the provided block height and/or with the provided block hash
+func (ecr *CIDRetriever) retrieveHeaderBlooms(tx *sqlx.Tx, blockNumber int64, blockHash *common.Hash) ([]headerBloom, error) {
+	args := make([]interface{}, 0, 2)
+	pgStr := `SELECT block_hash, bloom FROM eth.header_cids WHERE TRUE`
+	id := 1
+	if blockNumber > 0 {
+		pgStr += fmt.Sprintf(` AND block_number = $%d`, id)
+		args = append(args, blockNumber)
+		id++
+	}
+	if blockHash != nil {
+		pgStr += fmt.Sprintf(` AND block_hash = $%d`, id)
+		args = append(args, blockHash.String())
+	}
+	blooms := make([]headerBloom, 0)
+	return blooms, selectWithTimeout(ecr.QueryTimeout, tx, &blooms, pgStr, args...)
+}
+
+// bloomMayMatchFilter reports whether a block with the provided logs bloom may hold logs matching the addresses and topics
+// of the filter; a bloom which isn't well-formed is assumed to match, so that a block is only ever skipped when its bloom
+// guarantees that none of its logs match
+func bloomMayMatchFilter(bloom []byte, rctFilter ReceiptFilter) bool {
+	if len(bloom) != types.BloomByteLength {
+		return true
+	}
+	b := types.BytesToBloom(bloom)
+	if len(rctFilter.LogAddresses) > 0 {
+		included := false
+		for _, addr := range rctFilter.LogAddresses {
+			if b.Test(common.HexToAddress(addr).Bytes()) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, topicSet := range rctFilter.Topics {
+		if len(topicSet) == 0 {
+			continue
+		}
+		included := false
+		for _, topic := range topicSet {
+			if b.Test(common.HexToHash(topic).Bytes()) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
 // RetrieveRctCIDs retrieves and returns all of the rct cids at the provided blockheight or block hash that conform to the provided
 // filter parameters and correspond to the provided tx ids
 func (ecr *CIDRetriever) RetrieveRctCIDs(tx *sqlx.Tx, rctFilter ReceiptFilter, blockNumber int64, blockHash string, txHashes []string) ([]models.ReceiptModel, error) {
@@ -468,7 +910,7 @@ func (ecr *CIDRetriever) RetrieveRctCIDs(tx *sqlx.Tx, rctFilter ReceiptFilter, b
 
 	pgStr += ` ORDER BY transaction_cids.index`
 	receiptCIDs := make([]models.ReceiptModel, 0)
-	return receiptCIDs, tx.Select(&receiptCIDs, pgStr, args...)
+	return receiptCIDs, selectWithTimeout(ecr.QueryTimeout, tx, &receiptCIDs, pgStr, args...)
 }
 
 func hasTopics(topics [][]string) bool {
@@ -506,7 +948,7 @@ func (ecr *CIDRetriever) RetrieveStateCIDs(tx *sqlx.Tx, stateFilter StateFilter,
 		pgStr += ` AND state_cids.node_type = 2`
 	}
 	stateNodeCIDs := make([]models.StateNodeModel, 0)
-	return stateNodeCIDs, tx.Select(&stateNodeCIDs, pgStr, args...)
+	return stateNodeCIDs, selectWithTimeout(ecr.QueryTimeout, tx, &stateNodeCIDs, pgStr, args...)
 }
 
 // RetrieveStorageCIDs retrieves and returns all of the storage node cids at the provided header id that conform to the provided filter parameters
@@ -543,7 +985,285 @@ func (ecr *CIDRetriever) RetrieveStorageCIDs(tx *sqlx.Tx, storageFilter StorageF
 		pgStr += ` AND storage_cids.node_type = 2`
 	}
 	storageNodeCIDs := make([]models.StorageNodeWithStateKeyModel, 0)
-	return storageNodeCIDs, tx.Select(&storageNodeCIDs, pgStr, args...)
+	return storageNodeCIDs, selectWithTimeout(ecr.QueryTimeout, tx, &storageNodeCIDs, pgStr, args...)
+}
+
+// RetrieveStorageChangeBlocks returns the canonical block numbers within the provided range (inclusive)
+// at which a leaf of the storage trie for the provided address was updated or removed
+func (ecr *CIDRetriever) RetrieveStorageChangeBlocks(address common.Address, from, to int64) ([]int64, error) {
+	log.Debugf("retrieving storage change blocks for address %s from %d to %d", address.Hex(), from, to)
+	pgStr := `SELECT DISTINCT storage_cids.block_number
+			FROM eth.storage_cids
+				INNER JOIN eth.state_cids ON (
+					storage_cids.header_id = state_cids.header_id
+					AND storage_cids.state_path = state_cids.state_path
+					AND storage_cids.block_number = state_cids.block_number
+				)
+			WHERE state_cids.state_leaf_key = $1
+			AND storage_cids.block_number BETWEEN $2 AND $3
+			AND storage_cids.node_type IN (2, 3)
+			AND storage_cids.header_id = (SELECT canonical_header_hash(storage_cids.block_number))
+			ORDER BY storage_cids.block_number`
+	blockNumbers := make([]int64, 0)
+	leafKey := crypto.Keccak256Hash(address.Bytes())
+	return blockNumbers, selectWithTimeout(ecr.QueryTimeout, ecr.db, &blockNumbers, pgStr, leafKey.Hex(), from, to)
+}
+
+// RetrieveContractDeploymentBlock returns the number of the earliest canonical block at which the account at the provided
+// address held non-empty code, as recorded by its state leaf; it returns sql.ErrNoRows if the account never held code
+func (ecr *CIDRetriever) RetrieveContractDeploymentBlock(address common.Address) (int64, error) {
+	log.Debug("retrieving contract deployment block for address ", address.Hex())
+	pgStr := `SELECT state_accounts.block_number
+			FROM eth.state_accounts
+				INNER JOIN eth.state_cids ON (
+					state_accounts.header_id = state_cids.header_id
+					AND state_accounts.state_path = state_cids.state_path
+					AND state_accounts.block_number = state_cids.block_number
+				)
+			WHERE state_cids.state_leaf_key = $1
+			AND state_accounts.code_hash <> $2
+			AND state_accounts.header_id = (SELECT canonical_header_hash(state_accounts.block_number))
+			ORDER BY state_accounts.block_number
+			LIMIT 1`
+	var blockNumber int64
+	leafKey := crypto.Keccak256Hash(address.Bytes())
+	return blockNumber, getWithTimeout(ecr.QueryTimeout, ecr.db, &blockNumber, pgStr, leafKey.Hex(), crypto.Keccak256(nil))
+}
+
+// RetrieveTxHashesByAddress returns the canonical transactions sent from or to the provided address within the provided
+// block range (inclusive), ordered by block number and then index
+// With a limit on the number of transactions (0 = unlimited), it returns an error if more of them match
+func (ecr *CIDRetriever) RetrieveTxHashesByAddress(address common.Address, fromBlock, toBlock int64, limit int) ([]TxResult, error) {
+	log.Debugf("retrieving tx hashes for address %s from %d to %d", address.Hex(), fromBlock, toBlock)
+	pgStr := `SELECT tx_hash, header_id, block_number, index, src, dst
+			FROM eth.transaction_cids
+			WHERE (src = $1 OR dst = $1)
+			AND block_number BETWEEN $2 AND $3
+			AND header_id = (SELECT canonical_header_hash(block_number))
+			ORDER BY block_number, index`
+	args := []interface{}{address.Hex(), fromBlock, toBlock}
+	if limit > 0 {
+		// one more tx than the limit is enough to detect the overflow
+		pgStr += ` LIMIT $4`
+		args = append(args, limit+1)
+	}
+	txs := make([]TxResult, 0)
+	if err := selectWithTimeout(ecr.QueryTimeout, ecr.db, &txs, pgStr, args...); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(txs) > limit {
+		return nil, fmt.Errorf("more than %d transactions were sent from or to %s in the range; narrow the block range", limit, address.Hex())
+	}
+	return txs, nil
+}
+
+// RetrieveRemovedAccountsByBlockHash returns the leaf keys of the accounts removed (e.g. self-destructed)
+// in the block with the provided hash, if it is canonical
+func (ecr *CIDRetriever) RetrieveRemovedAccountsByBlockHash(blockHash common.Hash) ([]string, error) {
+	log.Debug("retrieving removed accounts for block hash ", blockHash.String())
+	pgStr := `SELECT state_cids.state_leaf_key
+			FROM eth.state_cids
+			WHERE state_cids.header_id = $1
+			AND state_cids.node_type = 3
+			AND state_cids.state_leaf_key <> $2
+			AND state_cids.header_id = (SELECT canonical_header_hash(state_cids.block_number))
+			ORDER BY state_cids.state_leaf_key`
+	leafKeys := make([]string, 0)
+	// removed intermediate nodes are indexed with an empty leaf key
+	return leafKeys, selectWithTimeout(ecr.QueryTimeout, ecr.db, &leafKeys, pgStr, blockHash.String(), common.Hash{}.String())
+}
+
+// RetrieveDistinctTopic0ForAddress returns the distinct topic0 values (event signatures) of the canonical logs
+// emitted by the provided contract address within the provided block range (inclusive)
+func (ecr *CIDRetriever) RetrieveDistinctTopic0ForAddress(address common.Address, from, to int64) ([]string, error) {
+	log.Debugf("retrieving distinct topic0s for address %s from %d to %d", address.Hex(), from, to)
+	pgStr := `SELECT DISTINCT log_cids.topic0
+			FROM eth.log_cids
+			WHERE log_cids.address = $1
+			AND log_cids.block_number BETWEEN $2 AND $3
+			AND log_cids.topic0 <> ''
+			AND log_cids.header_id = (SELECT canonical_header_hash(log_cids.block_number))
+			ORDER BY log_cids.topic0`
+	topics := make([]string, 0)
+	return topics, selectWithTimeout(ecr.QueryTimeout, ecr.db, &topics, pgStr, address.String(), from, to)
+}
+
+// RetrieveLogBlockRangeForAddress returns the range spanning the first and last canonical blocks at which the
+// provided contract address emitted logs, or nil if it has not emitted any
+func (ecr *CIDRetriever) RetrieveLogBlockRangeForAddress(address common.Address) (*BlockRange, error) {
+	log.Debug("retrieving log block range for address ", address.Hex())
+	pgStr := `SELECT MIN(log_cids.block_number) AS from_block, MAX(log_cids.block_number) AS to_block
+			FROM eth.log_cids
+			WHERE log_cids.address = $1
+			AND log_cids.header_id = (SELECT canonical_header_hash(log_cids.block_number))`
+	var res struct {
+		From sql.NullInt64 `db:"from_block"`
+		To   sql.NullInt64 `db:"to_block"`
+	}
+	if err := getWithTimeout(ecr.QueryTimeout, ecr.db, &res, pgStr, address.String()); err != nil {
+		return nil, err
+	}
+	if !res.From.Valid || !res.To.Valid {
+		return nil, nil
+	}
+	return &BlockRange{From: res.From.Int64, To: res.To.Int64}, nil
+}
+
+// RetrieveActiveAddressCountByBlockNumber returns the number of distinct addresses that sent or received
+// the canonical transactions at the provided block number
+func (ecr *CIDRetriever) RetrieveActiveAddressCountByBlockNumber(blockNumber int64) (int64, error) {
+	log.Debug("retrieving active address count for block ", blockNumber)
+	// contract creations are indexed with an empty dst
+	pgStr := `SELECT COUNT(*)
+			FROM (
+				SELECT src AS address
+				FROM eth.transaction_cids
+				WHERE block_number = $1
+				AND header_id = (SELECT canonical_header_hash($1))
+				UNION
+				SELECT dst AS address
+				FROM eth.transaction_cids
+				WHERE block_number = $1
+				AND header_id = (SELECT canonical_header_hash($1))
+			) AS addresses
+			WHERE address <> ''`
+	var count int64
+	return count, getWithTimeout(ecr.QueryTimeout, ecr.db, &count, pgStr, blockNumber)
+}
+
+// RetrieveContractDeploymentCountByBlockNumber returns the number of contracts created by the canonical
+// transactions at the provided block number
+func (ecr *CIDRetriever) RetrieveContractDeploymentCountByBlockNumber(blockNumber int64) (int64, error) {
+	log.Debug("retrieving contract deployment count for block ", blockNumber)
+	// receipts of txs which are not contract creations are indexed with an empty contract
+	pgStr := `SELECT COUNT(*)
+			FROM eth.receipt_cids
+			WHERE block_number = $1
+			AND header_id = (SELECT canonical_header_hash($1))
+			AND contract <> ''`
+	var count int64
+	return count, getWithTimeout(ecr.QueryTimeout, ecr.db, &count, pgStr, blockNumber)
+}
+
+// RetrieveLogCountByBlockHash returns the number of logs emitted by the transactions of the block with the provided hash,
+// which is zero if the block is not canonical
+func (ecr *CIDRetriever) RetrieveLogCountByBlockHash(hash common.Hash) (int64, error) {
+	log.Debug("retrieving log count for block hash ", hash.Hex())
+	pgStr := `SELECT COUNT(*)
+			FROM eth.log_cids
+				INNER JOIN eth.header_cids ON (
+					log_cids.header_id = header_cids.block_hash
+					AND log_cids.block_number = header_cids.block_number
+				)
+			WHERE header_cids.block_hash = $1
+			AND header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number))`
+	var count int64
+	return count, getWithTimeout(ecr.QueryTimeout, ecr.db, &count, pgStr, hash.Hex())
+}
+
+// RetrieveGasUsedRatioInRange returns the ratio of the gas used to the gas limit of each canonical block within the
+// provided block range (inclusive), ordered by block number; the ratio of a block without a gas limit is 0
+func (ecr *CIDRetriever) RetrieveGasUsedRatioInRange(from, to int64) ([]GasUsedRatio, error) {
+	log.Debugf("retrieving gas used ratios from %d to %d", from, to)
+	// gas used and gas limit are not indexed, so they are read from the header IPLDs
+	pgStr := `SELECT header_cids.block_number, data
+			FROM eth.header_cids
+				INNER JOIN public.blocks ON (
+					header_cids.mh_key = blocks.key
+					AND header_cids.block_number = blocks.block_number
+				)
+			WHERE header_cids.block_number BETWEEN $1 AND $2
+			AND header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number))
+			ORDER BY header_cids.block_number`
+	var rows []struct {
+		BlockNumber int64  `db:"block_number"`
+		Data        []byte `db:"data"`
+	}
+	if err := selectWithTimeout(ecr.QueryTimeout, ecr.db, &rows, pgStr, from, to); err != nil {
+		return nil, err
+	}
+
+	ratios := make([]GasUsedRatio, len(rows))
+	for i, row := range rows {
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(row.Data, header); err != nil {
+			return nil, err
+		}
+		ratios[i].BlockNumber = row.BlockNumber
+		if header.GasLimit > 0 {
+			ratios[i].Ratio = float64(header.GasUsed) / float64(header.GasLimit)
+		}
+	}
+	return ratios, nil
+}
+
+// RetrieveAverageGasPriceInRange returns the average and the provided percentile (0-100) of the effective gas prices
+// paid by the canonical transactions within the provided block range (inclusive)
+func (ecr *CIDRetriever) RetrieveAverageGasPriceInRange(from, to int64, percentile int) (*GasPriceStats, error) {
+	log.Debugf("retrieving gas price stats from %d to %d", from, to)
+	if percentile < 0 || percentile > 100 {
+		return nil, fmt.Errorf("invalid gas price percentile %d, must be between 0 and 100", percentile)
+	}
+	// gas prices are not indexed, so they are read from the tx IPLDs along with the base fee from the header IPLDs
+	pgStr := `SELECT header_cids.block_number, header_blocks.data AS header_data, tx_blocks.data AS tx_data
+			FROM eth.transaction_cids
+				INNER JOIN eth.header_cids ON (
+					transaction_cids.header_id = header_cids.block_hash
+					AND transaction_cids.block_number = header_cids.block_number
+				)
+				INNER JOIN public.blocks AS header_blocks ON (
+					header_cids.mh_key = header_blocks.key
+					AND header_cids.block_number = header_blocks.block_number
+				)
+				INNER JOIN public.blocks AS tx_blocks ON (
+					transaction_cids.mh_key = tx_blocks.key
+					AND transaction_cids.block_number = tx_blocks.block_number
+				)
+			WHERE header_cids.block_number BETWEEN $1 AND $2
+			AND header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number))
+			ORDER BY header_cids.block_number, transaction_cids.index`
+	var rows []struct {
+		BlockNumber int64  `db:"block_number"`
+		HeaderData  []byte `db:"header_data"`
+		TxData      []byte `db:"tx_data"`
+	}
+	if err := selectWithTimeout(ecr.QueryTimeout, ecr.db, &rows, pgStr, from, to); err != nil {
+		return nil, err
+	}
+
+	stats := &GasPriceStats{Count: len(rows)}
+	if len(rows) == 0 {
+		return stats, nil
+	}
+	prices := make([]*big.Int, len(rows))
+	sum := new(big.Int)
+	var header *types.Header
+	for i, row := range rows {
+		if header == nil || header.Number.Int64() != row.BlockNumber {
+			header = new(types.Header)
+			if err := rlp.DecodeBytes(row.HeaderData, header); err != nil {
+				return nil, err
+			}
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(row.TxData); err != nil {
+			return nil, err
+		}
+		prices[i] = effectiveGasPrice(tx, header.BaseFee)
+		sum.Add(sum, prices[i])
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	stats.Average = sum.Div(sum, big.NewInt(int64(len(prices))))
+	stats.Percentile = prices[(len(prices)-1)*percentile/100]
+	return stats, nil
+}
+
+// effectiveGasPrice returns the gas price paid by the tx in a block with the provided base fee
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	return math.BigMin(new(big.Int).Add(tx.GasTipCap(), baseFee), tx.GasFeeCap())
 }
 
 // RetrieveBlockByHash returns all of the CIDs needed to compose an entire block, for a given block hash
@@ -620,23 +1340,23 @@ func (ecr *CIDRetriever) RetrieveBlockByNumber(blockNumber int64) (models.Header
 		}
 	}()
 
-	var headerCID []models.HeaderModel
-	headerCID, err = ecr.RetrieveHeaderCIDs(tx, blockNumber)
+	var headerCID models.HeaderModel
+	headerCID, err = ecr.RetrieveCanonicalHeaderCIDByNumber(tx, blockNumber)
+	if err == sql.ErrNoRows {
+		return models.HeaderModel{}, nil, nil, nil, fmt.Errorf("header cid retrieval error, no canonical header CID found at block %d", blockNumber)
+	}
 	if err != nil {
 		log.Error("header cid retrieval error")
 		return models.HeaderModel{}, nil, nil, nil, err
 	}
-	if len(headerCID) < 1 {
-		return models.HeaderModel{}, nil, nil, nil, fmt.Errorf("header cid retrieval error, no header CIDs found at block %d", blockNumber)
-	}
 	var uncleCIDs []models.UncleModel
-	uncleCIDs, err = ecr.RetrieveUncleCIDsByHeaderID(tx, headerCID[0].BlockHash)
+	uncleCIDs, err = ecr.RetrieveUncleCIDsByHeaderID(tx, headerCID.BlockHash)
 	if err != nil {
 		log.Error("uncle cid retrieval error")
 		return models.HeaderModel{}, nil, nil, nil, err
 	}
 	var txCIDs []models.TxModel
-	txCIDs, err = ecr.RetrieveTxCIDsByHeaderID(tx, headerCID[0].BlockHash, blockNumber)
+	txCIDs, err = ecr.RetrieveTxCIDsByHeaderID(tx, headerCID.BlockHash, blockNumber)
 	if err != nil {
 		log.Error("tx cid retrieval error")
 		return models.HeaderModel{}, nil, nil, nil, err
@@ -646,11 +1366,11 @@ func (ecr *CIDRetriever) RetrieveBlockByNumber(blockNumber int64) (models.Header
 		txHashes[i] = txCID.TxHash
 	}
 	var rctCIDs []models.ReceiptModel
-	rctCIDs, err = ecr.RetrieveReceiptCIDsByByHeaderIDAndTxIDs(tx, headerCID[0].BlockHash, txHashes, blockNumber)
+	rctCIDs, err = ecr.RetrieveReceiptCIDsByByHeaderIDAndTxIDs(tx, headerCID.BlockHash, txHashes, blockNumber)
 	if err != nil {
 		log.Error("rct cid retrieval error")
 	}
-	return headerCID[0], uncleCIDs, txCIDs, rctCIDs, err
+	return headerCID, uncleCIDs, txCIDs, rctCIDs, err
 }
 
 // RetrieveHeaderCIDByHash returns the header for the given block hash
@@ -660,7 +1380,7 @@ func (ecr *CIDRetriever) RetrieveHeaderCIDByHash(tx *sqlx.Tx, blockHash common.H
 			state_root, uncle_root, tx_root, receipt_root, bloom, timestamp FROM eth.header_cids
 			WHERE block_hash = $1`
 	var headerCID models.HeaderModel
-	return headerCID, tx.Get(&headerCID, pgStr, blockHash.String())
+	return headerCID, getWithTimeout(ecr.QueryTimeout, tx, &headerCID, pgStr, blockHash.String())
 }
 
 // RetrieveTxCIDsByHeaderID retrieves all tx CIDs for the given header id
@@ -672,7 +1392,7 @@ func (ecr *CIDRetriever) RetrieveTxCIDsByHeaderID(tx *sqlx.Tx, headerID string,
 			WHERE header_id = $1 AND block_number = $2
 			ORDER BY index`
 	var txCIDs []models.TxModel
-	return txCIDs, tx.Select(&txCIDs, pgStr, headerID, blockNumber)
+	return txCIDs, selectWithTimeout(ecr.QueryTimeout, tx, &txCIDs, pgStr, headerID, blockNumber)
 }
 
 // RetrieveReceiptCIDsByByHeaderIDAndTxIDs retrieves receipt CIDs by their associated tx IDs for the given header id
@@ -689,20 +1409,55 @@ func (ecr *CIDRetriever) RetrieveReceiptCIDsByByHeaderIDAndTxIDs(tx *sqlx.Tx, he
 			AND transaction_cids.block_number = $3
 			ORDER BY transaction_cids.index`
 	var rctCIDs []models.ReceiptModel
-	return rctCIDs, tx.Select(&rctCIDs, pgStr, headerID, pq.Array(txHashes), blockNumber)
+	return rctCIDs, selectWithTimeout(ecr.QueryTimeout, tx, &rctCIDs, pgStr, headerID, pq.Array(txHashes), blockNumber)
 }
 
 // RetrieveHeaderAndTxCIDsByBlockNumber retrieves header CIDs and their associated tx CIDs by block number
-func (ecr *CIDRetriever) RetrieveHeaderAndTxCIDsByBlockNumber(blockNumber int64) ([]HeaderCIDRecord, error) {
+// The canonical header is ordered first, followed by any non-canonical headers ordered by block hash
+// A limit of 0 returns all of the headers at the block number
+func (ecr *CIDRetriever) RetrieveHeaderAndTxCIDsByBlockNumber(blockNumber int64, limit int) ([]HeaderCIDRecord, error) {
 	log.Debug("retrieving header cids and tx cids for block number ", blockNumber)
 
 	var headerCIDs []HeaderCIDRecord
 
 	// https://github.com/go-gorm/gorm/issues/4083#issuecomment-778883283
 	// Will use join for TransactionCIDs once preload for 1:N is supported.
-	err := ecr.gormDB.Preload("TransactionCIDs", func(tx *gorm.DB) *gorm.DB {
+	query := ecr.gormDB.Preload("TransactionCIDs", func(tx *gorm.DB) *gorm.DB {
 		return tx.Select("cid", "tx_hash", "index", "src", "dst", "header_id", "block_number")
-	}).Joins("IPLD").Find(&headerCIDs, "header_cids.block_number = ?", blockNumber).Error
+	}).Joins("IPLD").Order("header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number)) DESC, header_cids.block_hash")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&headerCIDs, "header_cids.block_number = ?", blockNumber).Error
+
+	if err != nil {
+		log.Error("header cid retrieval error")
+		return nil, err
+	}
+
+	return headerCIDs, nil
+}
+
+// RetrieveHeaderAndTxCIDsInRange retrieves header CIDs and their associated tx CIDs in the block range (inclusive)
+// The headers are ordered by block number and then block hash, starting after the provided cursor if there is one
+// A limit of 0 returns all of the remaining headers in the range
+func (ecr *CIDRetriever) RetrieveHeaderAndTxCIDsInRange(from, to int64, after *HeaderCursor, limit int) ([]HeaderCIDRecord, error) {
+	log.Debugf("retrieving header cids and tx cids from %d to %d", from, to)
+
+	var headerCIDs []HeaderCIDRecord
+
+	// https://github.com/go-gorm/gorm/issues/4083#issuecomment-778883283
+	// Will use join for TransactionCIDs once preload for 1:N is supported.
+	query := ecr.gormDB.Preload("TransactionCIDs", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("cid", "tx_hash", "index", "src", "dst", "header_id", "block_number")
+	}).Joins("IPLD").Where("header_cids.block_number BETWEEN ? AND ?", from, to).Order("header_cids.block_number, header_cids.block_hash")
+	if after != nil {
+		query = query.Where("(header_cids.block_number, header_cids.block_hash) > (?, ?)", after.BlockNumber, after.BlockHash)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&headerCIDs).Error
 
 	if err != nil {
 		log.Error("header cid retrieval error")
@@ -769,3 +1524,17 @@ func (ecr *CIDRetriever) RetrieveTxCIDByHash(txHash string, blockNumber *big.Int
 
 	return txCIDs[0], nil
 }
+
+// RetrieveTxCIDsByHashes returns the canonical txs for the given tx hashes, hashes without a canonical tx are omitted
+func (ecr *CIDRetriever) RetrieveTxCIDsByHashes(txHashes []string) ([]TransactionCIDRecord, error) {
+	log.Debugf("retrieving tx cids for tx hashes %v", txHashes)
+
+	txCIDs := make([]TransactionCIDRecord, 0)
+	err := ecr.gormDB.Joins("IPLD").Order("transaction_cids.block_number, transaction_cids.index").Find(&txCIDs, "tx_hash = ANY(?) AND transaction_cids.header_id = (SELECT canonical_header_hash(transaction_cids.block_number))", pq.Array(txHashes)).Error
+	if err != nil {
+		log.Error("tx retrieval error")
+		return nil, err
+	}
+
+	return txCIDs, nil
+}
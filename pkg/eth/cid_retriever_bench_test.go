@@ -0,0 +1,103 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+// BenchmarkRetrieveFilteredLog compares filtering the logs of a 500 log block on a topic its bloom includes
+// with filtering them on a topic its bloom excludes, which skips the block before the logs are joined
+func BenchmarkRetrieveFilteredLog(b *testing.B) {
+	RegisterTestingT(b)
+	const txCount = 500
+
+	db := shared.SetupDB()
+	defer shared.TearDownDB(db)
+
+	topic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	signer := types.HomesteadSigner{}
+	txs := make(types.Transactions, txCount)
+	rcts := make(types.Receipts, txCount)
+	for i := range txs {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), test_helpers.ContractAddr, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, test_helpers.TestBankKey)
+		Expect(err).ToNot(HaveOccurred())
+		txs[i] = tx
+		rct := types.NewReceipt(nil, false, uint64(i+1)*params.TxGas)
+		rct.TxHash = tx.Hash()
+		rct.GasUsed = params.TxGas
+		rct.Logs = []*types.Log{{
+			Address: test_helpers.ContractAddr,
+			Topics:  []common.Hash{topic},
+			TxHash:  tx.Hash(),
+			TxIndex: uint(i),
+			Index:   uint(i),
+		}}
+		rct.Bloom = types.CreateBloom(types.Receipts{rct})
+		rcts[i] = rct
+	}
+	header := &types.Header{
+		ParentHash: test_helpers.Genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		GasLimit:   txCount * params.TxGas,
+		GasUsed:    txCount * params.TxGas,
+	}
+	block := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+	chainConfig := *params.TestChainConfig
+	indexer := shared.SetupTestStateDiffIndexer(context.Background(), &chainConfig, test_helpers.Genesis.Hash())
+	tx, err := indexer.PushBlock(block, rcts, block.Difficulty())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(tx.Submit(err)).To(Succeed())
+
+	retriever, err := eth.NewCIDRetriever(db)
+	Expect(err).ToNot(HaveOccurred())
+	for _, bm := range []struct {
+		name  string
+		topic common.Hash
+		count int
+	}{
+		{"included", topic, txCount},
+		{"excluded", crypto.Keccak256Hash([]byte("Approval(address,address,uint256)")), 0},
+	} {
+		filter := eth.ReceiptFilter{Topics: [][]string{{bm.topic.Hex()}}}
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dbTx, err := db.Beginx()
+				Expect(err).ToNot(HaveOccurred())
+				logs, err := retriever.RetrieveFilteredLog(dbTx, filter, block.Number().Int64(), nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs).To(HaveLen(bm.count))
+				Expect(dbTx.Rollback()).To(Succeed())
+			}
+		})
+	}
+}
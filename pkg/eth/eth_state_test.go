@@ -19,6 +19,7 @@ package eth_test
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -30,10 +31,14 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/statediff"
 	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/jmoiron/sqlx"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -93,6 +98,17 @@ func init() {
 	}
 }
 
+// mockStorageAPI is a proxy node eth API answering eth_getStorageAt with a fixed value, counting the calls it receives
+type mockStorageAPI struct {
+	value hexutil.Bytes
+	calls int
+}
+
+func (api *mockStorageAPI) GetStorageAt(ctx context.Context, address common.Address, key string, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	api.calls++
+	return api.value, nil
+}
+
 var _ = Describe("eth state reading tests", func() {
 	const chainLength = 5
 	var (
@@ -126,7 +142,7 @@ var _ = Describe("eth state reading tests", func() {
 			},
 		})
 		Expect(err).ToNot(HaveOccurred())
-		api, _ = eth.NewPublicEthAPI(backend, nil, eth.APIConfig{false, false, false, false, shared.DefaultStateDiffTimeout})
+		api, _ = eth.NewPublicEthAPI(backend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
 
 		// make the test blockchain (and state)
 		blocks, receipts, chain = test_helpers.MakeChain(chainLength, test_helpers.Genesis, test_helpers.TestChainGen)
@@ -259,6 +275,83 @@ var _ = Describe("eth state reading tests", func() {
 			expectedRes = hexutil.Bytes(common.Hex2Bytes("0000000000000000000000000000000000000000000000000000000000000000"))
 			Expect(res).To(Equal(expectedRes))
 		})
+
+		It("Uses the precompile gas costs of the fork active at the block", func() {
+			// activate Istanbul, which reduced the gas cost of the bn256Add precompile from 500 to 150 (EIP-1108), at block 3
+			chainConfig := *backend.Config.ChainConfig
+			chainConfig.IstanbulBlock = big.NewInt(3)
+			chainConfig.MuirGlacierBlock = big.NewInt(3)
+			chainConfig.BerlinBlock = nil
+			chainConfig.LondonBlock = nil
+			config := *backend.Config
+			config.ChainConfig = &chainConfig
+			istanbulBackend := *backend
+			istanbulBackend.Config = &config
+			istanbulAPI, err := eth.NewPublicEthAPI(&istanbulBackend, nil, eth.APIConfig{StateDiffTimeout: shared.DefaultStateDiffTimeout})
+			Expect(err).ToNot(HaveOccurred())
+
+			// adding the point at infinity to itself is a valid input, the call has only enough gas for the Istanbul cost
+			bn256Add := common.BytesToAddress([]byte{6})
+			input := hexutil.Bytes(make([]byte, 128))
+			gas := hexutil.Uint64(params.TxGas + uint64(len(input))*params.TxDataZeroGas + params.Bn256AddGasIstanbul)
+			callArgs := eth.CallArgs{
+				To:   &bn256Add,
+				Gas:  &gas,
+				Data: &input,
+			}
+
+			_, err = istanbulAPI.Call(context.Background(), callArgs, rpc.BlockNumberOrHashWithNumber(2), nil)
+			Expect(err).To(MatchError(vm.ErrOutOfGas))
+
+			res, err := istanbulAPI.Call(context.Background(), callArgs, rpc.BlockNumberOrHashWithNumber(3), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(hexutil.Bytes(make([]byte, 64))))
+		})
+	})
+
+	Describe("eth_createAccessList", func() {
+		It("Creates the access list of the storage slots a contract call touches", func() {
+			data, err := parsedABI.Pack("Put", big.NewInt(7))
+			Expect(err).ToNot(HaveOccurred())
+			bdata := hexutil.Bytes(data)
+			callArgs := eth.CallArgs{
+				From: &test_helpers.TestBankAddress,
+				To:   &test_helpers.ContractAddr,
+				Data: &bdata,
+			}
+			blockNrOrHash := rpc.BlockNumberOrHashWithNumber(3)
+			res, err := api.CreateAccessList(context.Background(), callArgs, &blockNrOrHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Error).To(BeEmpty())
+			Expect(uint64(res.GasUsed)).To(BeNumerically(">", params.TxGas))
+			Expect(*res.Accesslist).To(Equal(types.AccessList{
+				{
+					Address:     test_helpers.ContractAddr,
+					StorageKeys: []common.Hash{common.HexToHash(test_helpers.IndexOne)},
+				},
+			}))
+		})
+
+		It("Returns the access list of a reverted call along with the revert reason", func() {
+			data, err := parsedABI.Pack("close")
+			Expect(err).ToNot(HaveOccurred())
+			bdata := hexutil.Bytes(data)
+			callArgs := eth.CallArgs{
+				From: &test_helpers.TestBankAddress,
+				To:   &test_helpers.ContractAddr,
+				Data: &bdata,
+			}
+			blockNrOrHash := rpc.BlockNumberOrHashWithNumber(3)
+			res, err := api.CreateAccessList(context.Background(), callArgs, &blockNrOrHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Error).To(Equal("execution reverted: Only owner can call this function."))
+			Expect(*res.Accesslist).To(Equal(types.AccessList{
+				{
+					Address:     test_helpers.ContractAddr,
+					StorageKeys: []common.Hash{common.HexToHash(test_helpers.IndexZero)},
+				},
+			}))
+		})
 	})
 
 	var (
@@ -509,6 +602,128 @@ var _ = Describe("eth state reading tests", func() {
 		})
 	})
 
+	Describe("eth_getProof", func() {
+		// proofDB returns a db of the provided proof nodes keyed by their hash
+		proofDB := func(proof []string) *memorydb.Database {
+			nodes := memorydb.New()
+			for _, node := range proof {
+				nodeBytes, err := hexutil.Decode(node)
+				Expect(err).ToNot(HaveOccurred())
+				err = nodes.Put(crypto.Keccak256(nodeBytes), nodeBytes)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			return nodes
+		}
+
+		It("Retrieves account and storage proofs which hash-link back to the state root", func() {
+			// the slot holds a non-zero value at block 4
+			block := blocks[3]
+			slotKey := common.HexToHash(test_helpers.IndexOne)
+			res, err := api.GetProof(ctx, test_helpers.ContractAddr, []string{slotKey.Hex()}, rpc.BlockNumberOrHashWithHash(block.Hash(), true))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.AccountProof).ToNot(BeEmpty())
+
+			accountRLP, err := trie.VerifyProof(block.Root(), crypto.Keccak256(test_helpers.ContractAddr.Bytes()), proofDB(res.AccountProof))
+			Expect(err).ToNot(HaveOccurred())
+			var account types.StateAccount
+			err = rlp.DecodeBytes(accountRLP, &account)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(account.Root).To(Equal(res.StorageHash))
+			Expect(common.BytesToHash(account.CodeHash)).To(Equal(res.CodeHash))
+			Expect(hexutil.Uint64(account.Nonce)).To(Equal(res.Nonce))
+			Expect(account.Balance.Cmp(res.Balance.ToInt())).To(Equal(0))
+
+			Expect(len(res.StorageProof)).To(Equal(1))
+			storageProof := res.StorageProof[0]
+			Expect(storageProof.Proof).ToNot(BeEmpty())
+			valueRLP, err := trie.VerifyProof(res.StorageHash, crypto.Keccak256(slotKey.Bytes()), proofDB(storageProof.Proof))
+			Expect(err).ToNot(HaveOccurred())
+			_, value, _, err := rlp.Split(valueRLP)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(new(big.Int).SetBytes(value).Cmp(storageProof.Value.ToInt())).To(Equal(0))
+			Expect(storageProof.Value.ToInt().Int64()).To(Equal(int64(9)))
+		})
+
+		It("Retrieves empty proofs for an account which does not exist", func() {
+			slotKey := common.HexToHash(test_helpers.IndexOne).Hex()
+			res, err := api.GetProof(ctx, randomAddr, []string{slotKey}, rpc.BlockNumberOrHashWithNumber(chainLength))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.AccountProof).To(BeEmpty())
+			Expect(res.StorageHash).To(Equal(types.EmptyRootHash))
+			Expect(res.CodeHash).To(Equal(crypto.Keccak256Hash(nil)))
+			Expect(len(res.StorageProof)).To(Equal(1))
+			Expect(res.StorageProof[0].Key).To(Equal(slotKey))
+			Expect(res.StorageProof[0].Proof).To(BeEmpty())
+		})
+	})
+
+	Describe("eth_getAccountState", func() {
+		includeCode := true
+		slots := []string{test_helpers.IndexOne, randomHash.Hex()}
+
+		It("Retrieves the same account state as the individual calls at each block", func() {
+			for number := int64(0); number <= chainLength; number++ {
+				for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+					rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)),
+					rpc.BlockNumberOrHashWithHash(blocks[number].Hash(), true),
+				} {
+					for _, address := range []common.Address{test_helpers.ContractAddr, test_helpers.Account1Addr, randomAddr} {
+						res, err := api.GetAccountState(ctx, address, slots, blockNrOrHash, &includeCode)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(res.Address).To(Equal(address))
+
+						bal, err := api.GetBalance(ctx, address, blockNrOrHash)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(res.Balance.ToInt().Cmp(bal.ToInt())).To(Equal(0))
+
+						// eth_getTransactionCount fails rather than returning 0 for an account that does not exist
+						nonce, err := api.GetTransactionCount(ctx, address, blockNrOrHash)
+						if err == sql.ErrNoRows {
+							Expect(res.Nonce).To(BeZero())
+						} else {
+							Expect(err).ToNot(HaveOccurred())
+							Expect(res.Nonce).To(Equal(*nonce))
+						}
+
+						code, err := api.GetCode(ctx, address, blockNrOrHash)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(bytes.Equal(res.Code, code)).To(BeTrue())
+						Expect(res.CodeHash).To(Equal(crypto.Keccak256Hash(code)))
+
+						Expect(res.Storage).To(HaveLen(len(slots)))
+						for i, slot := range slots {
+							value, err := api.GetStorageAt(ctx, address, slot, blockNrOrHash)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(res.Storage[i].Key).To(Equal(slot))
+							Expect(res.Storage[i].Value).To(Equal(value))
+						}
+					}
+				}
+			}
+		})
+		It("Omits the code unless it is requested", func() {
+			res, err := api.GetAccountState(ctx, test_helpers.ContractAddr, nil, rpc.BlockNumberOrHashWithNumber(3), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Code).To(BeNil())
+			Expect(res.CodeHash).To(Equal(crypto.Keccak256Hash(test_helpers.ContractCode)))
+			Expect(res.Storage).To(BeEmpty())
+		})
+		It("Rejects a request for more storage slots than the limit", func() {
+			tooMany := make([]string, 1025)
+			for i := range tooMany {
+				tooMany[i] = common.BigToHash(big.NewInt(int64(i))).Hex()
+			}
+			_, err := api.GetAccountState(ctx, test_helpers.ContractAddr, tooMany, rpc.BlockNumberOrHashWithNumber(3), nil)
+			Expect(err).To(MatchError("too many storage slots requested: 1025, the maximum is 1024"))
+		})
+		It("Throws an error for a non-existing block hash or number", func() {
+			_, err := api.GetAccountState(ctx, test_helpers.ContractAddr, slots, rpc.BlockNumberOrHashWithHash(randomHash, true), nil)
+			Expect(err).To(MatchError("header for hash not found"))
+			_, err = api.GetAccountState(ctx, test_helpers.ContractAddr, slots, rpc.BlockNumberOrHashWithNumber(chainLength+1), nil)
+			Expect(err).To(MatchError("header not found"))
+		})
+	})
+
 	Describe("eth_getStorageAt", func() {
 		It("Returns empty slice if it tries to access a contract which does not exist", func() {
 			storage, err := api.GetStorageAt(ctx, test_helpers.ContractAddr, test_helpers.ContractSlotKeyHash.Hex(), rpc.BlockNumberOrHashWithNumber(0))
@@ -524,6 +739,50 @@ var _ = Describe("eth state reading tests", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(storage).To(Equal(hexutil.Bytes(eth.EmptyNodeValue)))
 		})
+		Describe("with proxyOnError", func() {
+			var (
+				proxyAPI    *mockStorageAPI
+				proxyServer *rpc.Server
+				proxiedAPI  *eth.PublicEthAPI
+			)
+			BeforeEach(func() {
+				proxyAPI = &mockStorageAPI{value: common.HexToHash("0x2a").Bytes()}
+				proxyServer = rpc.NewServer()
+				err := proxyServer.RegisterName(eth.APIName, proxyAPI)
+				Expect(err).ToNot(HaveOccurred())
+				proxiedAPI, err = eth.NewPublicEthAPI(backend, rpc.DialInProc(proxyServer), eth.APIConfig{
+					ProxyOnError:     true,
+					StateDiffTimeout: shared.DefaultStateDiffTimeout,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				proxyServer.Stop()
+			})
+			It("Forwards to the proxy node for a block whose header is not indexed", func() {
+				storage, err := proxiedAPI.GetStorageAt(ctx, test_helpers.ContractAddr, test_helpers.IndexOne, rpc.BlockNumberOrHashWithHash(randomHash, true))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(storage).To(Equal(proxyAPI.value))
+				Expect(proxyAPI.calls).To(Equal(1))
+
+				storage, err = proxiedAPI.GetStorageAt(ctx, test_helpers.ContractAddr, test_helpers.IndexOne, rpc.BlockNumberOrHashWithNumber(chainLength+1))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(storage).To(Equal(proxyAPI.value))
+				Expect(proxyAPI.calls).To(Equal(2))
+			})
+			It("Returns zero without forwarding for a slot that does not exist at an indexed block", func() {
+				storage, err := proxiedAPI.GetStorageAt(ctx, test_helpers.ContractAddr, randomHash.Hex(), rpc.BlockNumberOrHashWithNumber(2))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(storage).To(Equal(hexutil.Bytes(eth.EmptyNodeValue)))
+				Expect(proxyAPI.calls).To(Equal(0))
+			})
+			It("Returns the indexed value without forwarding", func() {
+				storage, err := proxiedAPI.GetStorageAt(ctx, test_helpers.ContractAddr, test_helpers.IndexOne, rpc.BlockNumberOrHashWithNumber(3))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(storage).To(Equal(hexutil.Bytes(common.HexToHash("0x03").Bytes())))
+				Expect(proxyAPI.calls).To(Equal(0))
+			})
+		})
 		It("Retrieves the storage value at the provided contract address and storage leaf key at the block with the provided hash or number", func() {
 			// After deployment
 			val, err := api.GetStorageAt(ctx, test_helpers.ContractAddr, test_helpers.IndexOne, rpc.BlockNumberOrHashWithNumber(2))
@@ -22,6 +22,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/statediff/indexer/ipld"
 	"github.com/ethereum/go-ethereum/statediff/indexer/models"
@@ -33,6 +34,7 @@ import (
 // Filterer interface for substituing mocks in tests
 type Filterer interface {
 	Filter(filter SubscriptionSettings, payload ConvertedPayload) (*IPLDs, error)
+	FilterLogs(filter ReceiptFilter, payload ConvertedPayload) []*types.Log
 }
 
 // ResponseFilterer satisfies the ResponseFilterer interface for ethereum
@@ -123,7 +125,8 @@ func (s *ResponseFilterer) filterTransactions(trxFilter TxFilter, response *IPLD
 		response.Transactions = make([]models.IPLDModel, 0, trxLen)
 		for i, trx := range payload.Block.Body().Transactions {
 			// TODO: check if want corresponding receipt and if we do we must include this transaction
-			if checkTransactionAddrs(trxFilter.Src, trxFilter.Dst, payload.TxMetaData[i].Src, payload.TxMetaData[i].Dst) {
+			if checkTransactionAddrs(trxFilter.Src, trxFilter.Dst, payload.TxMetaData[i].Src, payload.TxMetaData[i].Dst) &&
+				checkTransactionType(trxFilter.Types, trx.Type()) {
 				trxBuffer := new(bytes.Buffer)
 				if err := trx.EncodeRLP(trxBuffer); err != nil {
 					return nil, err
@@ -163,6 +166,20 @@ func checkTransactionAddrs(wantedSrc, wantedDst []string, actualSrc, actualDst s
 	return false
 }
 
+// checkTransactionType returns true if the transaction type is one of the wanted types
+func checkTransactionType(wantedTypes []uint8, actualType uint8) bool {
+	// If we aren't filtering for any types, every transaction is a go
+	if len(wantedTypes) == 0 {
+		return true
+	}
+	for _, wantedType := range wantedTypes {
+		if wantedType == actualType {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *ResponseFilterer) filerReceipts(receiptFilter ReceiptFilter, response *IPLDs, payload ConvertedPayload, trxHashes []common.Hash) error {
 	if !receiptFilter.Off {
 		response.Receipts = make([]models.IPLDModel, 0, len(payload.Receipts))
@@ -227,6 +244,83 @@ func checkReceipts(rct *types.Receipt, wantedTopics, actualTopics [][]string, wa
 	return false
 }
 
+// NewReceiptFilter converts log filter criteria into a ReceiptFilter; topics past the fourth position are dropped
+func NewReceiptFilter(crit filters.FilterCriteria) ReceiptFilter {
+	addrStrs := make([]string, len(crit.Addresses))
+	for i, addr := range crit.Addresses {
+		addrStrs[i] = addr.String()
+	}
+
+	topics := crit.Topics
+	if len(topics) > 4 {
+		// don't allow more than 4 topics
+		topics = topics[:4]
+	}
+	topicStrSets := make([][]string, len(topics))
+	for i, topicSet := range topics {
+		for _, topic := range topicSet {
+			topicStrSets[i] = append(topicStrSets[i], topic.String())
+		}
+	}
+	return ReceiptFilter{
+		LogAddresses: addrStrs,
+		Topics:       topicStrSets,
+	}
+}
+
+// FilterLogs returns the logs in the payload which match the address and topic filter
+// The returned logs are copies carrying their block and transaction context, as they are returned by eth_getLogs
+func (s *ResponseFilterer) FilterLogs(filter ReceiptFilter, payload ConvertedPayload) []*types.Log {
+	if filter.Off {
+		return nil
+	}
+	blockHash := payload.Block.Hash()
+	txs := payload.Block.Transactions()
+	var logs []*types.Log
+	var logIndex uint
+	for txIndex, receipt := range payload.Receipts {
+		for _, l := range receipt.Logs {
+			if checkLog(l, filter.LogAddresses, filter.Topics) {
+				matched := *l
+				matched.BlockNumber = payload.Block.NumberU64()
+				matched.BlockHash = blockHash
+				matched.TxIndex = uint(txIndex)
+				matched.Index = logIndex
+				if txIndex < len(txs) {
+					matched.TxHash = txs[txIndex].Hash()
+				}
+				logs = append(logs, &matched)
+			}
+			logIndex++
+		}
+	}
+	return logs
+}
+
+// checkLog returns true if the log was emitted by one of the wanted addresses and conforms to the wantedTopics filter
+// An empty address list matches logs from any address
+func checkLog(l *types.Log, wantedAddresses []string, wantedTopics [][]string) bool {
+	if len(wantedAddresses) > 0 {
+		actualAddr := l.Address.String()
+		var found bool
+		for _, wantedAddr := range wantedAddresses {
+			if wantedAddr == actualAddr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	// topics is always length 4
+	topics := make([][]string, 4)
+	for idx, t := range l.Topics {
+		topics[idx] = []string{t.String()}
+	}
+	return filterMatch(wantedTopics, topics)
+}
+
 // filterMatch returns true if the actualTopics conform to the wantedTopics filter
 func filterMatch(wantedTopics, actualTopics [][]string) bool {
 	// actualTopics should always be length 4, but the members can be nil slices
@@ -205,4 +205,36 @@ var _ = Describe("Filterer", func() {
 			Expect(len(iplds8.Receipts)).To(Equal(0))
 		})
 	})
+
+	Describe("FilterLogs", func() {
+		BeforeEach(func() {
+			filterer = eth.NewResponseFilterer()
+		})
+
+		It("Returns only the logs matching the addresses and topics", func() {
+			logs := filterer.FilterLogs(eth.ReceiptFilter{
+				LogAddresses: []string{test_helpers.AnotherAddress1.String()},
+			}, test_helpers.MockConvertedPayload)
+			Expect(len(logs)).To(Equal(3))
+			for i, l := range logs {
+				Expect(l.Address).To(Equal(test_helpers.AnotherAddress1))
+				Expect(l.Index).To(Equal(uint(2 + i)))
+				Expect(l.TxHash).To(Equal(test_helpers.MockTransactions[2].Hash()))
+				Expect(l.BlockHash).To(Equal(test_helpers.MockBlock.Hash()))
+			}
+
+			// a log with fewer topics than the filter has positions does not match
+			logs = filterer.FilterLogs(eth.ReceiptFilter{
+				Topics: [][]string{nil, nil, {test_helpers.MockLog4.Topics[2].String()}},
+			}, test_helpers.MockConvertedPayload)
+			Expect(len(logs)).To(Equal(1))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog4.Topics))
+
+			logs = filterer.FilterLogs(eth.ReceiptFilter{}, test_helpers.MockConvertedPayload)
+			Expect(len(logs)).To(Equal(6))
+
+			logs = filterer.FilterLogs(eth.ReceiptFilter{Off: true}, test_helpers.MockConvertedPayload)
+			Expect(logs).To(BeEmpty())
+		})
+	})
 })
@@ -0,0 +1,139 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+// HeaderCache is an LRU cache of decoded headers keyed by block hash
+// A header never changes for a given hash, so entries remain valid across reorgs. Canonical headers are also
+// indexed by number, but only once they are finalizedDepth blocks below the highest header seen, so that a reorg
+// is not expected to replace them; a nil HeaderCache caches nothing
+type HeaderCache struct {
+	headers        *lru.Cache
+	numbers        *lru.Cache
+	finalizedDepth uint64
+	head           uint64
+	hits           uint64
+	misses         uint64
+}
+
+// HeaderCacheStats holds the lookup counters of a HeaderCache
+type HeaderCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// NewHeaderCache creates a new HeaderCache holding up to size headers, indexing the canonical ones by number
+// once they are finalizedDepth blocks deep; a size of 0 disables caching, and a depth of 0 uses the default
+func NewHeaderCache(size int, finalizedDepth uint64) (*HeaderCache, error) {
+	if finalizedDepth == 0 {
+		finalizedDepth = shared.DefaultHeaderCacheFinalizedDepth
+	}
+	c := &HeaderCache{finalizedDepth: finalizedDepth}
+	if size > 0 {
+		headers, err := lru.New(size)
+		if err != nil {
+			return nil, err
+		}
+		numbers, err := lru.New(size)
+		if err != nil {
+			return nil, err
+		}
+		c.headers = headers
+		c.numbers = numbers
+	}
+	return c, nil
+}
+
+// Get returns a copy of the cached header for the provided hash
+func (c *HeaderCache) Get(hash common.Hash) (*types.Header, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if c.headers != nil {
+		if header, ok := c.headers.Get(hash); ok {
+			atomic.AddUint64(&c.hits, 1)
+			return types.CopyHeader(header.(*types.Header)), true
+		}
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil, false
+}
+
+// GetCanonical returns a copy of the cached canonical header for the provided number, if it is finalized
+func (c *HeaderCache) GetCanonical(number uint64) (*types.Header, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if c.numbers != nil {
+		if hash, ok := c.numbers.Get(number); ok {
+			return c.Get(hash.(common.Hash))
+		}
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil, false
+}
+
+// Add caches a copy of the provided header under its hash
+func (c *HeaderCache) Add(header *types.Header) {
+	if c == nil || c.headers == nil {
+		return
+	}
+	c.headers.Add(header.Hash(), types.CopyHeader(header))
+	c.observeHead(header.Number.Uint64())
+}
+
+// AddCanonical caches a copy of the provided canonical header under its hash, and under its number if it is finalized
+func (c *HeaderCache) AddCanonical(header *types.Header) {
+	if c == nil || c.headers == nil {
+		return
+	}
+	c.Add(header)
+	number := header.Number.Uint64()
+	if number+c.finalizedDepth <= atomic.LoadUint64(&c.head) {
+		c.numbers.Add(number, header.Hash())
+	}
+}
+
+// observeHead raises the highest header number seen to the provided number
+func (c *HeaderCache) observeHead(number uint64) {
+	for {
+		head := atomic.LoadUint64(&c.head)
+		if number <= head || atomic.CompareAndSwapUint64(&c.head, head, number) {
+			return
+		}
+	}
+}
+
+// Stats returns the lookup counters of the cache
+func (c *HeaderCache) Stats() HeaderCacheStats {
+	if c == nil {
+		return HeaderCacheStats{}
+	}
+	return HeaderCacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
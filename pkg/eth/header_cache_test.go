@@ -0,0 +1,109 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+)
+
+var _ = Describe("HeaderCache", func() {
+	It("Returns copies of the cached headers and counts hits and misses", func() {
+		cache, err := eth.NewHeaderCache(1, 0)
+		Expect(err).ToNot(HaveOccurred())
+		header := test_helpers.MockBlock.Header()
+
+		_, ok := cache.Get(header.Hash())
+		Expect(ok).To(BeFalse())
+
+		cache.Add(header)
+		cached, ok := cache.Get(header.Hash())
+		Expect(ok).To(BeTrue())
+		Expect(cached.Hash()).To(Equal(header.Hash()))
+
+		// mutating the returned header does not affect the cached entry
+		cached.Number = big.NewInt(0)
+		cached, ok = cache.Get(header.Hash())
+		Expect(ok).To(BeTrue())
+		Expect(cached.Hash()).To(Equal(header.Hash()))
+
+		// the least recently used header is evicted
+		cache.Add(test_helpers.MockChild.Header())
+		_, ok = cache.Get(header.Hash())
+		Expect(ok).To(BeFalse())
+
+		Expect(cache.Stats()).To(Equal(eth.HeaderCacheStats{Hits: 2, Misses: 2}))
+	})
+
+	It("Does not cache headers when disabled", func() {
+		cache, err := eth.NewHeaderCache(0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		header := test_helpers.MockBlock.Header()
+
+		cache.Add(header)
+		_, ok := cache.Get(header.Hash())
+		Expect(ok).To(BeFalse())
+		Expect(cache.Stats()).To(Equal(eth.HeaderCacheStats{Misses: 1}))
+	})
+
+	It("Serves canonical headers by number once they are finalized", func() {
+		cache, err := eth.NewHeaderCache(8, 2)
+		Expect(err).ToNot(HaveOccurred())
+		newHeader := func(number int64, extra string) *types.Header {
+			return &types.Header{Number: big.NewInt(number), Extra: []byte(extra)}
+		}
+
+		canonical := newHeader(1, "canonical")
+		cache.AddCanonical(canonical)
+		_, ok := cache.GetCanonical(1)
+		Expect(ok).To(BeFalse())
+
+		// a header seen by hash raises the head, but is not indexed by number
+		cache.Add(newHeader(3, "sibling"))
+		_, ok = cache.GetCanonical(3)
+		Expect(ok).To(BeFalse())
+
+		cache.AddCanonical(canonical)
+		cached, ok := cache.GetCanonical(1)
+		Expect(ok).To(BeTrue())
+		Expect(cached.Hash()).To(Equal(canonical.Hash()))
+
+		// the header is still served by hash
+		cached, ok = cache.Get(canonical.Hash())
+		Expect(ok).To(BeTrue())
+		Expect(cached.Hash()).To(Equal(canonical.Hash()))
+	})
+
+	It("Caches nothing when nil", func() {
+		var cache *eth.HeaderCache
+		header := test_helpers.MockBlock.Header()
+
+		cache.Add(header)
+		cache.AddCanonical(header)
+		_, ok := cache.Get(header.Hash())
+		Expect(ok).To(BeFalse())
+		_, ok = cache.GetCanonical(header.Number.Uint64())
+		Expect(ok).To(BeFalse())
+		Expect(cache.Stats()).To(Equal(eth.HeaderCacheStats{}))
+	})
+})
@@ -17,15 +17,20 @@
 package eth
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+	ethServerShared "github.com/ethereum/go-ethereum/statediff/indexer/shared"
 	"github.com/ethereum/go-ethereum/statediff/trie_helpers"
 	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/lib/pq"
@@ -148,6 +153,22 @@ const (
 											WHERE header_cids.block_number = $1
 											AND block_hash = (SELECT canonical_header_hash(header_cids.block_number))
 											ORDER BY eth.transaction_cids.index ASC`
+	RetrieveTransactionByBlockHashAndIndexPgStr = `SELECT transaction_cids.cid, data
+											FROM eth.transaction_cids
+												INNER JOIN eth.header_cids ON (
+													transaction_cids.header_id = header_cids.block_hash
+													AND transaction_cids.block_number = header_cids.block_number
+												)
+												INNER JOIN public.blocks ON (
+													transaction_cids.mh_key = blocks.key
+													AND transaction_cids.block_number = blocks.block_number
+												)
+											WHERE block_hash = $1
+											AND transaction_cids.index = $2`
+	RetrieveTxHashesByBlockHashPgStr = `SELECT tx_hash
+										FROM eth.transaction_cids
+										WHERE header_id = $1
+										ORDER BY index ASC`
 	RetrieveTransactionByHashPgStr = `SELECT DISTINCT ON (tx_hash) cid, data
 									FROM eth.transaction_cids
 										INNER JOIN public.blocks ON (
@@ -221,6 +242,14 @@ const (
 										WHERE header_cids.block_number = $1
 										AND block_hash = (SELECT canonical_header_hash(header_cids.block_number))
 										ORDER BY eth.transaction_cids.index ASC`
+	RetrieveReceiptsByBlockNumberFastPgStr = `SELECT receipt_cids.leaf_cid, data
+										FROM eth.receipt_cids
+											INNER JOIN public.blocks ON (
+												receipt_cids.leaf_mh_key = blocks.key
+												AND receipt_cids.block_number = blocks.block_number
+											)
+										WHERE receipt_cids.block_number = $1
+										AND receipt_cids.header_id = (SELECT canonical_header_hash($1))`
 	RetrieveReceiptByTxHashPgStr = `SELECT receipt_cids.leaf_cid, data
 									FROM eth.receipt_cids
 										INNER JOIN eth.transaction_cids ON (
@@ -247,6 +276,22 @@ const (
 												AND header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number))
 												ORDER BY header_cids.block_number DESC
 												LIMIT 1`
+	RetrieveAccountsByLeafKeysAndBlockHashPgStr = `SELECT DISTINCT ON (state_leaf_key) state_leaf_key, state_cids.cid, state_cids.node_type, blocks.data
+												FROM eth.state_cids
+													INNER JOIN eth.header_cids ON (
+														state_cids.header_id = header_cids.block_hash
+														AND state_cids.block_number = header_cids.block_number
+													)
+													LEFT JOIN public.blocks ON (
+														state_cids.mh_key = blocks.key
+														AND state_cids.block_number = blocks.block_number
+													)
+												WHERE state_leaf_key = ANY($1::VARCHAR(66)[])
+												AND header_cids.block_number <= (SELECT block_number
+																	FROM eth.header_cids
+																	WHERE block_hash = $2)
+												AND header_cids.block_hash = (SELECT canonical_header_hash(header_cids.block_number))
+												ORDER BY state_leaf_key, header_cids.block_number DESC`
 	RetrieveAccountByLeafKeyAndBlockNumberPgStr = `SELECT state_cids.cid, state_cids.mh_key, state_cids.node_type
 													FROM eth.state_cids
 														INNER JOIN eth.header_cids ON (
@@ -257,8 +302,95 @@ const (
 													AND header_cids.block_number <= $2
 													ORDER BY header_cids.block_number DESC
 													LIMIT 1`
+	RetrieveStorageLeafByAddressHashAndLeafKeyAndCanonicalBlockNumberPgStr = `SELECT storage_cids.cid, storage_cids.mh_key, storage_cids.block_number, storage_cids.node_type,
+																		EXISTS (SELECT 1
+																			FROM eth.state_cids AS removed
+																			WHERE removed.state_leaf_key = $1
+																			AND removed.node_type = 3
+																			AND removed.block_number > storage_cids.block_number
+																			AND removed.block_number <= $3
+																			AND removed.header_id = (SELECT canonical_header_hash(removed.block_number))
+																		) AS state_leaf_removed
+																	FROM eth.storage_cids
+																		INNER JOIN eth.state_cids ON (
+																			storage_cids.header_id = state_cids.header_id
+																			AND storage_cids.state_path = state_cids.state_path
+																			AND storage_cids.block_number = state_cids.block_number
+																		)
+																	WHERE state_cids.state_leaf_key = $1
+																	AND storage_cids.storage_leaf_key = $2
+																	AND storage_cids.block_number <= $3
+																	AND storage_cids.header_id = (SELECT canonical_header_hash(storage_cids.block_number))
+																	ORDER BY storage_cids.block_number DESC
+																	LIMIT 1`
 	RetrieveStorageLeafByAddressHashAndLeafKeyAndBlockNumberPgStr = `SELECT cid, mh_key, block_number, node_type, state_leaf_removed FROM get_storage_at_by_number($1, $2, $3)`
 	RetrieveStorageLeafByAddressHashAndLeafKeyAndBlockHashPgStr   = `SELECT cid, mh_key, block_number, node_type, state_leaf_removed FROM get_storage_at_by_hash($1, $2, $3)`
+	RetrieveStorageSlotHistoryPgStr                               = `SELECT storage_cids.block_number, storage_cids.node_type, blocks.data
+																	FROM eth.storage_cids
+																		INNER JOIN eth.state_cids ON (
+																			storage_cids.header_id = state_cids.header_id
+																			AND storage_cids.state_path = state_cids.state_path
+																			AND storage_cids.block_number = state_cids.block_number
+																		)
+																		INNER JOIN public.blocks ON (
+																			storage_cids.mh_key = blocks.key
+																			AND storage_cids.block_number = blocks.block_number
+																		)
+																	WHERE state_cids.state_leaf_key = $1
+																	AND storage_cids.storage_leaf_key = $2
+																	AND storage_cids.block_number BETWEEN $3 AND $4
+																	AND storage_cids.node_type IN (2, 3)
+																	AND storage_cids.header_id = (SELECT canonical_header_hash(storage_cids.block_number))
+																	UNION ALL
+																	SELECT removed.block_number, removed.node_type, NULL AS data
+																	FROM eth.state_cids AS removed
+																	WHERE removed.state_leaf_key = $1
+																	AND removed.block_number BETWEEN $3 AND $4
+																	AND removed.node_type = 3
+																	AND removed.header_id = (SELECT canonical_header_hash(removed.block_number))
+																	AND EXISTS (SELECT 1
+																		FROM eth.storage_cids
+																			INNER JOIN eth.state_cids ON (
+																				storage_cids.header_id = state_cids.header_id
+																				AND storage_cids.state_path = state_cids.state_path
+																				AND storage_cids.block_number = state_cids.block_number
+																			)
+																		WHERE state_cids.state_leaf_key = $1
+																		AND storage_cids.storage_leaf_key = $2
+																		AND storage_cids.block_number < removed.block_number
+																		AND storage_cids.header_id = (SELECT canonical_header_hash(storage_cids.block_number))
+																	)
+																	ORDER BY block_number`
+
+	RetrieveStorageLeavesByAddressHashAndLeafKeysAndBlockHashPgStr = `SELECT DISTINCT ON (storage_cids.storage_leaf_key) storage_cids.storage_leaf_key, storage_cids.cid,
+																		storage_cids.node_type, blocks.data,
+																		EXISTS (SELECT 1
+																			FROM eth.state_cids AS removed
+																			WHERE removed.state_leaf_key = $1
+																			AND removed.node_type = 3
+																			AND removed.block_number > storage_cids.block_number
+																			AND removed.block_number <= (SELECT block_number
+																								FROM eth.header_cids
+																								WHERE block_hash = $3)
+																			AND removed.header_id = (SELECT canonical_header_hash(removed.block_number))
+																		) AS state_leaf_removed
+																	FROM eth.storage_cids
+																		INNER JOIN eth.state_cids ON (
+																			storage_cids.header_id = state_cids.header_id
+																			AND storage_cids.state_path = state_cids.state_path
+																			AND storage_cids.block_number = state_cids.block_number
+																		)
+																		LEFT JOIN public.blocks ON (
+																			storage_cids.mh_key = blocks.key
+																			AND storage_cids.block_number = blocks.block_number
+																		)
+																	WHERE state_cids.state_leaf_key = $1
+																	AND storage_cids.storage_leaf_key = ANY($2::VARCHAR(66)[])
+																	AND storage_cids.block_number <= (SELECT block_number
+																						FROM eth.header_cids
+																						WHERE block_hash = $3)
+																	AND storage_cids.header_id = (SELECT canonical_header_hash(storage_cids.block_number))
+																	ORDER BY storage_cids.storage_leaf_key, storage_cids.block_number DESC`
 )
 
 var EmptyNodeValue = make([]byte, common.HashLength)
@@ -269,6 +401,27 @@ type rctIpldResult struct {
 	TxHash  string `db:"tx_hash"`
 }
 
+type accountLeafResult struct {
+	StateLeafKey string `db:"state_leaf_key"`
+	CID          string `db:"cid"`
+	NodeType     int    `db:"node_type"`
+	Data         []byte `db:"data"`
+}
+
+type storageLeafResult struct {
+	StorageLeafKey   string `db:"storage_leaf_key"`
+	CID              string `db:"cid"`
+	NodeType         int    `db:"node_type"`
+	Data             []byte `db:"data"`
+	StateLeafRemoved bool   `db:"state_leaf_removed"`
+}
+
+type storageSlotChangeResult struct {
+	BlockNumber int64  `db:"block_number"`
+	NodeType    int    `db:"node_type"`
+	Data        []byte `db:"data"`
+}
+
 type ipldResult struct {
 	CID    string `db:"cid"`
 	Data   []byte `db:"data"`
@@ -277,6 +430,9 @@ type ipldResult struct {
 
 type IPLDRetriever struct {
 	db *sqlx.DB
+
+	// QueryTimeout bounds how long a single query may run, 0 disables the timeout
+	QueryTimeout time.Duration
 }
 
 func NewIPLDRetriever(db *sqlx.DB) *IPLDRetriever {
@@ -292,7 +448,7 @@ func (r *IPLDRetriever) RetrieveHeadersByHashes(hashes []common.Hash) ([]string,
 	for i, hash := range hashes {
 		hashStrs[i] = hash.Hex()
 	}
-	if err := r.db.Select(&headerResults, RetrieveHeadersByHashesPgStr, pq.Array(hashStrs)); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &headerResults, RetrieveHeadersByHashesPgStr, pq.Array(hashStrs)); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(headerResults))
@@ -308,7 +464,7 @@ func (r *IPLDRetriever) RetrieveHeadersByHashes(hashes []common.Hash) ([]string,
 // This can return more than one result since there can be more than one header (non-canonical headers)
 func (r *IPLDRetriever) RetrieveHeadersByBlockNumber(number uint64) ([]string, [][]byte, error) {
 	headerResults := make([]ipldResult, 0)
-	if err := r.db.Select(&headerResults, RetrieveHeadersByBlockNumberPgStr, number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &headerResults, RetrieveHeadersByBlockNumberPgStr, number); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(headerResults))
@@ -323,7 +479,7 @@ func (r *IPLDRetriever) RetrieveHeadersByBlockNumber(number uint64) ([]string, [
 // RetrieveHeaderByHash returns the cid and rlp bytes for the header corresponding to the provided block hash
 func (r *IPLDRetriever) RetrieveHeaderByHash(tx *sqlx.Tx, hash common.Hash) (string, []byte, error) {
 	headerResult := new(ipldResult)
-	return headerResult.CID, headerResult.Data, tx.Get(headerResult, RetrieveHeaderByHashPgStr, hash.Hex())
+	return headerResult.CID, headerResult.Data, getWithTimeout(r.QueryTimeout, tx, headerResult, RetrieveHeaderByHashPgStr, hash.Hex())
 }
 
 // RetrieveUnclesByHashes returns the cids and rlp bytes for the uncles corresponding to the provided uncle hashes
@@ -333,7 +489,7 @@ func (r *IPLDRetriever) RetrieveUnclesByHashes(hashes []common.Hash) ([]string,
 	for i, hash := range hashes {
 		hashStrs[i] = hash.Hex()
 	}
-	if err := r.db.Select(&uncleResults, RetrieveUnclesByHashesPgStr, pq.Array(hashStrs)); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &uncleResults, RetrieveUnclesByHashesPgStr, pq.Array(hashStrs)); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(uncleResults))
@@ -348,7 +504,7 @@ func (r *IPLDRetriever) RetrieveUnclesByHashes(hashes []common.Hash) ([]string,
 // RetrieveUncles returns the cids and rlp bytes for the uncles corresponding to the provided block hash, number (of non-omner root block)
 func (r *IPLDRetriever) RetrieveUncles(tx *sqlx.Tx, hash common.Hash, number uint64) ([]string, [][]byte, error) {
 	uncleResults := make([]ipldResult, 0)
-	if err := tx.Select(&uncleResults, RetrieveUnclesPgStr, hash.Hex(), number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, tx, &uncleResults, RetrieveUnclesPgStr, hash.Hex(), number); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(uncleResults))
@@ -363,7 +519,7 @@ func (r *IPLDRetriever) RetrieveUncles(tx *sqlx.Tx, hash common.Hash, number uin
 // RetrieveUnclesByBlockHash returns the cids and rlp bytes for the uncles corresponding to the provided block hash (of non-omner root block)
 func (r *IPLDRetriever) RetrieveUnclesByBlockHash(tx *sqlx.Tx, hash common.Hash) ([]string, [][]byte, error) {
 	uncleResults := make([]ipldResult, 0)
-	if err := tx.Select(&uncleResults, RetrieveUnclesByBlockHashPgStr, hash.Hex()); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, tx, &uncleResults, RetrieveUnclesByBlockHashPgStr, hash.Hex()); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(uncleResults))
@@ -378,7 +534,7 @@ func (r *IPLDRetriever) RetrieveUnclesByBlockHash(tx *sqlx.Tx, hash common.Hash)
 // RetrieveUnclesByBlockNumber returns the cids and rlp bytes for the uncles corresponding to the provided block number (of non-omner root block)
 func (r *IPLDRetriever) RetrieveUnclesByBlockNumber(number uint64) ([]string, [][]byte, error) {
 	uncleResults := make([]ipldResult, 0)
-	if err := r.db.Select(&uncleResults, RetrieveUnclesByBlockNumberPgStr, number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &uncleResults, RetrieveUnclesByBlockNumberPgStr, number); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(uncleResults))
@@ -393,7 +549,7 @@ func (r *IPLDRetriever) RetrieveUnclesByBlockNumber(number uint64) ([]string, []
 // RetrieveUncleByHash returns the cid and rlp bytes for the uncle corresponding to the provided uncle hash
 func (r *IPLDRetriever) RetrieveUncleByHash(hash common.Hash) (string, []byte, error) {
 	uncleResult := new(ipldResult)
-	return uncleResult.CID, uncleResult.Data, r.db.Get(uncleResult, RetrieveUncleByHashPgStr, hash.Hex())
+	return uncleResult.CID, uncleResult.Data, getWithTimeout(r.QueryTimeout, r.db, uncleResult, RetrieveUncleByHashPgStr, hash.Hex())
 }
 
 // RetrieveTransactionsByHashes returns the cids and rlp bytes for the transactions corresponding to the provided tx hashes
@@ -403,7 +559,7 @@ func (r *IPLDRetriever) RetrieveTransactionsByHashes(hashes []common.Hash) ([]st
 	for i, hash := range hashes {
 		hashStrs[i] = hash.Hex()
 	}
-	if err := r.db.Select(&txResults, RetrieveTransactionsByHashesPgStr, pq.Array(hashStrs)); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &txResults, RetrieveTransactionsByHashesPgStr, pq.Array(hashStrs)); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(txResults))
@@ -418,7 +574,7 @@ func (r *IPLDRetriever) RetrieveTransactionsByHashes(hashes []common.Hash) ([]st
 // RetrieveTransactions returns the cids and rlp bytes for the transactions corresponding to the provided block hash, number
 func (r *IPLDRetriever) RetrieveTransactions(tx *sqlx.Tx, hash common.Hash, number uint64) ([]string, [][]byte, error) {
 	txResults := make([]ipldResult, 0)
-	if err := tx.Select(&txResults, RetrieveTransactionsPgStr, hash.Hex(), number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, tx, &txResults, RetrieveTransactionsPgStr, hash.Hex(), number); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(txResults))
@@ -433,7 +589,7 @@ func (r *IPLDRetriever) RetrieveTransactions(tx *sqlx.Tx, hash common.Hash, numb
 // RetrieveTransactionsByBlockHash returns the cids and rlp bytes for the transactions corresponding to the provided block hash
 func (r *IPLDRetriever) RetrieveTransactionsByBlockHash(tx *sqlx.Tx, hash common.Hash) ([]string, [][]byte, error) {
 	txResults := make([]ipldResult, 0)
-	if err := tx.Select(&txResults, RetrieveTransactionsByBlockHashPgStr, hash.Hex()); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, tx, &txResults, RetrieveTransactionsByBlockHashPgStr, hash.Hex()); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(txResults))
@@ -448,7 +604,7 @@ func (r *IPLDRetriever) RetrieveTransactionsByBlockHash(tx *sqlx.Tx, hash common
 // RetrieveTransactionsByBlockNumber returns the cids and rlp bytes for the transactions corresponding to the provided block number
 func (r *IPLDRetriever) RetrieveTransactionsByBlockNumber(number uint64) ([]string, [][]byte, error) {
 	txResults := make([]ipldResult, 0)
-	if err := r.db.Select(&txResults, RetrieveTransactionsByBlockNumberPgStr, number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &txResults, RetrieveTransactionsByBlockNumberPgStr, number); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(txResults))
@@ -460,10 +616,33 @@ func (r *IPLDRetriever) RetrieveTransactionsByBlockNumber(number uint64) ([]stri
 	return cids, txs, nil
 }
 
+// RetrieveTransactionByBlockHashAndIndex returns the cid and rlp bytes for the transaction at the provided index of the block with the provided hash
+func (r *IPLDRetriever) RetrieveTransactionByBlockHashAndIndex(hash common.Hash, index uint64) (string, []byte, error) {
+	txResult := new(ipldResult)
+	if err := getWithTimeout(r.QueryTimeout, r.db, txResult, RetrieveTransactionByBlockHashAndIndexPgStr, hash.Hex(), index); err != nil {
+		return "", nil, err
+	}
+	return txResult.CID, txResult.Data, nil
+}
+
+// RetrieveTxHashesByBlockHash returns the hashes of the transactions of the provided block hash, in tx index order,
+// without fetching the transactions themselves
+func (r *IPLDRetriever) RetrieveTxHashesByBlockHash(hash common.Hash) ([]common.Hash, error) {
+	txHashes := make([]string, 0)
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &txHashes, RetrieveTxHashesByBlockHashPgStr, hash.Hex()); err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, len(txHashes))
+	for i, txHash := range txHashes {
+		hashes[i] = common.HexToHash(txHash)
+	}
+	return hashes, nil
+}
+
 // RetrieveTransactionByTxHash returns the cid and rlp bytes for the transaction corresponding to the provided tx hash
 func (r *IPLDRetriever) RetrieveTransactionByTxHash(hash common.Hash) (string, []byte, error) {
 	txResult := new(ipldResult)
-	return txResult.CID, txResult.Data, r.db.Get(txResult, RetrieveTransactionByHashPgStr, hash.Hex())
+	return txResult.CID, txResult.Data, getWithTimeout(r.QueryTimeout, r.db, txResult, RetrieveTransactionByHashPgStr, hash.Hex())
 }
 
 // DecodeLeafNode decodes the leaf node data
@@ -483,34 +662,40 @@ func DecodeLeafNode(node []byte) ([]byte, error) {
 	return nodeElements[1].([]byte), nil
 }
 
-// RetrieveReceiptsByTxHashes returns the cids and rlp bytes for the receipts corresponding to the provided tx hashes
-func (r *IPLDRetriever) RetrieveReceiptsByTxHashes(hashes []common.Hash) ([]string, [][]byte, error) {
+// RetrieveReceiptsByTxHashes returns the cids, rlp bytes and logs blooms for the receipts corresponding to the provided tx hashes
+func (r *IPLDRetriever) RetrieveReceiptsByTxHashes(hashes []common.Hash) ([]string, [][]byte, []types.Bloom, error) {
 	rctResults := make([]rctIpldResult, 0)
 	hashStrs := make([]string, len(hashes))
 	for i, hash := range hashes {
 		hashStrs[i] = hash.Hex()
 	}
-	if err := r.db.Select(&rctResults, RetrieveReceiptsByTxHashesPgStr, pq.Array(hashStrs)); err != nil {
-		return nil, nil, err
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &rctResults, RetrieveReceiptsByTxHashesPgStr, pq.Array(hashStrs)); err != nil {
+		return nil, nil, nil, err
 	}
 	cids := make([]string, len(rctResults))
 	rcts := make([][]byte, len(rctResults))
+	blooms := make([]types.Bloom, len(rctResults))
 	for i, res := range rctResults {
 		cids[i] = res.LeafCID
 		nodeVal, err := DecodeLeafNode(res.Data)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		rcts[i] = nodeVal
+		rct := new(types.Receipt)
+		if err := rct.UnmarshalBinary(nodeVal); err != nil {
+			return nil, nil, nil, err
+		}
+		blooms[i] = rct.Bloom
 	}
-	return cids, rcts, nil
+	return cids, rcts, blooms, nil
 }
 
 // RetrieveReceipts returns the cids and rlp bytes for the receipts corresponding to the provided block hash, number.
 // cid returned corresponds to the leaf node data which contains the receipt.
 func (r *IPLDRetriever) RetrieveReceipts(tx *sqlx.Tx, hash common.Hash, number uint64) ([]string, [][]byte, []common.Hash, error) {
 	rctResults := make([]rctIpldResult, 0)
-	if err := tx.Select(&rctResults, RetrieveReceiptsPgStr, hash.Hex(), number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, tx, &rctResults, RetrieveReceiptsPgStr, hash.Hex(), number); err != nil {
 		return nil, nil, nil, err
 	}
 	cids := make([]string, len(rctResults))
@@ -534,7 +719,7 @@ func (r *IPLDRetriever) RetrieveReceipts(tx *sqlx.Tx, hash common.Hash, number u
 // cid returned corresponds to the leaf node data which contains the receipt.
 func (r *IPLDRetriever) RetrieveReceiptsByBlockHash(tx *sqlx.Tx, hash common.Hash) ([]string, [][]byte, []common.Hash, error) {
 	rctResults := make([]rctIpldResult, 0)
-	if err := tx.Select(&rctResults, RetrieveReceiptsByBlockHashPgStr, hash.Hex()); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, tx, &rctResults, RetrieveReceiptsByBlockHashPgStr, hash.Hex()); err != nil {
 		return nil, nil, nil, err
 	}
 	cids := make([]string, len(rctResults))
@@ -554,15 +739,38 @@ func (r *IPLDRetriever) RetrieveReceiptsByBlockHash(tx *sqlx.Tx, hash common.Has
 	return cids, rcts, txs, nil
 }
 
-// RetrieveReceiptsByBlockNumber returns the cids and rlp bytes for the receipts corresponding to the provided block hash.
-// cid returned corresponds to the leaf node data which contains the receipt.
+// RetrieveReceiptsByBlockNumber returns the cids and rlp bytes for the receipts of the canonical block at the provided number,
+// in tx index order. cid returned corresponds to the leaf node data which contains the receipt.
 func (r *IPLDRetriever) RetrieveReceiptsByBlockNumber(number uint64) ([]string, [][]byte, error) {
 	rctResults := make([]rctIpldResult, 0)
-	if err := r.db.Select(&rctResults, RetrieveReceiptsByBlockNumberPgStr, number); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &rctResults, RetrieveReceiptsByBlockNumberPgStr, number); err != nil {
+		return nil, nil, err
+	}
+	cids := make([]string, len(rctResults))
+	rcts := make([][]byte, len(rctResults))
+	for i, res := range rctResults {
+		cids[i] = res.LeafCID
+		nodeVal, err := DecodeLeafNode(res.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		rcts[i] = nodeVal
+	}
+	return cids, rcts, nil
+}
+
+// RetrieveReceiptsByBlockNumberFast returns the same receipts as RetrieveReceiptsByBlockNumber, without joining
+// the receipts to their transactions and headers. The receipts are selected by their own block number and the
+// canonical header hash, and are put in tx index order by their cumulative gas used, which strictly increases
+// within a block as every tx uses at least the intrinsic gas.
+func (r *IPLDRetriever) RetrieveReceiptsByBlockNumberFast(number uint64) ([]string, [][]byte, error) {
+	rctResults := make([]rctIpldResult, 0)
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &rctResults, RetrieveReceiptsByBlockNumberFastPgStr, number); err != nil {
 		return nil, nil, err
 	}
 	cids := make([]string, len(rctResults))
 	rcts := make([][]byte, len(rctResults))
+	cumulativeGas := make([]uint64, len(rctResults))
 	for i, res := range rctResults {
 		cids[i] = res.LeafCID
 		nodeVal, err := DecodeLeafNode(res.Data)
@@ -570,15 +778,38 @@ func (r *IPLDRetriever) RetrieveReceiptsByBlockNumber(number uint64) ([]string,
 			return nil, nil, err
 		}
 		rcts[i] = nodeVal
+		rct := new(types.Receipt)
+		if err := rct.UnmarshalBinary(nodeVal); err != nil {
+			return nil, nil, err
+		}
+		cumulativeGas[i] = rct.CumulativeGasUsed
 	}
+	sort.Sort(receiptsByCumulativeGas{cids: cids, rcts: rcts, cumulativeGas: cumulativeGas})
 	return cids, rcts, nil
 }
 
+// receiptsByCumulativeGas sorts the cids and rlp bytes of a block's receipts by their cumulative gas used
+type receiptsByCumulativeGas struct {
+	cids          []string
+	rcts          [][]byte
+	cumulativeGas []uint64
+}
+
+func (s receiptsByCumulativeGas) Len() int { return len(s.rcts) }
+
+func (s receiptsByCumulativeGas) Less(i, j int) bool { return s.cumulativeGas[i] < s.cumulativeGas[j] }
+
+func (s receiptsByCumulativeGas) Swap(i, j int) {
+	s.cids[i], s.cids[j] = s.cids[j], s.cids[i]
+	s.rcts[i], s.rcts[j] = s.rcts[j], s.rcts[i]
+	s.cumulativeGas[i], s.cumulativeGas[j] = s.cumulativeGas[j], s.cumulativeGas[i]
+}
+
 // RetrieveReceiptByHash returns the cid and rlp bytes for the receipt corresponding to the provided tx hash.
 // cid returned corresponds to the leaf node data which contains the receipt.
 func (r *IPLDRetriever) RetrieveReceiptByHash(hash common.Hash) (string, []byte, error) {
 	rctResult := new(rctIpldResult)
-	if err := r.db.Select(&rctResult, RetrieveReceiptByTxHashPgStr, hash.Hex()); err != nil {
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &rctResult, RetrieveReceiptByTxHashPgStr, hash.Hex()); err != nil {
 		return "", nil, err
 	}
 
@@ -601,33 +832,98 @@ type nodeInfo struct {
 // RetrieveAccountByAddressAndBlockHash returns the cid and rlp bytes for the account corresponding to the provided address and block hash
 // TODO: ensure this handles deleted accounts appropriately
 func (r *IPLDRetriever) RetrieveAccountByAddressAndBlockHash(address common.Address, hash common.Hash) (string, []byte, error) {
+	cid, _, accountRLP, err := r.RetrieveStateLeafByAddressAndBlockHash(address, hash)
+	return cid, accountRLP, err
+}
+
+// RetrieveStateLeafByAddressAndBlockHash returns the cid, raw leaf node IPLD and account rlp bytes for the state leaf corresponding to the provided address and block hash
+func (r *IPLDRetriever) RetrieveStateLeafByAddressAndBlockHash(address common.Address, hash common.Hash) (string, []byte, []byte, error) {
 	accountResult := new(nodeInfo)
 	leafKey := crypto.Keccak256Hash(address.Bytes())
-	if err := r.db.Get(accountResult, RetrieveAccountByLeafKeyAndBlockHashPgStr, leafKey.Hex(), hash.Hex()); err != nil {
-		return "", nil, err
+	if err := getWithTimeout(r.QueryTimeout, r.db, accountResult, RetrieveAccountByLeafKeyAndBlockHashPgStr, leafKey.Hex(), hash.Hex()); err != nil {
+		return "", nil, nil, err
 	}
 
 	if accountResult.NodeType == sdtypes.Removed.Int() {
-		return "", EmptyNodeValue, nil
+		return "", EmptyNodeValue, EmptyNodeValue, nil
 	}
 
 	blockNumber, err := strconv.ParseUint(accountResult.BlockNumber, 10, 64)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	accountResult.Data, err = shared.FetchIPLD(r.db, accountResult.MhKey, blockNumber)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
 	var i []interface{}
 	if err := rlp.DecodeBytes(accountResult.Data, &i); err != nil {
-		return "", nil, fmt.Errorf("error decoding state leaf node rlp: %s", err.Error())
+		return "", nil, nil, fmt.Errorf("error decoding state leaf node rlp: %s", err.Error())
 	}
 	if len(i) != 2 {
-		return "", nil, fmt.Errorf("eth IPLDRetriever expected state leaf node rlp to decode into two elements")
+		return "", nil, nil, fmt.Errorf("eth IPLDRetriever expected state leaf node rlp to decode into two elements")
 	}
-	return accountResult.CID, i[1].([]byte), nil
+	return accountResult.CID, accountResult.Data, i[1].([]byte), nil
+}
+
+// RetrieveCodeByCodeHash returns the contract code with the provided code hash
+func (r *IPLDRetriever) RetrieveCodeByCodeHash(codeHash common.Hash) ([]byte, error) {
+	if bytes.Equal(codeHash.Bytes(), emptyCodeHash) {
+		return []byte{}, nil
+	}
+	mhKey, err := ethServerShared.MultihashKeyFromKeccak256(codeHash)
+	if err != nil {
+		return nil, err
+	}
+	code := make([]byte, 0)
+	if err := getWithTimeout(r.QueryTimeout, r.db, &code, RetrieveCodeByMhKey, mhKey); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// RetrieveCodeByAddressAndBlockHash returns the code of the contract deployed at the provided address as of the canonical
+// block with the provided hash, reading the account's code hash rather than resolving its state
+func (r *IPLDRetriever) RetrieveCodeByAddressAndBlockHash(address common.Address, hash common.Hash) ([]byte, error) {
+	codeHash := make([]byte, 0)
+	leafKey := crypto.Keccak256Hash(address.Bytes())
+	if err := getWithTimeout(r.QueryTimeout, r.db, &codeHash, RetrieveCodeHashByLeafKeyAndBlockHash, leafKey.Hex(), hash.Hex()); err != nil {
+		return nil, err
+	}
+	return r.RetrieveCodeByCodeHash(common.BytesToHash(codeHash))
+}
+
+// RetrieveAccountsByAddressesAndBlockHash returns the cid and rlp bytes of the accounts corresponding to the provided
+// addresses at the block with the provided hash, using a single query.
+// Addresses without an account at that block are absent from the returned map.
+func (r *IPLDRetriever) RetrieveAccountsByAddressesAndBlockHash(addresses []common.Address, hash common.Hash) (map[common.Address]AccountLeafResult, error) {
+	leafKeys := make([]string, len(addresses))
+	addressesByLeafKey := make(map[string]common.Address, len(addresses))
+	for i, address := range addresses {
+		leafKeys[i] = crypto.Keccak256Hash(address.Bytes()).Hex()
+		addressesByLeafKey[leafKeys[i]] = address
+	}
+	accountResults := make([]accountLeafResult, 0)
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &accountResults, RetrieveAccountsByLeafKeysAndBlockHashPgStr, pq.Array(leafKeys), hash.Hex()); err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[common.Address]AccountLeafResult, len(accountResults))
+	for _, res := range accountResults {
+		if res.NodeType == sdtypes.Removed.Int() {
+			continue
+		}
+		account, err := DecodeLeafNode(res.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding state leaf node rlp: %s", err.Error())
+		}
+		accounts[addressesByLeafKey[res.StateLeafKey]] = AccountLeafResult{
+			CID:  res.CID,
+			Data: account,
+		}
+	}
+	return accounts, nil
 }
 
 // RetrieveAccountByAddressAndBlockNumber returns the cid and rlp bytes for the account corresponding to the provided address and block number
@@ -635,7 +931,7 @@ func (r *IPLDRetriever) RetrieveAccountByAddressAndBlockHash(address common.Addr
 func (r *IPLDRetriever) RetrieveAccountByAddressAndBlockNumber(address common.Address, number uint64) (string, []byte, error) {
 	accountResult := new(nodeInfo)
 	leafKey := crypto.Keccak256Hash(address.Bytes())
-	if err := r.db.Get(accountResult, RetrieveAccountByLeafKeyAndBlockNumberPgStr, leafKey.Hex(), number); err != nil {
+	if err := getWithTimeout(r.QueryTimeout, r.db, accountResult, RetrieveAccountByLeafKeyAndBlockNumberPgStr, leafKey.Hex(), number); err != nil {
 		return "", nil, err
 	}
 
@@ -664,7 +960,106 @@ func (r *IPLDRetriever) RetrieveStorageAtByAddressAndStorageSlotAndBlockHash(add
 	storageResult := new(nodeInfo)
 	stateLeafKey := crypto.Keccak256Hash(address.Bytes())
 	storageHash := crypto.Keccak256Hash(key.Bytes())
-	if err := r.db.Get(storageResult, RetrieveStorageLeafByAddressHashAndLeafKeyAndBlockHashPgStr, stateLeafKey.Hex(), storageHash.Hex(), hash.Hex()); err != nil {
+	if err := getWithTimeout(r.QueryTimeout, r.db, storageResult, RetrieveStorageLeafByAddressHashAndLeafKeyAndBlockHashPgStr, stateLeafKey.Hex(), storageHash.Hex(), hash.Hex()); err != nil {
+		return "", nil, nil, err
+	}
+	if storageResult.StateLeafRemoved || storageResult.NodeType == sdtypes.Removed.Int() {
+		return "", EmptyNodeValue, EmptyNodeValue, nil
+	}
+
+	blockNumber, err := strconv.ParseUint(storageResult.BlockNumber, 10, 64)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	storageResult.Data, err = shared.FetchIPLD(r.db, storageResult.MhKey, blockNumber)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var i []interface{}
+	if err := rlp.DecodeBytes(storageResult.Data, &i); err != nil {
+		err = fmt.Errorf("error decoding storage leaf node rlp: %s", err.Error())
+		return "", nil, nil, err
+	}
+	if len(i) != 2 {
+		return "", nil, nil, fmt.Errorf("eth IPLDRetriever expected storage leaf node rlp to decode into two elements")
+	}
+	return storageResult.CID, storageResult.Data, i[1].([]byte), nil
+}
+
+// RetrieveStorageAtByAddressAndStorageSlotsAndBlockHash returns the rlp bytes of the values of the provided storage slots of
+// the contract at the provided address at the block with the provided hash, using a single query.
+// Slots without a storage leaf at that block are absent from the returned map, cleared slots hold EmptyNodeValue
+func (r *IPLDRetriever) RetrieveStorageAtByAddressAndStorageSlotsAndBlockHash(address common.Address, slots []common.Hash, hash common.Hash) (map[common.Hash][]byte, error) {
+	stateLeafKey := crypto.Keccak256Hash(address.Bytes())
+	leafKeys := make([]string, len(slots))
+	slotsByLeafKey := make(map[string]common.Hash, len(slots))
+	for i, slot := range slots {
+		leafKeys[i] = crypto.Keccak256Hash(slot.Bytes()).Hex()
+		slotsByLeafKey[leafKeys[i]] = slot
+	}
+	storageResults := make([]storageLeafResult, 0)
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &storageResults, RetrieveStorageLeavesByAddressHashAndLeafKeysAndBlockHashPgStr, stateLeafKey.Hex(), pq.Array(leafKeys), hash.Hex()); err != nil {
+		return nil, err
+	}
+
+	values := make(map[common.Hash][]byte, len(storageResults))
+	for _, res := range storageResults {
+		slot := slotsByLeafKey[res.StorageLeafKey]
+		if res.StateLeafRemoved || res.NodeType == sdtypes.Removed.Int() {
+			values[slot] = EmptyNodeValue
+			continue
+		}
+		value, err := DecodeLeafNode(res.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding storage leaf node rlp: %s", err.Error())
+		}
+		values[slot] = value
+	}
+	return values, nil
+}
+
+// RetrieveStorageSlotHistory returns the changes of the storage slot of the provided contract within the provided
+// range (inclusive) of canonical blocks, in block order, with each value decoded from its storage leaf node
+// The removal of the contract account clears a slot that was set before it, which is included as an empty value
+func (r *IPLDRetriever) RetrieveStorageSlotHistory(address common.Address, slot common.Hash, from, to int64) ([]StorageSlotChange, error) {
+	stateLeafKey := crypto.Keccak256Hash(address.Bytes())
+	storageHash := crypto.Keccak256Hash(slot.Bytes())
+	results := make([]storageSlotChangeResult, 0)
+	if err := selectWithTimeout(r.QueryTimeout, r.db, &results, RetrieveStorageSlotHistoryPgStr, stateLeafKey.Hex(), storageHash.Hex(), from, to); err != nil {
+		return nil, err
+	}
+
+	changes := make([]StorageSlotChange, len(results))
+	for idx, res := range results {
+		changes[idx].BlockNumber = res.BlockNumber
+		if res.NodeType == sdtypes.Removed.Int() {
+			changes[idx].Value = []byte{}
+			continue
+		}
+		var i []interface{}
+		if err := rlp.DecodeBytes(res.Data, &i); err != nil {
+			return nil, fmt.Errorf("error decoding storage leaf node rlp: %s", err.Error())
+		}
+		if len(i) != 2 {
+			return nil, fmt.Errorf("eth IPLDRetriever expected storage leaf node rlp to decode into two elements")
+		}
+		var value []byte
+		if err := rlp.DecodeBytes(i[1].([]byte), &value); err != nil {
+			return nil, fmt.Errorf("error decoding storage value rlp: %s", err.Error())
+		}
+		changes[idx].Value = value
+	}
+	return changes, nil
+}
+
+// RetrieveStorageAtByAddressAndStorageSlotAndBlockNumber returns the cid, leaf node IPLD and rlp bytes for the storage value corresponding to the provided address, storage slot, and block number
+// Only the canonical chain is considered, the value is the one from the most recent canonical update at or below the block number
+func (r *IPLDRetriever) RetrieveStorageAtByAddressAndStorageSlotAndBlockNumber(address common.Address, key common.Hash, number uint64) (string, []byte, []byte, error) {
+	storageResult := new(nodeInfo)
+	stateLeafKey := crypto.Keccak256Hash(address.Bytes())
+	storageHash := crypto.Keccak256Hash(key.Bytes())
+	if err := getWithTimeout(r.QueryTimeout, r.db, storageResult, RetrieveStorageLeafByAddressHashAndLeafKeyAndCanonicalBlockNumberPgStr, stateLeafKey.Hex(), storageHash.Hex(), number); err != nil {
 		return "", nil, nil, err
 	}
 	if storageResult.StateLeafRemoved || storageResult.NodeType == sdtypes.Removed.Int() {
@@ -696,7 +1091,7 @@ func (r *IPLDRetriever) RetrieveStorageAtByAddressAndStorageSlotAndBlockHash(add
 func (r *IPLDRetriever) RetrieveStorageAtByAddressAndStorageKeyAndBlockNumber(address common.Address, storageLeafKey common.Hash, number uint64) (string, []byte, error) {
 	storageResult := new(nodeInfo)
 	stateLeafKey := crypto.Keccak256Hash(address.Bytes())
-	if err := r.db.Get(storageResult, RetrieveStorageLeafByAddressHashAndLeafKeyAndBlockNumberPgStr, stateLeafKey.Hex(), storageLeafKey.Hex(), number); err != nil {
+	if err := getWithTimeout(r.QueryTimeout, r.db, storageResult, RetrieveStorageLeafByAddressHashAndLeafKeyAndBlockNumberPgStr, stateLeafKey.Hex(), storageLeafKey.Hex(), number); err != nil {
 		return "", nil, err
 	}
 
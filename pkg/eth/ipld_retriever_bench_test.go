@@ -0,0 +1,87 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+// BenchmarkRetrieveReceiptsByBlockNumber compares retrieving the receipts of a 500 tx block
+// with and without joining them to their transactions and headers
+func BenchmarkRetrieveReceiptsByBlockNumber(b *testing.B) {
+	RegisterTestingT(b)
+	const txCount = 500
+
+	db := shared.SetupDB()
+	defer shared.TearDownDB(db)
+
+	signer := types.HomesteadSigner{}
+	txs := make(types.Transactions, txCount)
+	rcts := make(types.Receipts, txCount)
+	for i := range txs {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), test_helpers.Account1Addr, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, test_helpers.TestBankKey)
+		Expect(err).ToNot(HaveOccurred())
+		txs[i] = tx
+		rct := types.NewReceipt(nil, false, uint64(i+1)*params.TxGas)
+		rct.TxHash = tx.Hash()
+		rct.GasUsed = params.TxGas
+		rct.Bloom = types.CreateBloom(types.Receipts{rct})
+		rcts[i] = rct
+	}
+	header := &types.Header{
+		ParentHash: test_helpers.Genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		GasLimit:   txCount * params.TxGas,
+		GasUsed:    txCount * params.TxGas,
+	}
+	block := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+	chainConfig := *params.TestChainConfig
+	indexer := shared.SetupTestStateDiffIndexer(context.Background(), &chainConfig, test_helpers.Genesis.Hash())
+	tx, err := indexer.PushBlock(block, rcts, block.Difficulty())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(tx.Submit(err)).To(Succeed())
+
+	retriever := eth.NewIPLDRetriever(db)
+	for _, bm := range []struct {
+		name     string
+		retrieve func(uint64) ([]string, [][]byte, error)
+	}{
+		{"joined", retriever.RetrieveReceiptsByBlockNumber},
+		{"fast", retriever.RetrieveReceiptsByBlockNumberFast},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, receipts, err := bm.retrieve(block.NumberU64())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(receipts)).To(Equal(txCount))
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"database/sql"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+var _ = Describe("IPLDRetriever", func() {
+	var (
+		db        *sqlx.DB
+		retriever *eth.IPLDRetriever
+	)
+	BeforeEach(func() {
+		db = shared.SetupDB()
+		indexer := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+		tx, err := indexer.PushBlock(test_helpers.MockBlock, test_helpers.MockReceipts, test_helpers.MockBlock.Difficulty())
+		Expect(err).ToNot(HaveOccurred())
+		for _, node := range test_helpers.MockStateNodes {
+			err = indexer.PushStateNode(tx, node, test_helpers.MockBlock.Hash().String())
+			Expect(err).ToNot(HaveOccurred())
+		}
+		err = indexer.PushCodeAndCodeHash(tx, sdtypes.CodeAndCodeHash{
+			Hash: test_helpers.ContractCodeHash,
+			Code: test_helpers.ContractCode,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		err = tx.Submit(err)
+		Expect(err).ToNot(HaveOccurred())
+		retriever = eth.NewIPLDRetriever(db)
+	})
+	AfterEach(func() {
+		shared.TearDownDB(db)
+	})
+
+	Describe("RetrieveReceiptsByTxHashes", func() {
+		It("Retrieves the logs bloom of each receipt, matching the receipt's logs", func() {
+			hashes := make([]common.Hash, len(test_helpers.MockTransactions))
+			for i, trx := range test_helpers.MockTransactions {
+				hashes[i] = trx.Hash()
+			}
+			cids, rcts, blooms, err := retriever.RetrieveReceiptsByTxHashes(hashes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(cids)).To(Equal(len(hashes)))
+			Expect(len(rcts)).To(Equal(len(hashes)))
+			Expect(len(blooms)).To(Equal(len(hashes)))
+
+			for i, bloom := range blooms {
+				rct := new(types.Receipt)
+				err = rct.UnmarshalBinary(rcts[i])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bloom).To(Equal(types.CreateBloom(types.Receipts{rct})))
+				for _, log := range rct.Logs {
+					Expect(bloom.Test(log.Address.Bytes())).To(BeTrue())
+					for _, topic := range log.Topics {
+						Expect(bloom.Test(topic.Bytes())).To(BeTrue())
+					}
+				}
+			}
+		})
+	})
+
+	Describe("RetrieveAccountsByAddressesAndBlockHash", func() {
+		It("Retrieves the accounts of the provided addresses, leaving out missing accounts", func() {
+			missingAddr := common.HexToAddress("0x1C3ab14BBaD3D99F4203bd7a11aCB94882050E6f")
+			addresses := []common.Address{test_helpers.ContractAddress, missingAddr, test_helpers.AccountAddresss}
+			accounts, err := retriever.RetrieveAccountsByAddressesAndBlockHash(addresses, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(accounts)).To(Equal(2))
+			Expect(accounts).ToNot(HaveKey(missingAddr))
+
+			for _, address := range []common.Address{test_helpers.ContractAddress, test_helpers.AccountAddresss} {
+				cid, data, err := retriever.RetrieveAccountByAddressAndBlockHash(address, test_helpers.MockBlock.Hash())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(accounts).To(HaveKey(address))
+				Expect(accounts[address].CID).To(Equal(cid))
+				Expect(accounts[address].Data).To(Equal(data))
+			}
+			Expect(accounts[test_helpers.ContractAddress].Data).To(Equal(test_helpers.ContractAccount))
+			Expect(accounts[test_helpers.AccountAddresss].Data).To(Equal(test_helpers.Account))
+		})
+
+		It("Retrieves no accounts for a block that cannot be found", func() {
+			accounts, err := retriever.RetrieveAccountsByAddressesAndBlockHash([]common.Address{test_helpers.ContractAddress}, common.HexToHash("0x01"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(accounts).To(BeEmpty())
+		})
+	})
+
+	Describe("RetrieveStorageAtByAddressAndStorageSlotsAndBlockHash", func() {
+		It("Retrieves the values of the provided slots, leaving out slots without a storage leaf", func() {
+			slot, missingSlot := common.HexToHash("0"), common.HexToHash("0x01")
+			values, err := retriever.RetrieveStorageAtByAddressAndStorageSlotsAndBlockHash(test_helpers.ContractAddress, []common.Hash{slot, missingSlot}, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(values)).To(Equal(1))
+			Expect(values).ToNot(HaveKey(missingSlot))
+
+			_, _, value, err := retriever.RetrieveStorageAtByAddressAndStorageSlotAndBlockHash(test_helpers.ContractAddress, slot, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(HaveKeyWithValue(slot, value))
+			Expect(values[slot]).To(Equal(test_helpers.StorageValue))
+		})
+
+		It("Retrieves no values for a block that cannot be found", func() {
+			values, err := retriever.RetrieveStorageAtByAddressAndStorageSlotsAndBlockHash(test_helpers.ContractAddress, []common.Hash{common.HexToHash("0")}, common.HexToHash("0x01"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(BeEmpty())
+		})
+	})
+
+	Describe("RetrieveCodeByCodeHash", func() {
+		It("Retrieves the code with the provided code hash", func() {
+			code, err := retriever.RetrieveCodeByCodeHash(test_helpers.ContractCodeHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(test_helpers.ContractCode))
+		})
+
+		It("Retrieves empty code for the empty code hash", func() {
+			code, err := retriever.RetrieveCodeByCodeHash(test_helpers.AccountCodeHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(BeEmpty())
+		})
+
+		It("Returns sql.ErrNoRows for code that cannot be found", func() {
+			_, err := retriever.RetrieveCodeByCodeHash(common.HexToHash("0x01"))
+			Expect(err).To(Equal(sql.ErrNoRows))
+		})
+	})
+
+	Describe("RetrieveCodeByAddressAndBlockHash", func() {
+		It("Retrieves the code of the contract deployed at the address as of the block", func() {
+			code, err := retriever.RetrieveCodeByAddressAndBlockHash(test_helpers.ContractAddress, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(test_helpers.ContractCode))
+		})
+
+		It("Retrieves empty code for an account without code", func() {
+			code, err := retriever.RetrieveCodeByAddressAndBlockHash(test_helpers.AccountAddresss, test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(BeEmpty())
+		})
+	})
+
+	Describe("RetrieveTxHashesByBlockHash", func() {
+		It("Retrieves the hashes of the block's transactions in tx index order", func() {
+			hashes, err := retriever.RetrieveTxHashesByBlockHash(test_helpers.MockBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(hashes)).To(Equal(len(test_helpers.MockTransactions)))
+			for i, trx := range test_helpers.MockTransactions {
+				Expect(hashes[i]).To(Equal(trx.Hash()))
+			}
+		})
+
+		It("Retrieves no hashes for a block that cannot be found", func() {
+			hashes, err := retriever.RetrieveTxHashesByBlockHash(common.HexToHash("0x01"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hashes).To(BeEmpty())
+		})
+	})
+
+	Describe("RetrieveReceiptsByBlockNumber", func() {
+		It("Retrieves the receipts of the canonical block in tx index order when a sibling exists", func() {
+			// a non-canonical sibling, indexed with a lower total difficulty, holding a subset of the txs
+			header := test_helpers.NewOrphanSibling(test_helpers.MockBlock.Header(), "sibling")
+			siblingTxs := types.Transactions{test_helpers.MockTransactions[3], test_helpers.MockTransactions[0]}
+			siblingRcts := types.Receipts{test_helpers.MockReceipts[3], test_helpers.MockReceipts[0]}
+			sibling := types.NewBlock(header, siblingTxs, nil, siblingRcts, new(trie.Trie))
+			indexer := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(sibling, siblingRcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			cids, rcts, err := retriever.RetrieveReceiptsByBlockNumber(test_helpers.MockBlock.NumberU64())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(cids)).To(Equal(len(test_helpers.MockReceipts)))
+			Expect(len(rcts)).To(Equal(len(test_helpers.MockReceipts)))
+			for i, rct := range rcts {
+				Expect(rct).To(Equal(test_helpers.GetRctRlp(i, test_helpers.MockReceipts)))
+			}
+		})
+	})
+
+	Describe("RetrieveReceiptsByBlockNumberFast", func() {
+		It("Retrieves the same receipts as RetrieveReceiptsByBlockNumber when a sibling exists", func() {
+			header := test_helpers.NewOrphanSibling(test_helpers.MockBlock.Header(), "sibling")
+			siblingTxs := types.Transactions{test_helpers.MockTransactions[3], test_helpers.MockTransactions[0]}
+			siblingRcts := types.Receipts{test_helpers.MockReceipts[3], test_helpers.MockReceipts[0]}
+			sibling := types.NewBlock(header, siblingTxs, nil, siblingRcts, new(trie.Trie))
+			indexer := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(sibling, siblingRcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			cids, rcts, err := retriever.RetrieveReceiptsByBlockNumberFast(test_helpers.MockBlock.NumberU64())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(rcts)).To(Equal(len(test_helpers.MockReceipts)))
+			for i, rct := range rcts {
+				Expect(rct).To(Equal(test_helpers.GetRctRlp(i, test_helpers.MockReceipts)))
+			}
+
+			expectedCIDs, expectedRcts, err := retriever.RetrieveReceiptsByBlockNumber(test_helpers.MockBlock.NumberU64())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cids).To(Equal(expectedCIDs))
+			Expect(rcts).To(Equal(expectedRcts))
+		})
+
+		It("Retrieves no receipts for a block number which is not indexed", func() {
+			cids, rcts, err := retriever.RetrieveReceiptsByBlockNumberFast(test_helpers.MockBlock.NumberU64() + 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cids).To(BeEmpty())
+			Expect(rcts).To(BeEmpty())
+		})
+	})
+	Describe("RetrieveStorageSlotHistory", func() {
+		BeforeEach(func() {
+			// the contract account, whose storage slot is set in MockBlock, is removed in its child
+			indexer := shared.SetupTestStateDiffIndexer(ctx, params.TestChainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(test_helpers.MockChild, test_helpers.MockReceipts, test_helpers.MockChild.Difficulty())
+			Expect(err).ToNot(HaveOccurred())
+			err = indexer.PushStateNode(tx, sdtypes.StateNode{
+				LeafKey:   test_helpers.ContractLeafKey,
+				Path:      []byte{'\x06'},
+				NodeType:  sdtypes.Removed,
+				NodeValue: []byte{},
+			}, test_helpers.MockChild.Hash().String())
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Includes the removal of the contract account as clearing a slot set before it", func() {
+			number := test_helpers.MockChild.Number().Int64()
+			history, err := retriever.RetrieveStorageSlotHistory(test_helpers.ContractAddress, common.Hash{}, number, number)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(history).To(Equal([]eth.StorageSlotChange{{BlockNumber: number, Value: []byte{}}}))
+		})
+
+		It("Does not include the removal of the contract account for a slot that was never set", func() {
+			number := test_helpers.MockChild.Number().Int64()
+			history, err := retriever.RetrieveStorageSlotHistory(test_helpers.ContractAddress, common.HexToHash("0x01"), number, number)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(history).To(BeEmpty())
+		})
+	})
+})
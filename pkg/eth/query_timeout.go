@@ -0,0 +1,63 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQueryTimeout is returned when a retriever query runs longer than the configured query timeout
+var ErrQueryTimeout = errors.New("retriever query timed out")
+
+// contextQueryer is satisfied by both *sqlx.DB and *sqlx.Tx
+type contextQueryer interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// queryContext returns the context a retriever query is run with; a timeout of 0 disables the timeout
+func queryContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// queryErr replaces the error of a query that was cancelled by its timeout with ErrQueryTimeout
+func queryErr(ctx context.Context, timeout time.Duration, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrQueryTimeout, timeout)
+	}
+	return err
+}
+
+// getWithTimeout runs a single row query, bounded by the provided timeout
+func getWithTimeout(timeout time.Duration, q contextQueryer, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := queryContext(timeout)
+	defer cancel()
+	return queryErr(ctx, timeout, q.GetContext(ctx, dest, query, args...))
+}
+
+// selectWithTimeout runs a multi row query, bounded by the provided timeout
+func selectWithTimeout(timeout time.Duration, q contextQueryer, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := queryContext(timeout)
+	defer cancel()
+	return queryErr(ctx, timeout, q.SelectContext(ctx, dest, query, args...))
+}
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/spf13/viper"
@@ -33,6 +34,9 @@ type SubscriptionSettings struct {
 	ReceiptFilter ReceiptFilter
 	StateFilter   StateFilter
 	StorageFilter StorageFilter
+	// SlowConsumerPolicy is how the subscription is handled once its channel is full, "block" or "close";
+	// the server's policy applies if it is empty
+	SlowConsumerPolicy string
 }
 
 // HeaderFilter contains filter settings for headers
@@ -43,9 +47,10 @@ type HeaderFilter struct {
 
 // TxFilter contains filter settings for txs
 type TxFilter struct {
-	Off bool
-	Src []string
-	Dst []string
+	Off   bool
+	Src   []string
+	Dst   []string
+	Types []uint8 // EIP-2718 transaction types, e.g. 0 for legacy and 2 for dynamic fee transactions
 }
 
 // ReceiptFilter contains filter settings for receipts
@@ -87,12 +92,20 @@ func NewEthSubscriptionConfig() (*SubscriptionSettings, error) {
 		Off:    viper.GetBool("watcher.ethSubscription.headerFilter.off"),
 		Uncles: viper.GetBool("watcher.ethSubscription.headerFilter.uncles"),
 	}
-	// Below defaults to false and two slices of length 0
+	// Below defaults to false and three slices of length 0
 	// Which means we get all transactions by default
+	txTypes := viper.GetIntSlice("watcher.ethSubscription.txFilter.types")
 	sc.TxFilter = TxFilter{
-		Off: viper.GetBool("watcher.ethSubscription.txFilter.off"),
-		Src: viper.GetStringSlice("watcher.ethSubscription.txFilter.src"),
-		Dst: viper.GetStringSlice("watcher.ethSubscription.txFilter.dst"),
+		Off:   viper.GetBool("watcher.ethSubscription.txFilter.off"),
+		Src:   viper.GetStringSlice("watcher.ethSubscription.txFilter.src"),
+		Dst:   viper.GetStringSlice("watcher.ethSubscription.txFilter.dst"),
+		Types: make([]uint8, 0, len(txTypes)),
+	}
+	for _, txType := range txTypes {
+		if txType < 0 || txType > 0x7f {
+			return nil, fmt.Errorf("invalid transaction type %d in tx filter", txType)
+		}
+		sc.TxFilter.Types = append(sc.TxFilter.Types, uint8(txType))
 	}
 	// By default all of the topic slices will be empty => match on any/all topics
 	topics := make([][]string, 4)
@@ -121,5 +134,6 @@ func NewEthSubscriptionConfig() (*SubscriptionSettings, error) {
 		Addresses:         viper.GetStringSlice("watcher.ethSubscription.storageFilter.addresses"),
 		StorageKeys:       viper.GetStringSlice("watcher.ethSubscription.storageFilter.storageKeys"),
 	}
+	sc.SlowConsumerPolicy = viper.GetString("watcher.ethSubscription.slowConsumerPolicy")
 	return sc, nil
 }
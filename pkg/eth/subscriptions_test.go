@@ -0,0 +1,124 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+)
+
+// mockPendingTxsAPI is a proxy node eth API emitting a fixed set of pending tx hashes to each subscriber
+type mockPendingTxsAPI struct {
+	txHashes []common.Hash
+}
+
+func (api *mockPendingTxsAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		for _, txHash := range api.txHashes {
+			if err := notifier.Notify(rpcSub.ID, txHash); err != nil {
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+var _ = Describe("eth_subscribe newPendingTransactions", func() {
+	var (
+		ctx      = context.Background()
+		txHashes = []common.Hash{
+			common.HexToHash("0x01"),
+			common.HexToHash("0x02"),
+			common.HexToHash("0x03"),
+		}
+		proxyServer *rpc.Server
+		server      *rpc.Server
+		client      *rpc.Client
+	)
+
+	newServer := func(proxy *rpc.Client) *rpc.Server {
+		api, err := eth.NewPublicEthAPI(&eth.Backend{}, proxy, eth.APIConfig{})
+		Expect(err).ToNot(HaveOccurred())
+		s := rpc.NewServer()
+		err = s.RegisterName(eth.APIName, api)
+		Expect(err).ToNot(HaveOccurred())
+		return s
+	}
+
+	BeforeEach(func() {
+		proxyServer = rpc.NewServer()
+		err := proxyServer.RegisterName(eth.APIName, &mockPendingTxsAPI{txHashes: txHashes})
+		Expect(err).ToNot(HaveOccurred())
+
+		server = newServer(rpc.DialInProc(proxyServer))
+		client = rpc.DialInProc(server)
+	})
+	AfterEach(func() {
+		client.Close()
+		server.Stop()
+		proxyServer.Stop()
+	})
+
+	It("Relays the pending tx hashes of the proxy node", func() {
+		ch := make(chan common.Hash)
+		sub, err := client.EthSubscribe(ctx, ch, "newPendingTransactions")
+		Expect(err).ToNot(HaveOccurred())
+		defer sub.Unsubscribe()
+
+		for _, txHash := range txHashes {
+			Eventually(ch, time.Second).Should(Receive(Equal(txHash)))
+		}
+	})
+
+	It("Stops relaying once the proxy node disconnects", func() {
+		ch := make(chan common.Hash)
+		sub, err := client.EthSubscribe(ctx, ch, "newPendingTransactions")
+		Expect(err).ToNot(HaveOccurred())
+		defer sub.Unsubscribe()
+		for range txHashes {
+			Eventually(ch, time.Second).Should(Receive())
+		}
+
+		proxyServer.Stop()
+		Consistently(ch, 100*time.Millisecond).ShouldNot(Receive())
+		Consistently(sub.Err(), 100*time.Millisecond).ShouldNot(Receive())
+
+		_, err = client.EthSubscribe(ctx, make(chan common.Hash), "newPendingTransactions")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rejects subscriptions when no proxy node is configured", func() {
+		noProxyClient := rpc.DialInProc(newServer(nil))
+		defer noProxyClient.Close()
+
+		_, err := noProxyClient.EthSubscribe(ctx, make(chan common.Hash), "newPendingTransactions")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("require a proxy node"))
+	})
+})
@@ -630,6 +630,15 @@ func createNewBlock(header *types.Header, txs []*types.Transaction, uncles []*ty
 	return block
 }
 
+// NewOrphanSibling returns a copy of the header with the provided extra data, to tell the two apart, and the lowest
+// difficulty, so that the block it heads is orphaned by the canonical block with the header at the same height
+func NewOrphanSibling(header *types.Header, extra string) *types.Header {
+	sibling := types.CopyHeader(header)
+	sibling.Difficulty = big.NewInt(1)
+	sibling.Extra = []byte(extra)
+	return sibling
+}
+
 // createDynamicTransactionsAndReceipts is a helper function to generate signed mock transactions and mock receipts with mock logs
 func createDynamicTransactionsAndReceipts(blockNumber *big.Int) (types.Transactions, types.Receipts, common.Address) {
 	// make transactions
@@ -739,7 +748,13 @@ func createLegacyTransactionsAndReceipts() (types.Transactions, types.Receipts,
 	mockReceipt4.TxHash = signedTrx4.Hash()
 	mockReceipt4.GasUsed = mockReceipt4.CumulativeGasUsed - mockReceipt3.CumulativeGasUsed
 
-	return types.Transactions{signedTrx1, signedTrx2, signedTrx3, signedTrx4}, types.Receipts{mockReceipt1, mockReceipt2, mockReceipt3, mockReceipt4}, SenderAddr
+	// set the logs blooms, as real receipts carry them in their consensus encoding
+	receipts := types.Receipts{mockReceipt1, mockReceipt2, mockReceipt3, mockReceipt4}
+	for _, rct := range receipts {
+		rct.Bloom = types.CreateBloom(types.Receipts{rct})
+	}
+
+	return types.Transactions{signedTrx1, signedTrx2, signedTrx3, signedTrx4}, receipts, SenderAddr
 }
 
 func GetTxnRlp(num int, txs types.Transactions) []byte {
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -27,6 +28,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/statediff/indexer/models"
 	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
 )
@@ -67,8 +70,8 @@ type RPCReceipt struct {
 	ContractAddress  *common.Address `json:"contractAddress"`
 	Logs             []*types.Log    `json:"logs"`
 	Bloom            types.Bloom     `json:"logsBloom"`
-	Root             []byte          `json:"root"`
-	Status           uint64          `json:"status"`
+	Root             hexutil.Bytes   `json:"root,omitempty"`
+	Status           *hexutil.Uint64 `json:"status,omitempty"`
 }
 
 // AccountResult struct for GetProof
@@ -89,6 +92,28 @@ type StorageResult struct {
 	Proof []string     `json:"proof"`
 }
 
+// AccountStateResult for GetAccountState
+type AccountStateResult struct {
+	Address  common.Address       `json:"address"`
+	Balance  *hexutil.Big         `json:"balance"`
+	Nonce    hexutil.Uint64       `json:"nonce"`
+	CodeHash common.Hash          `json:"codeHash"`
+	Code     hexutil.Bytes        `json:"code,omitempty"`
+	Storage  []StorageValueResult `json:"storage"`
+}
+
+// StorageValueResult for GetAccountState
+type StorageValueResult struct {
+	Key   string        `json:"key"`
+	Value hexutil.Bytes `json:"value"`
+}
+
+// AccountLeafResult holds the cid of an account's state leaf node and the rlp encoded account it contains
+type AccountLeafResult struct {
+	CID  string
+	Data []byte
+}
+
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
 	From                 *common.Address   `json:"from"`
@@ -247,6 +272,53 @@ type ConvertedPayload struct {
 	StorageNodes    map[string][]sdtypes.StorageNode
 }
 
+// GasPriceStats holds the effective gas price statistics of the canonical transactions in a block range
+type GasPriceStats struct {
+	Count      int
+	Average    *big.Int
+	Percentile *big.Int
+}
+
+// GasUsedRatio is the ratio of the gas used to the gas limit of a canonical block
+type GasUsedRatio struct {
+	BlockNumber int64
+	Ratio       float64
+}
+
+// HeaderCursor is the position of a header in a listing ordered by block number and then block hash
+type HeaderCursor struct {
+	BlockNumber int64
+	BlockHash   string
+}
+
+// BlockRange is an inclusive range of block numbers
+type BlockRange struct {
+	From int64
+	To   int64
+}
+
+// BlockHashResult is the hash of a header indexed at a height, flagged if it is the canonical header at that height
+type BlockHashResult struct {
+	BlockHash string `db:"block_hash"`
+	Canonical bool   `db:"canonical"`
+}
+
+// TxResult locates a canonical transaction by its block and index, with its sender and recipient
+type TxResult struct {
+	TxHash      string `db:"tx_hash"`
+	BlockHash   string `db:"header_id"`
+	BlockNumber int64  `db:"block_number"`
+	Index       int64  `db:"index"`
+	Src         string `db:"src"`
+	Dst         string `db:"dst"`
+}
+
+// StorageSlotChange is the value a storage slot was set to at a block; the value is empty if the slot was cleared
+type StorageSlotChange struct {
+	BlockNumber int64
+	Value       []byte
+}
+
 // LogResult represent a log.
 type LogResult struct {
 	LeafCID     string `db:"leaf_cid"`
@@ -265,6 +337,53 @@ type LogResult struct {
 	BlockHash   string `db:"block_hash"`
 	TxnIndex    int64  `db:"txn_index"`
 	TxHash      string `db:"tx_hash"`
+	Removed     bool   `db:"removed"`
+}
+
+// LogFilterCriteria are the eth_getLogs filter criteria, extended with a list of block hashes to retrieve the logs of
+// along with optional minBlock and maxBlock bounds on the numbers of the listed blocks.
+// The list is mutually exclusive with the blockHash and the fromBlock/toBlock criteria, and the bounds require it
+type LogFilterCriteria struct {
+	filters.FilterCriteria
+	BlockHashes []common.Hash
+	MinBlock    *big.Int
+	MaxBlock    *big.Int
+}
+
+// UnmarshalJSON sets the criteria from their JSON representation
+func (crit *LogFilterCriteria) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		BlockHash   *common.Hash     `json:"blockHash"`
+		FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+		ToBlock     *rpc.BlockNumber `json:"toBlock"`
+		BlockHashes []common.Hash    `json:"blockHashes"`
+		MinBlock    *hexutil.Uint64  `json:"minBlock"`
+		MaxBlock    *hexutil.Uint64  `json:"maxBlock"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := crit.FilterCriteria.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	if raw.BlockHashes == nil {
+		if raw.MinBlock != nil || raw.MaxBlock != nil {
+			return errors.New("minBlock and maxBlock can only be specified along with blockHashes")
+		}
+		return nil
+	}
+	if raw.BlockHash != nil || raw.FromBlock != nil || raw.ToBlock != nil {
+		return errors.New("cannot specify both blockHashes and blockHash or fromBlock/toBlock, choose one or the other")
+	}
+	crit.BlockHashes = raw.BlockHashes
+	if raw.MinBlock != nil {
+		crit.MinBlock = new(big.Int).SetUint64(uint64(*raw.MinBlock))
+	}
+	if raw.MaxBlock != nil {
+		crit.MaxBlock = new(big.Int).SetUint64(uint64(*raw.MaxBlock))
+	}
+	return nil
 }
 
 // GetSliceResponse holds response for the eth_getSlice method
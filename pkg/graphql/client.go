@@ -21,6 +21,15 @@ type GetStorageAt struct {
 	Response StorageResponse `json:"getStorageAt"`
 }
 
+type StateLeafResponse struct {
+	CID       string        `json:"cid"`
+	IpldBlock hexutil.Bytes `json:"ipldBlock"`
+}
+
+type GetStateLeaf struct {
+	Response *StateLeafResponse `json:"stateLeaf"`
+}
+
 type LogResponse struct {
 	Topics      []common.Hash       `json:"topics"`
 	Data        hexutil.Bytes       `json:"data"`
@@ -37,6 +46,37 @@ type GetLogs struct {
 	Responses []LogResponse `json:"getLogs"`
 }
 
+type LogsPageResponse struct {
+	Logs      []LogResponse `json:"logs"`
+	Truncated bool          `json:"truncated"`
+	NextBlock *hexutil.Big  `json:"nextBlock"`
+}
+
+type GetLogsPage struct {
+	Response LogsPageResponse `json:"getLogsPage"`
+}
+
+type BlockLogsResponse struct {
+	Number hexutil.Uint64 `json:"number"`
+	Logs   []LogResponse  `json:"logs"`
+}
+
+type FilteredLogs struct {
+	Responses []LogResponse `json:"logs"`
+}
+
+type BlocksLogs struct {
+	Responses []BlockLogsResponse `json:"blocks"`
+}
+
+type BlockNumberResponse struct {
+	Number hexutil.Uint64 `json:"number"`
+}
+
+type BlockNumbers struct {
+	Responses []BlockNumberResponse `json:"blocks"`
+}
+
 type IPFSBlockResponse struct {
 	Key  string `json:"key"`
 	Data string `json:"data"`
@@ -49,6 +89,7 @@ type EthTransactionCIDResponse struct {
 	Src          string            `json:"src"`
 	Dst          string            `json:"dst"`
 	BlockByMhKey IPFSBlockResponse `json:"blockByMhKey"`
+	Data         *hexutil.Bytes    `json:"data"`
 }
 
 type EthTransactionCIDByTxHash struct {
@@ -59,6 +100,109 @@ type EthTransactionCIDsByHeaderIdResponse struct {
 	Nodes []EthTransactionCIDResponse `json:"nodes"`
 }
 
+type EthTransactionCIDsByTxHashes struct {
+	Response EthTransactionCIDsByHeaderIdResponse `json:"ethTransactionCidsByTxHashes"`
+}
+
+type TransactionByAddressResponse struct {
+	Hash  common.Hash `json:"hash"`
+	Index int32       `json:"index"`
+	Block struct {
+		Number hexutil.Uint64 `json:"number"`
+	} `json:"block"`
+}
+
+type TransactionsByAddress struct {
+	Responses []TransactionByAddressResponse `json:"transactionsByAddress"`
+}
+
+type BlockNumberByHash struct {
+	Number *hexutil.Uint64 `json:"blockNumberByHash"`
+}
+
+type ContractDeploymentBlock struct {
+	Number *hexutil.Uint64 `json:"contractDeploymentBlock"`
+}
+
+type EventSignaturesResponse struct {
+	EventSignatures []common.Hash `json:"eventSignatures"`
+}
+
+type BlockRangeResponse struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+type GetLogBlockRange struct {
+	Response *BlockRangeResponse `json:"logBlockRange"`
+}
+
+type TransferResponse struct {
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Value    *hexutil.Big   `json:"value"`
+	TokenId  *hexutil.Big   `json:"tokenId"`
+	TxHash   common.Hash    `json:"txHash"`
+	LogIndex hexutil.Uint64 `json:"logIndex"`
+}
+
+type GetTransfers struct {
+	Responses []TransferResponse `json:"transfers"`
+}
+
+type StorageSlotChangeResponse struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Value       common.Hash    `json:"value"`
+}
+
+type GetStorageSlotHistory struct {
+	Responses []StorageSlotChangeResponse `json:"storageSlotHistory"`
+}
+
+type GasPriceStatsResponse struct {
+	Count      hexutil.Uint64 `json:"count"`
+	Average    *hexutil.Big   `json:"average"`
+	Percentile *hexutil.Big   `json:"percentile"`
+}
+
+type GasUsedRatioResponse struct {
+	Number hexutil.Uint64 `json:"number"`
+	Ratio  float64        `json:"ratio"`
+}
+
+type GetGasUsedRatios struct {
+	Responses []GasUsedRatioResponse `json:"gasUsedRatios"`
+}
+
+type GetGasPriceStats struct {
+	Response GasPriceStatsResponse `json:"gasPriceStats"`
+}
+
+type BlockHashAtResponse struct {
+	Hash      common.Hash `json:"hash"`
+	Canonical bool        `json:"canonical"`
+}
+
+type GetBlockHashesAt struct {
+	Responses []BlockHashAtResponse `json:"blockHashesAt"`
+}
+
+type StorageChangeBlocksResponse struct {
+	StorageChangeBlocks []hexutil.Uint64 `json:"storageChangeBlocks"`
+}
+
+type RemovedAccountsResponse struct {
+	RemovedAccounts []common.Hash `json:"removedAccounts"`
+}
+
+type ActiveAddressCountResponse struct {
+	ActiveAddressCount hexutil.Uint64 `json:"activeAddressCount"`
+}
+
+type CodeChunkResponse struct {
+	CodeChunk hexutil.Bytes `json:"codeChunk"`
+}
+
 type EthHeaderCIDResponse struct {
 	CID                          string                               `json:"cid"`
 	BlockNumber                  BigInt                               `json:"blockNumber"`
@@ -71,18 +215,245 @@ type EthHeaderCIDResponse struct {
 	ReceiptRoot                  string                               `json:"receiptRoot"`
 	UncleRoot                    string                               `json:"uncleRoot"`
 	Bloom                        string                               `json:"bloom"`
+	Coinbase                     string                               `json:"coinbase"`
+	NodeID                       string                               `json:"nodeId"`
 	EthTransactionCIDsByHeaderId EthTransactionCIDsByHeaderIdResponse `json:"ethTransactionCidsByHeaderId"`
 	BlockByMhKey                 IPFSBlockResponse                    `json:"blockByMhKey"`
 }
 
+type PageInfoResponse struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
 type AllEthHeaderCIDsResponse struct {
-	Nodes []EthHeaderCIDResponse `json:"nodes"`
+	Nodes    []EthHeaderCIDResponse `json:"nodes"`
+	PageInfo PageInfoResponse       `json:"pageInfo"`
 }
 
 type AllEthHeaderCIDs struct {
 	Response AllEthHeaderCIDsResponse `json:"allEthHeaderCids"`
 }
 
+type TransactionGasUsedResponse struct {
+	CumulativeGasUsed    hexutil.Uint64 `json:"cumulativeGasUsed"`
+	GasUsedByTransaction hexutil.Uint64 `json:"gasUsedByTransaction"`
+}
+
+type BlockGasUsedResponse struct {
+	GasUsed      hexutil.Uint64               `json:"gasUsed"`
+	Transactions []TransactionGasUsedResponse `json:"transactions"`
+}
+
+type BlockGasUsed struct {
+	Response BlockGasUsedResponse `json:"block"`
+}
+
+type OmmerResponse struct {
+	Hash             common.Hash           `json:"hash"`
+	TransactionCount *int32                `json:"transactionCount"`
+	Transactions     []TransactionResponse `json:"transactions"`
+	Logs             []LogResponse         `json:"logs"`
+}
+
+type BlockOmmersResponse struct {
+	Ommers []OmmerResponse `json:"ommers"`
+}
+
+type BlockOmmers struct {
+	Response BlockOmmersResponse `json:"block"`
+}
+
+type BlockTransactionHashesResponse struct {
+	TransactionHashes []common.Hash `json:"transactionHashes"`
+}
+
+type BlockTransactionHashes struct {
+	Response BlockTransactionHashesResponse `json:"block"`
+}
+
+type BlockContractsCreatedCountResponse struct {
+	ContractsCreatedCount *hexutil.Uint64 `json:"contractsCreatedCount"`
+}
+
+type BlockContractsCreatedCount struct {
+	Response BlockContractsCreatedCountResponse `json:"block"`
+}
+
+type TransactionRawResponse struct {
+	Raw        hexutil.Bytes `json:"raw"`
+	RawReceipt hexutil.Bytes `json:"rawReceipt"`
+}
+
+type TransactionRaw struct {
+	Response TransactionRawResponse `json:"transaction"`
+}
+
+type TransactionMethodSelectorResponse struct {
+	MethodSelector *string `json:"methodSelector"`
+}
+
+type TransactionMethodSelector struct {
+	Response TransactionMethodSelectorResponse `json:"transaction"`
+}
+
+type BlockLogCountResponse struct {
+	LogCount *hexutil.Uint64 `json:"logCount"`
+}
+
+type BlockLogCount struct {
+	Response BlockLogCountResponse `json:"block"`
+}
+
+type BlockSizeResponse struct {
+	Size hexutil.Uint64 `json:"size"`
+}
+
+type BlockSize struct {
+	Response BlockSizeResponse `json:"block"`
+}
+
+type CliqueExtraDataResponse struct {
+	Vanity  hexutil.Bytes    `json:"vanity"`
+	Signers []common.Address `json:"signers"`
+	Seal    hexutil.Bytes    `json:"seal"`
+	Sealer  *common.Address  `json:"sealer"`
+}
+
+type BlockCliqueSignersResponse struct {
+	CliqueSigners *CliqueExtraDataResponse `json:"cliqueSigners"`
+}
+
+type BlockCliqueSigners struct {
+	Response BlockCliqueSignersResponse `json:"block"`
+}
+
+type BlockByTagResponse struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+type BlockByTag struct {
+	Response BlockByTagResponse `json:"block"`
+}
+
+type TransactionEffectiveTipResponse struct {
+	Hash         common.Hash  `json:"hash"`
+	EffectiveTip *hexutil.Big `json:"effectiveTip"`
+}
+
+type BlockEffectiveTipsResponse struct {
+	Transactions []TransactionEffectiveTipResponse `json:"transactions"`
+}
+
+type BlockEffectiveTips struct {
+	Response BlockEffectiveTipsResponse `json:"block"`
+}
+
+type AccountAddressResponse struct {
+	Address common.Address `json:"address"`
+}
+
+type TransactionFromResponse struct {
+	Hash common.Hash            `json:"hash"`
+	From AccountAddressResponse `json:"from"`
+}
+
+type BlockTransactionSendersResponse struct {
+	Transactions []TransactionFromResponse `json:"transactions"`
+}
+
+type BlockTransactionSenders struct {
+	Response BlockTransactionSendersResponse `json:"block"`
+}
+
+type AccessTupleResponse struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+type TransactionAccessListResponse struct {
+	Hash       common.Hash           `json:"hash"`
+	AccessList []AccessTupleResponse `json:"accessList"`
+}
+
+type BlockAccessListsResponse struct {
+	Transactions []TransactionAccessListResponse `json:"transactions"`
+}
+
+type BlockAccessLists struct {
+	Response BlockAccessListsResponse `json:"block"`
+}
+
+type TransactionSignatureResponse struct {
+	R       hexutil.Big     `json:"r"`
+	S       hexutil.Big     `json:"s"`
+	V       hexutil.Big     `json:"v"`
+	YParity *hexutil.Uint64 `json:"yParity"`
+}
+
+type TransactionWithSignatureResponse struct {
+	Hash      common.Hash                  `json:"hash"`
+	Signature TransactionSignatureResponse `json:"signature"`
+}
+
+type BlockTransactionSignaturesResponse struct {
+	Transactions []TransactionWithSignatureResponse `json:"transactions"`
+}
+
+type BlockTransactionSignatures struct {
+	Response BlockTransactionSignaturesResponse `json:"block"`
+}
+
+type TransactionChainIDResponse struct {
+	Hash    common.Hash     `json:"hash"`
+	YParity *hexutil.Uint64 `json:"yParity"`
+	ChainID *hexutil.Big    `json:"chainID"`
+}
+
+type BlockTransactionChainIDsResponse struct {
+	Transactions []TransactionChainIDResponse `json:"transactions"`
+}
+
+type BlockTransactionChainIDs struct {
+	Response BlockTransactionChainIDsResponse `json:"block"`
+}
+
+type TransactionLogTopicsResponse struct {
+	Topics []common.Hash `json:"topics"`
+}
+
+type TransactionReceiptBloomResponse struct {
+	Hash         common.Hash                    `json:"hash"`
+	ReceiptBloom hexutil.Bytes                  `json:"receiptBloom"`
+	Logs         []TransactionLogTopicsResponse `json:"logs"`
+}
+
+type BlockReceiptBloomsResponse struct {
+	Transactions []TransactionReceiptBloomResponse `json:"transactions"`
+}
+
+type BlockReceiptBlooms struct {
+	Response BlockReceiptBloomsResponse `json:"block"`
+}
+
+type BlockMixHashResponse struct {
+	MixHash    common.Hash  `json:"mixHash"`
+	PrevRandao *common.Hash `json:"prevRandao"`
+}
+
+type BlockMixHash struct {
+	Response BlockMixHashResponse `json:"block"`
+}
+
+type BlockBaseFeeResponse struct {
+	BaseFeePerGas *hexutil.Big `json:"baseFeePerGas"`
+}
+
+type BlockBaseFee struct {
+	Response BlockBaseFeeResponse `json:"block"`
+}
+
 type Client struct {
 	client *gqlclient.Client
 }
@@ -93,7 +464,19 @@ func NewClient(endpoint string) *Client {
 }
 
 func (c *Client) GetLogs(ctx context.Context, hash common.Hash, addresses []common.Address) ([]LogResponse, error) {
-	params := fmt.Sprintf(`blockHash: "%s"`, hash.String())
+	return c.GetTopicFilteredLogs(ctx, hash, addresses, nil)
+}
+
+func (c *Client) GetTopicFilteredLogs(ctx context.Context, hash common.Hash, addresses []common.Address, topics [][]common.Hash) ([]LogResponse, error) {
+	return c.getLogs(ctx, fmt.Sprintf(`blockHash: "%s"`, hash.String()), addresses, topics)
+}
+
+// GetRangeLogs gets the logs in the block range (inclusive)
+func (c *Client) GetRangeLogs(ctx context.Context, from, to uint64, addresses []common.Address) ([]LogResponse, error) {
+	return c.getLogs(ctx, fmt.Sprintf(`fromBlock: "%d", toBlock: "%d"`, from, to), addresses, nil)
+}
+
+func (c *Client) getLogs(ctx context.Context, params string, addresses []common.Address, topics [][]common.Hash) ([]LogResponse, error) {
 
 	if addresses != nil {
 		addressStrings := make([]string, len(addresses))
@@ -104,6 +487,15 @@ func (c *Client) GetLogs(ctx context.Context, hash common.Hash, addresses []comm
 		params += fmt.Sprintf(`, addresses: [%s]`, strings.Join(addressStrings, ","))
 	}
 
+	if topics != nil {
+		topicsJSON, err := json.Marshal(topics)
+		if err != nil {
+			return nil, err
+		}
+
+		params += fmt.Sprintf(`, topics: %s`, topicsJSON)
+	}
+
 	getLogsQuery := fmt.Sprintf(`query{
 			getLogs(%s) {
 				data
@@ -138,18 +530,25 @@ func (c *Client) GetLogs(ctx context.Context, hash common.Hash, addresses []comm
 	return logs.Responses, nil
 }
 
-func (c *Client) GetStorageAt(ctx context.Context, hash common.Hash, address common.Address, slot string) (*StorageResponse, error) {
-	getLogsQuery := fmt.Sprintf(`
-		query{
-			getStorageAt(blockHash: "%s", contract: "%s",slot: "%s") {
-				cid
-				value
-				ipldBlock
+// GetRangeLogsPage gets the logs in the block range (inclusive), truncated to the server's cap on the results of a query
+func (c *Client) GetRangeLogsPage(ctx context.Context, from, to uint64) (*LogsPageResponse, error) {
+	getLogsPageQuery := fmt.Sprintf(`query{
+			getLogsPage(fromBlock: "%d", toBlock: "%d") {
+				logs {
+					data
+					topics
+					transaction {
+						hash
+					}
+					status
+					receiptCID
+				}
+				truncated
+				nextBlock
 			}
-		}
-	`, hash.String(), address.String(), common.HexToHash(slot))
+		}`, from, to)
 
-	req := gqlclient.NewRequest(getLogsQuery)
+	req := gqlclient.NewRequest(getLogsPageQuery)
 	req.Header.Set("Cache-Control", "no-cache")
 
 	var respData map[string]interface{}
@@ -163,61 +562,35 @@ func (c *Client) GetStorageAt(ctx context.Context, hash common.Hash, address com
 		return nil, err
 	}
 
-	var storageAt GetStorageAt
-	err = json.Unmarshal(jsonStr, &storageAt)
+	var page GetLogsPage
+	err = json.Unmarshal(jsonStr, &page)
 	if err != nil {
 		return nil, err
 	}
-	return &storageAt.Response, nil
+	return &page.Response, nil
 }
 
-func (c *Client) AllEthHeaderCIDs(ctx context.Context, condition EthHeaderCIDCondition) (*AllEthHeaderCIDsResponse, error) {
-	var params string
-	if condition.BlockHash != nil {
-		params = fmt.Sprintf(`blockHash: "%s"`, *condition.BlockHash)
-	}
-	if condition.BlockNumber != nil {
-		params += fmt.Sprintf(`blockNumber: "%s"`, condition.BlockNumber.String())
+func (c *Client) GetFilteredLogs(ctx context.Context, from, to uint64, topics [][]common.Hash) ([]LogResponse, error) {
+	topicsJSON, err := json.Marshal(topics)
+	if err != nil {
+		return nil, err
 	}
 
-	getHeadersQuery := fmt.Sprintf(`
-		query{
-			allEthHeaderCids(condition: { %s }) {
-				nodes {
-					cid
-					blockNumber
-					blockHash
-					parentHash
-					timestamp
-					stateRoot
-					td
-					txRoot
-					receiptRoot
-					uncleRoot
-					bloom
-					blockByMhKey {
-						key
-						data
-					}
-					ethTransactionCidsByHeaderId {
-						nodes {
-							cid
-							txHash
-							index
-							src
-							dst
-						}
-					}
+	getFilteredLogsQuery := fmt.Sprintf(`query{
+			logs(filter: {fromBlock: %d, toBlock: %d, topics: %s}) {
+				data
+				topics
+				transaction {
+					hash
 				}
 			}
-		}
-	`, params)
+		}`, from, to, topicsJSON)
 
-	req := gqlclient.NewRequest(getHeadersQuery)
+	req := gqlclient.NewRequest(getFilteredLogsQuery)
 	req.Header.Set("Cache-Control", "no-cache")
 
 	var respData map[string]interface{}
-	err := c.client.Run(ctx, req, &respData)
+	err = c.client.Run(ctx, req, &respData)
 	if err != nil {
 		return nil, err
 	}
@@ -227,31 +600,29 @@ func (c *Client) AllEthHeaderCIDs(ctx context.Context, condition EthHeaderCIDCon
 		return nil, err
 	}
 
-	var allEthHeaderCIDs AllEthHeaderCIDs
-	err = json.Unmarshal(jsonStr, &allEthHeaderCIDs)
+	var logs FilteredLogs
+	err = json.Unmarshal(jsonStr, &logs)
 	if err != nil {
 		return nil, err
 	}
-	return &allEthHeaderCIDs.Response, nil
+	return logs.Responses, nil
 }
 
-func (c *Client) EthTransactionCIDByTxHash(ctx context.Context, txHash string) (*EthTransactionCIDResponse, error) {
-	getTxQuery := fmt.Sprintf(`
-		query{
-			ethTransactionCidByTxHash(txHash: "%s") {
-				cid
-				txHash
-				index
-				src
-				dst
-				blockByMhKey {
+func (c *Client) GetBlocksLogs(ctx context.Context, from, to uint64) ([]BlockLogsResponse, error) {
+	getBlocksLogsQuery := fmt.Sprintf(`query{
+			blocks(from: %d, to: %d) {
+				number
+				logs(filter: {}) {
 					data
+					topics
+					transaction {
+						hash
+					}
 				}
 			}
-		}
-	`, txHash)
+		}`, from, to)
 
-	req := gqlclient.NewRequest(getTxQuery)
+	req := gqlclient.NewRequest(getBlocksLogsQuery)
 	req.Header.Set("Cache-Control", "no-cache")
 
 	var respData map[string]interface{}
@@ -265,10 +636,1391 @@ func (c *Client) EthTransactionCIDByTxHash(ctx context.Context, txHash string) (
 		return nil, err
 	}
 
-	var ethTxCID EthTransactionCIDByTxHash
-	err = json.Unmarshal(jsonStr, &ethTxCID)
+	var blocks BlocksLogs
+	err = json.Unmarshal(jsonStr, &blocks)
 	if err != nil {
 		return nil, err
 	}
-	return &ethTxCID.Response, nil
+	return blocks.Responses, nil
+}
+
+// GetBlocksFrom returns the numbers of the blocks from the given block to the head
+func (c *Client) GetBlocksFrom(ctx context.Context, from uint64) ([]BlockNumberResponse, error) {
+	getBlocksQuery := fmt.Sprintf(`query{
+			blocks(from: %d) {
+				number
+			}
+		}`, from)
+
+	req := gqlclient.NewRequest(getBlocksQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks BlockNumbers
+	err = json.Unmarshal(jsonStr, &blocks)
+	if err != nil {
+		return nil, err
+	}
+	return blocks.Responses, nil
+}
+
+func (c *Client) GetStorageAt(ctx context.Context, hash common.Hash, address common.Address, slot string) (*StorageResponse, error) {
+	getLogsQuery := fmt.Sprintf(`
+		query{
+			getStorageAt(blockHash: "%s", contract: "%s",slot: "%s") {
+				cid
+				value
+				ipldBlock
+			}
+		}
+	`, hash.String(), address.String(), common.HexToHash(slot))
+
+	req := gqlclient.NewRequest(getLogsQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageAt GetStorageAt
+	err = json.Unmarshal(jsonStr, &storageAt)
+	if err != nil {
+		return nil, err
+	}
+	return &storageAt.Response, nil
+}
+
+func (c *Client) GetStorageAtByNumber(ctx context.Context, number uint64, address common.Address, slot string) (*StorageResponse, error) {
+	getStorageQuery := fmt.Sprintf(`
+		query{
+			getStorageAt(blockNumber: "%d", contract: "%s", slot: "%s") {
+				cid
+				value
+				ipldBlock
+			}
+		}
+	`, number, address.String(), common.HexToHash(slot))
+
+	req := gqlclient.NewRequest(getStorageQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageAt GetStorageAt
+	err = json.Unmarshal(jsonStr, &storageAt)
+	if err != nil {
+		return nil, err
+	}
+	return &storageAt.Response, nil
+}
+
+func (c *Client) GetStateLeaf(ctx context.Context, hash common.Hash, address common.Address) (*StateLeafResponse, error) {
+	getStateLeafQuery := fmt.Sprintf(`
+		query{
+			stateLeaf(blockHash: "%s", address: "%s") {
+				cid
+				ipldBlock
+			}
+		}
+	`, hash.String(), address.String())
+
+	req := gqlclient.NewRequest(getStateLeafQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var stateLeaf GetStateLeaf
+	err = json.Unmarshal(jsonStr, &stateLeaf)
+	if err != nil {
+		return nil, err
+	}
+	return stateLeaf.Response, nil
+}
+
+func (c *Client) AllEthHeaderCIDs(ctx context.Context, condition EthHeaderCIDCondition, limit int32) (*AllEthHeaderCIDsResponse, error) {
+	var limitParam string
+	if limit > 0 {
+		limitParam = fmt.Sprintf(`, limit: %d`, limit)
+	}
+	return c.allEthHeaderCIDs(ctx, condition, limitParam)
+}
+
+// AllEthHeaderCIDsPage gets the page of headers after the cursor, if there is one
+func (c *Client) AllEthHeaderCIDsPage(ctx context.Context, condition EthHeaderCIDCondition, first int32, after *string) (*AllEthHeaderCIDsResponse, error) {
+	pageParams := fmt.Sprintf(`, first: %d`, first)
+	if after != nil {
+		pageParams += fmt.Sprintf(`, after: "%s"`, *after)
+	}
+	return c.allEthHeaderCIDs(ctx, condition, pageParams)
+}
+
+func (c *Client) allEthHeaderCIDs(ctx context.Context, condition EthHeaderCIDCondition, extraParams string) (*AllEthHeaderCIDsResponse, error) {
+	var params string
+	if condition.BlockHash != nil {
+		params = fmt.Sprintf(`blockHash: "%s"`, *condition.BlockHash)
+	}
+	if condition.BlockNumber != nil {
+		params += fmt.Sprintf(`blockNumber: "%s"`, condition.BlockNumber.String())
+	}
+	if condition.FromBlockNumber != nil {
+		params += fmt.Sprintf(` fromBlockNumber: "%s"`, condition.FromBlockNumber.String())
+	}
+	if condition.ToBlockNumber != nil {
+		params += fmt.Sprintf(` toBlockNumber: "%s"`, condition.ToBlockNumber.String())
+	}
+
+	getHeadersQuery := fmt.Sprintf(`
+		query{
+			allEthHeaderCids(condition: { %s }%s) {
+				nodes {
+					cid
+					blockNumber
+					blockHash
+					parentHash
+					timestamp
+					stateRoot
+					td
+					txRoot
+					receiptRoot
+					uncleRoot
+					bloom
+					coinbase
+					nodeId
+					blockByMhKey {
+						key
+						data
+					}
+					ethTransactionCidsByHeaderId {
+						nodes {
+							cid
+							txHash
+							index
+							src
+							dst
+						}
+					}
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}
+	`, params, extraParams)
+
+	req := gqlclient.NewRequest(getHeadersQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var allEthHeaderCIDs AllEthHeaderCIDs
+	err = json.Unmarshal(jsonStr, &allEthHeaderCIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &allEthHeaderCIDs.Response, nil
+}
+
+func (c *Client) EthTransactionCIDByTxHash(ctx context.Context, txHash string, includeData bool) (*EthTransactionCIDResponse, error) {
+	getTxQuery := fmt.Sprintf(`
+		query{
+			ethTransactionCidByTxHash(txHash: "%s", includeData: %t) {
+				cid
+				txHash
+				index
+				src
+				dst
+				blockByMhKey {
+					data
+				}
+				data
+			}
+		}
+	`, txHash, includeData)
+
+	req := gqlclient.NewRequest(getTxQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var ethTxCID EthTransactionCIDByTxHash
+	err = json.Unmarshal(jsonStr, &ethTxCID)
+	if err != nil {
+		return nil, err
+	}
+	return &ethTxCID.Response, nil
+}
+
+func (c *Client) GetBlockGasUsed(ctx context.Context, hash common.Hash) (*BlockGasUsedResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				gasUsed
+				transactions {
+					cumulativeGasUsed
+					gasUsedByTransaction
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockGasUsed
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockOmmers(ctx context.Context, hash common.Hash) (*BlockOmmersResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				ommers {
+					hash
+					transactionCount
+					transactions {
+						hash
+					}
+					logs(filter: {}) {
+						data
+					}
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockOmmers
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockTransactionHashes(ctx context.Context, hash common.Hash) (*BlockTransactionHashesResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactionHashes
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockTransactionHashes
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockContractsCreatedCount(ctx context.Context, hash common.Hash) (*BlockContractsCreatedCountResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				contractsCreatedCount
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockContractsCreatedCount
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetTransactionRaw(ctx context.Context, hash common.Hash) (*TransactionRawResponse, error) {
+	getTransactionQuery := fmt.Sprintf(`
+		query{
+			transaction(hash: "%s") {
+				raw
+				rawReceipt
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getTransactionQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx TransactionRaw
+	err = json.Unmarshal(jsonStr, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return &tx.Response, nil
+}
+
+func (c *Client) GetTransactionMethodSelector(ctx context.Context, hash common.Hash) (*TransactionMethodSelectorResponse, error) {
+	getTransactionQuery := fmt.Sprintf(`
+		query{
+			transaction(hash: "%s") {
+				methodSelector
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getTransactionQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx TransactionMethodSelector
+	err = json.Unmarshal(jsonStr, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return &tx.Response, nil
+}
+
+func (c *Client) GetBlockLogCount(ctx context.Context, hash common.Hash) (*BlockLogCountResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				logCount
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockLogCount
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockByTag(ctx context.Context, tag string) (*BlockByTagResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(tag: %s) {
+				number
+				hash
+			}
+		}
+	`, tag)
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockByTag
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockSize(ctx context.Context, hash common.Hash) (*BlockSizeResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				size
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockSize
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockCliqueSigners(ctx context.Context, hash common.Hash) (*BlockCliqueSignersResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				cliqueSigners {
+					vanity
+					signers
+					seal
+					sealer
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockCliqueSigners
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockBaseFee(ctx context.Context, hash common.Hash) (*BlockBaseFeeResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				baseFeePerGas
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockBaseFee
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockEffectiveTips(ctx context.Context, hash common.Hash) (*BlockEffectiveTipsResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactions {
+					hash
+					effectiveTip
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockEffectiveTips
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockReceiptBlooms(ctx context.Context, hash common.Hash) (*BlockReceiptBloomsResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactions {
+					hash
+					receiptBloom
+					logs {
+						topics
+					}
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockReceiptBlooms
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockTransactionSenders(ctx context.Context, hash common.Hash) (*BlockTransactionSendersResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactions {
+					hash
+					from {
+						address
+					}
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockTransactionSenders
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockAccessLists(ctx context.Context, hash common.Hash) (*BlockAccessListsResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactions {
+					hash
+					accessList {
+						address
+						storageKeys
+					}
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockAccessLists
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockMixHash(ctx context.Context, hash common.Hash) (*BlockMixHashResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				mixHash
+				prevRandao
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockMixHash
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockTransactionSignatures(ctx context.Context, hash common.Hash) (*BlockTransactionSignaturesResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactions {
+					hash
+					signature {
+						r
+						s
+						v
+						yParity
+					}
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockTransactionSignatures
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) GetBlockTransactionChainIDs(ctx context.Context, hash common.Hash) (*BlockTransactionChainIDsResponse, error) {
+	getBlockQuery := fmt.Sprintf(`
+		query{
+			block(hash: "%s") {
+				transactions {
+					hash
+					yParity
+					chainID
+				}
+			}
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var block BlockTransactionChainIDs
+	err = json.Unmarshal(jsonStr, &block)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Response, nil
+}
+
+func (c *Client) EthTransactionCIDsByTxHashes(ctx context.Context, txHashes []string, includeData bool) ([]EthTransactionCIDResponse, error) {
+	hashStrings := make([]string, len(txHashes))
+	for i, txHash := range txHashes {
+		hashStrings[i] = fmt.Sprintf(`"%s"`, txHash)
+	}
+
+	getTxsQuery := fmt.Sprintf(`
+		query{
+			ethTransactionCidsByTxHashes(txHashes: [%s], includeData: %t) {
+				nodes {
+					cid
+					txHash
+					index
+					src
+					dst
+					blockByMhKey {
+						data
+					}
+					data
+				}
+			}
+		}
+	`, strings.Join(hashStrings, ","), includeData)
+
+	req := gqlclient.NewRequest(getTxsQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var ethTxCIDs EthTransactionCIDsByTxHashes
+	err = json.Unmarshal(jsonStr, &ethTxCIDs)
+	if err != nil {
+		return nil, err
+	}
+	return ethTxCIDs.Response.Nodes, nil
+}
+
+func (c *Client) GetEventSignatures(ctx context.Context, address common.Address) ([]common.Hash, error) {
+	getEventSignaturesQuery := fmt.Sprintf(`
+		query{
+			eventSignatures(address: "%s")
+		}
+	`, address.String())
+
+	req := gqlclient.NewRequest(getEventSignaturesQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventSignatures EventSignaturesResponse
+	err = json.Unmarshal(jsonStr, &eventSignatures)
+	if err != nil {
+		return nil, err
+	}
+	return eventSignatures.EventSignatures, nil
+}
+
+func (c *Client) GetEventSignaturesInRange(ctx context.Context, address common.Address, from, to uint64) ([]common.Hash, error) {
+	getEventSignaturesQuery := fmt.Sprintf(`
+		query{
+			eventSignatures(address: "%s", from: %d, to: %d)
+		}
+	`, address.String(), from, to)
+
+	req := gqlclient.NewRequest(getEventSignaturesQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventSignatures EventSignaturesResponse
+	err = json.Unmarshal(jsonStr, &eventSignatures)
+	if err != nil {
+		return nil, err
+	}
+	return eventSignatures.EventSignatures, nil
+}
+
+func (c *Client) GetTransactionsByAddress(ctx context.Context, address common.Address, fromBlock, toBlock uint64) ([]TransactionByAddressResponse, error) {
+	getTransactionsQuery := fmt.Sprintf(`
+		query{
+			transactionsByAddress(address: "%s", fromBlock: %d, toBlock: %d) {
+				hash
+				index
+				block {
+					number
+				}
+			}
+		}
+	`, address.String(), fromBlock, toBlock)
+
+	req := gqlclient.NewRequest(getTransactionsQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs TransactionsByAddress
+	err = json.Unmarshal(jsonStr, &txs)
+	if err != nil {
+		return nil, err
+	}
+	return txs.Responses, nil
+}
+
+func (c *Client) GetBlockNumberByHash(ctx context.Context, hash common.Hash) (*hexutil.Uint64, error) {
+	getBlockNumberQuery := fmt.Sprintf(`
+		query{
+			blockNumberByHash(hash: "%s")
+		}
+	`, hash.String())
+
+	req := gqlclient.NewRequest(getBlockNumberQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockNumber BlockNumberByHash
+	err = json.Unmarshal(jsonStr, &blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return blockNumber.Number, nil
+}
+
+func (c *Client) GetContractDeploymentBlock(ctx context.Context, address common.Address) (*hexutil.Uint64, error) {
+	getDeploymentBlockQuery := fmt.Sprintf(`
+		query{
+			contractDeploymentBlock(address: "%s")
+		}
+	`, address.String())
+
+	req := gqlclient.NewRequest(getDeploymentBlockQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var deploymentBlock ContractDeploymentBlock
+	err = json.Unmarshal(jsonStr, &deploymentBlock)
+	if err != nil {
+		return nil, err
+	}
+	return deploymentBlock.Number, nil
+}
+
+func (c *Client) GetBlockHashesAt(ctx context.Context, number uint64) ([]BlockHashAtResponse, error) {
+	getBlockHashesQuery := fmt.Sprintf(`
+		query{
+			blockHashesAt(number: %d) {
+				hash
+				canonical
+			}
+		}
+	`, number)
+
+	req := gqlclient.NewRequest(getBlockHashesQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockHashes GetBlockHashesAt
+	err = json.Unmarshal(jsonStr, &blockHashes)
+	if err != nil {
+		return nil, err
+	}
+	return blockHashes.Responses, nil
+}
+
+func (c *Client) GetLogBlockRange(ctx context.Context, address common.Address) (*BlockRangeResponse, error) {
+	getLogBlockRangeQuery := fmt.Sprintf(`
+		query{
+			logBlockRange(address: "%s") {
+				from
+				to
+			}
+		}
+	`, address.String())
+
+	req := gqlclient.NewRequest(getLogBlockRangeQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockRange GetLogBlockRange
+	err = json.Unmarshal(jsonStr, &blockRange)
+	if err != nil {
+		return nil, err
+	}
+	return blockRange.Response, nil
+}
+
+func (c *Client) GetStorageSlotHistory(ctx context.Context, address common.Address, slot common.Hash, from, to uint64) ([]StorageSlotChangeResponse, error) {
+	getStorageSlotHistoryQuery := fmt.Sprintf(`
+		query{
+			storageSlotHistory(address: "%s", slot: "%s", from: %d, to: %d) {
+				blockNumber
+				value
+			}
+		}
+	`, address.String(), slot.Hex(), from, to)
+
+	req := gqlclient.NewRequest(getStorageSlotHistoryQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var history GetStorageSlotHistory
+	err = json.Unmarshal(jsonStr, &history)
+	if err != nil {
+		return nil, err
+	}
+	return history.Responses, nil
+}
+
+func (c *Client) GetStorageChangeBlocks(ctx context.Context, address common.Address, from, to uint64) ([]hexutil.Uint64, error) {
+	getStorageChangeBlocksQuery := fmt.Sprintf(`
+		query{
+			storageChangeBlocks(address: "%s", from: %d, to: %d)
+		}
+	`, address.String(), from, to)
+
+	req := gqlclient.NewRequest(getStorageChangeBlocksQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageChangeBlocks StorageChangeBlocksResponse
+	err = json.Unmarshal(jsonStr, &storageChangeBlocks)
+	if err != nil {
+		return nil, err
+	}
+	return storageChangeBlocks.StorageChangeBlocks, nil
+}
+
+func (c *Client) GetRemovedAccounts(ctx context.Context, blockHash common.Hash) ([]common.Hash, error) {
+	getRemovedAccountsQuery := fmt.Sprintf(`
+		query{
+			removedAccounts(blockHash: "%s")
+		}
+	`, blockHash.String())
+
+	req := gqlclient.NewRequest(getRemovedAccountsQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var removedAccounts RemovedAccountsResponse
+	err = json.Unmarshal(jsonStr, &removedAccounts)
+	if err != nil {
+		return nil, err
+	}
+	return removedAccounts.RemovedAccounts, nil
+}
+
+func (c *Client) GetGasUsedRatios(ctx context.Context, from, to uint64) ([]GasUsedRatioResponse, error) {
+	getGasUsedRatiosQuery := fmt.Sprintf(`
+		query{
+			gasUsedRatios(from: %d, to: %d) {
+				number
+				ratio
+			}
+		}
+	`, from, to)
+
+	req := gqlclient.NewRequest(getGasUsedRatiosQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var ratios GetGasUsedRatios
+	err = json.Unmarshal(jsonStr, &ratios)
+	if err != nil {
+		return nil, err
+	}
+	return ratios.Responses, nil
+}
+
+func (c *Client) GetGasPriceStats(ctx context.Context, from, to uint64, percentile int32) (*GasPriceStatsResponse, error) {
+	getGasPriceStatsQuery := fmt.Sprintf(`
+		query{
+			gasPriceStats(from: %d, to: %d, percentile: %d) {
+				count
+				average
+				percentile
+			}
+		}
+	`, from, to, percentile)
+
+	req := gqlclient.NewRequest(getGasPriceStatsQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats GetGasPriceStats
+	err = json.Unmarshal(jsonStr, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats.Response, nil
+}
+
+func (c *Client) GetActiveAddressCount(ctx context.Context, blockNumber uint64) (uint64, error) {
+	getActiveAddressCountQuery := fmt.Sprintf(`
+		query{
+			activeAddressCount(blockNumber: %d)
+		}
+	`, blockNumber)
+
+	req := gqlclient.NewRequest(getActiveAddressCountQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return 0, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return 0, err
+	}
+
+	var activeAddressCount ActiveAddressCountResponse
+	err = json.Unmarshal(jsonStr, &activeAddressCount)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(activeAddressCount.ActiveAddressCount), nil
+}
+
+func (c *Client) GetCodeChunk(ctx context.Context, address common.Address, blockNumber, offset, length uint64) (hexutil.Bytes, error) {
+	getCodeChunkQuery := fmt.Sprintf(`
+		query{
+			codeChunk(address: "%s", block: %d, offset: %d, length: %d)
+		}
+	`, address.String(), blockNumber, offset, length)
+
+	req := gqlclient.NewRequest(getCodeChunkQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var codeChunk CodeChunkResponse
+	err = json.Unmarshal(jsonStr, &codeChunk)
+	if err != nil {
+		return nil, err
+	}
+	return codeChunk.CodeChunk, nil
+}
+
+func (c *Client) GetTransfers(ctx context.Context, address common.Address, from, to uint64) ([]TransferResponse, error) {
+	getTransfersQuery := fmt.Sprintf(`
+		query{
+			transfers(address: "%s", fromBlock: %d, toBlock: %d) {
+				from
+				to
+				value
+				tokenId
+				txHash
+				logIndex
+			}
+		}
+	`, address.String(), from, to)
+
+	req := gqlclient.NewRequest(getTransfersQuery)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	var respData map[string]interface{}
+	err := c.client.Run(ctx, req, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers GetTransfers
+	err = json.Unmarshal(jsonStr, &transfers)
+	if err != nil {
+		return nil, err
+	}
+	return transfers.Responses, nil
 }
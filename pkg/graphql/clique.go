@@ -0,0 +1,107 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// cliqueExtraVanity is the number of extra-data prefix bytes reserved for the signer vanity
+	cliqueExtraVanity = 32
+	// cliqueExtraSeal is the number of extra-data suffix bytes reserved for the signer seal
+	cliqueExtraSeal = crypto.SignatureLength
+)
+
+var errInvalidCliqueExtraData = errors.New("extra-data is not formatted per clique rules")
+
+// CliqueExtraData is the extra-data of a Clique proof-of-authority block, split into its fields
+type CliqueExtraData struct {
+	vanity  []byte
+	signers []common.Address
+	seal    []byte
+	sealer  *common.Address
+}
+
+// ParseCliqueExtraData splits the header's extra-data into the signer vanity, the list of signers
+// (only present at checkpoint blocks) and the seal, recovering the sealer of the block from the seal;
+// the genesis block is not sealed, its seal is zero and it has no sealer
+func ParseCliqueExtraData(header *types.Header) (*CliqueExtraData, error) {
+	extra := header.Extra
+	if len(extra) < cliqueExtraVanity+cliqueExtraSeal {
+		return nil, errInvalidCliqueExtraData
+	}
+	signersBytes := extra[cliqueExtraVanity : len(extra)-cliqueExtraSeal]
+	if len(signersBytes)%common.AddressLength != 0 {
+		return nil, errInvalidCliqueExtraData
+	}
+	data := &CliqueExtraData{
+		vanity:  extra[:cliqueExtraVanity],
+		signers: make([]common.Address, len(signersBytes)/common.AddressLength),
+		seal:    extra[len(extra)-cliqueExtraSeal:],
+	}
+	for i := range data.signers {
+		copy(data.signers[i][:], signersBytes[i*common.AddressLength:])
+	}
+
+	if bytes.Equal(data.seal, make([]byte, cliqueExtraSeal)) {
+		return data, nil
+	}
+	pubkey, err := crypto.Ecrecover(clique.SealHash(header).Bytes(), data.seal)
+	if err != nil {
+		return nil, err
+	}
+	sealer := common.BytesToAddress(crypto.Keccak256(pubkey[1:])[12:])
+	data.sealer = &sealer
+	return data, nil
+}
+
+func (c *CliqueExtraData) Vanity(ctx context.Context) hexutil.Bytes {
+	return c.vanity
+}
+
+func (c *CliqueExtraData) Signers(ctx context.Context) []common.Address {
+	return c.signers
+}
+
+func (c *CliqueExtraData) Seal(ctx context.Context) hexutil.Bytes {
+	return c.seal
+}
+
+func (c *CliqueExtraData) Sealer(ctx context.Context) *common.Address {
+	return c.sealer
+}
+
+// CliqueSigners returns the Clique fields of the block's extra-data, or nil if the chain does not use Clique
+func (b *Block) CliqueSigners(ctx context.Context) (*CliqueExtraData, error) {
+	if b.backend.ChainConfig().Clique == nil {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCliqueExtraData(header)
+}
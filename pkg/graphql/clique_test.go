@@ -0,0 +1,103 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/graphql"
+)
+
+// sealCliqueHeader builds a header with Clique formatted extra-data listing the signers, sealed with the key
+func sealCliqueHeader(number int64, vanity []byte, signers []common.Address) (*types.Header, common.Address) {
+	key, err := crypto.GenerateKey()
+	Expect(err).ToNot(HaveOccurred())
+
+	extra := common.RightPadBytes(vanity, 32)
+	for _, signer := range signers {
+		extra = append(extra, signer.Bytes()...)
+	}
+	extra = append(extra, make([]byte, crypto.SignatureLength)...)
+	header := &types.Header{
+		Number:     big.NewInt(number),
+		Difficulty: big.NewInt(2),
+		GasLimit:   8000000,
+		Extra:      extra,
+	}
+	seal, err := crypto.Sign(clique.SealHash(header).Bytes(), key)
+	Expect(err).ToNot(HaveOccurred())
+	copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], seal)
+	return header, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+var _ = Describe("ParseCliqueExtraData", func() {
+	var (
+		ctx     = context.Background()
+		signers = []common.Address{
+			common.HexToAddress("0x1111111111111111111111111111111111111111"),
+			common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		}
+	)
+
+	It("Parses the vanity, signers and seal of a checkpoint block and recovers its sealer", func() {
+		header, sealer := sealCliqueHeader(0, []byte("vanity"), signers)
+		data, err := graphql.ParseCliqueExtraData(header)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.Vanity(ctx)).To(Equal(hexutil.Bytes(common.RightPadBytes([]byte("vanity"), 32))))
+		Expect(data.Signers(ctx)).To(Equal(signers))
+		Expect(data.Seal(ctx)).To(Equal(hexutil.Bytes(header.Extra[len(header.Extra)-crypto.SignatureLength:])))
+		Expect(data.Sealer(ctx)).To(Equal(&sealer))
+	})
+
+	It("Parses no signers for a block which is not a checkpoint", func() {
+		header, sealer := sealCliqueHeader(1, nil, nil)
+		data, err := graphql.ParseCliqueExtraData(header)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.Vanity(ctx)).To(Equal(hexutil.Bytes(make([]byte, 32))))
+		Expect(data.Signers(ctx)).To(BeEmpty())
+		Expect(data.Sealer(ctx)).To(Equal(&sealer))
+	})
+
+	It("Recovers no sealer from the zero seal of the genesis block", func() {
+		extra := append(make([]byte, 32), signers[0].Bytes()...)
+		extra = append(extra, make([]byte, crypto.SignatureLength)...)
+		data, err := graphql.ParseCliqueExtraData(&types.Header{Number: big.NewInt(0), Extra: extra})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.Signers(ctx)).To(Equal(signers[:1]))
+		Expect(data.Sealer(ctx)).To(BeNil())
+	})
+
+	It("Rejects extra-data which is not formatted per clique rules", func() {
+		// too short for the vanity and seal
+		_, err := graphql.ParseCliqueExtraData(&types.Header{Number: big.NewInt(1), Extra: make([]byte, 96)})
+		Expect(err).To(HaveOccurred())
+
+		// a partial signer address
+		_, err = graphql.ParseCliqueExtraData(&types.Header{Number: big.NewInt(1), Extra: bytes.Repeat([]byte{1}, 32+10+crypto.SignatureLength)})
+		Expect(err).To(HaveOccurred())
+	})
+})
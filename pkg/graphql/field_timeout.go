@@ -0,0 +1,51 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+// fieldTimeoutTracer traces like the default tracer, but bounds each resolver field by a deadline derived from the
+// context of its parent, so a single slow field fails on its own rather than hanging the whole query.
+// The executor does not start resolvers whose context is done, and resolvers doing iterative work check it themselves.
+type fieldTimeoutTracer struct {
+	trace.OpenTracingTracer
+	timeout time.Duration
+}
+
+// NewFieldTimeoutTracer returns a tracer that gives each non-trivial resolver field, and the fields selected beneath it,
+// at most the provided timeout to complete
+func NewFieldTimeoutTracer(timeout time.Duration) trace.Tracer {
+	return fieldTimeoutTracer{timeout: timeout}
+}
+
+func (t fieldTimeoutTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	ctx, finish := t.OpenTracingTracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+	if trivial {
+		return ctx, finish
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	return ctx, func(err *errors.QueryError) {
+		cancel()
+		finish(err)
+	}
+}
@@ -0,0 +1,73 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	gqlgo "github.com/graph-gophers/graphql-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/graphql"
+)
+
+const fieldTimeoutSchema = `
+	schema { query: Query }
+	type Query {
+		fast: String!
+		slow: String
+	}
+`
+
+type fieldTimeoutResolver struct{}
+
+func (fieldTimeoutResolver) Fast(ctx context.Context) (string, error) {
+	return "fast", ctx.Err()
+}
+
+// Slow blocks until its context is done, failing the test's time limit if the deadline never arrives
+func (fieldTimeoutResolver) Slow(ctx context.Context) (*string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(10 * time.Second):
+		res := "slow"
+		return &res, nil
+	}
+}
+
+var _ = Describe("field timeout", func() {
+	It("Fails a slow field once its deadline passes while other fields succeed", func() {
+		schema := gqlgo.MustParseSchema(fieldTimeoutSchema, &fieldTimeoutResolver{}, gqlgo.Tracer(graphql.NewFieldTimeoutTracer(50*time.Millisecond)))
+
+		start := time.Now()
+		res := schema.Exec(context.Background(), `{ fast slow }`, "", nil)
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+		Expect(len(res.Errors)).To(Equal(1))
+		Expect(res.Errors[0].Path).To(Equal([]interface{}{"slow"}))
+		Expect(res.Errors[0].Message).To(ContainSubstring(context.DeadlineExceeded.Error()))
+
+		var data map[string]interface{}
+		Expect(json.Unmarshal(res.Data, &data)).To(Succeed())
+		Expect(data["fast"]).To(Equal("fast"))
+		Expect(data["slow"]).To(BeNil())
+	})
+})
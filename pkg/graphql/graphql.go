@@ -21,9 +21,13 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -76,12 +80,37 @@ func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error)
 	return hexutil.Uint64(state.GetNonce(a.address)), nil
 }
 
+// Code returns the account's code, read by its code hash so that no state root needs to be resolved.
 func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	code, err := a.backend.GetCodeByNumberOrHash(ctx, a.address, a.blockNrOrHash)
+	if err == sql.ErrNoRows {
+		return hexutil.Bytes{}, nil
+	}
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(code), nil
+}
+
+// CodeChunk returns the slice of the account's code starting at offset, of at most length bytes.
+// The chunk is empty if the offset is beyond the end of the code.
+func (a *Account) CodeChunk(ctx context.Context, args struct {
+	Offset hexutil.Uint64
+	Length hexutil.Uint64
+}) (hexutil.Bytes, error) {
 	state, err := a.getState(ctx)
 	if err != nil {
 		return hexutil.Bytes{}, err
 	}
-	return hexutil.Bytes(state.GetCode(a.address)), nil
+	code := state.GetCode(a.address)
+	if uint64(args.Offset) >= uint64(len(code)) {
+		return hexutil.Bytes{}, nil
+	}
+	code = code[args.Offset:]
+	if uint64(args.Length) < uint64(len(code)) {
+		code = code[:args.Length]
+	}
+	return hexutil.Bytes(code), nil
 }
 
 func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
@@ -152,6 +181,48 @@ func (l *Log) ReceiptCID(_ context.Context) string {
 	return l.receiptCID
 }
 
+// AccessTuple represents an EIP-2930 access list entry: an address and the storage keys accessed under it.
+type AccessTuple struct {
+	address     common.Address
+	storageKeys []common.Hash
+}
+
+// Address returns the address of the accessed account.
+func (at *AccessTuple) Address() common.Address {
+	return at.address
+}
+
+// StorageKeys returns the accessed storage keys of the account.
+func (at *AccessTuple) StorageKeys() []common.Hash {
+	return at.storageKeys
+}
+
+// TransactionSignature represents the raw signature values of a transaction.
+type TransactionSignature struct {
+	r, s, v hexutil.Big
+	yParity *hexutil.Uint64
+}
+
+// R returns the r value of the signature.
+func (ts *TransactionSignature) R() hexutil.Big {
+	return ts.r
+}
+
+// S returns the s value of the signature.
+func (ts *TransactionSignature) S() hexutil.Big {
+	return ts.s
+}
+
+// V returns the v value of the signature.
+func (ts *TransactionSignature) V() hexutil.Big {
+	return ts.v
+}
+
+// YParity returns the y parity of the signature, which is only set for typed transactions.
+func (ts *TransactionSignature) YParity() *hexutil.Uint64 {
+	return ts.yParity
+}
+
 // Transaction represents an Ethereum transaction.
 // backend and hash are mandatory; all others will be fetched when required.
 type Transaction struct {
@@ -191,6 +262,17 @@ func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
 	return hexutil.Bytes(tx.Data()), nil
 }
 
+// MethodSelector returns the first 4 bytes of the input data, which select the contract method being called,
+// or nil if the input data is too short to hold one, as for plain value transfers
+func (t *Transaction) MethodSelector(ctx context.Context) (*string, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || len(tx.Data()) < 4 {
+		return nil, err
+	}
+	selector := hexutil.Encode(tx.Data()[:4])
+	return &selector, nil
+}
+
 func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
 	tx, err := t.resolve(ctx)
 	if err != nil || tx == nil {
@@ -207,6 +289,24 @@ func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*tx.GasPrice()), nil
 }
 
+// EffectiveTip returns the tip per unit of gas paid to the miner, given the base fee of the transaction's block
+// It is nil for transactions that are not mined or were mined before London
+func (t *Transaction) EffectiveTip(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || t.block == nil {
+		return nil, err
+	}
+	header, err := t.block.resolveHeader(ctx)
+	if err != nil || header == nil || header.BaseFee == nil {
+		return nil, err
+	}
+	tip, err := tx.EffectiveGasTip(header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(tip), nil
+}
+
 func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
 	tx, err := t.resolve(ctx)
 	if err != nil || tx == nil {
@@ -244,10 +344,7 @@ func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account,
 	if err != nil || tx == nil {
 		return nil, err
 	}
-	var signer types.Signer = types.HomesteadSigner{}
-	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainId())
-	}
+	signer := types.LatestSignerForChainID(tx.ChainId())
 	from, _ := types.Sender(signer, tx)
 
 	return &Account{
@@ -275,6 +372,25 @@ func (t *Transaction) Index(ctx context.Context) (*int32, error) {
 	return &index, nil
 }
 
+// Raw returns the canonical encoding of the transaction, the RLP encoding of a legacy transaction or the
+// type-prefixed envelope of a typed one, which is the data of its IPLD block
+func (t *Transaction) Raw(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return tx.MarshalBinary()
+}
+
+// RawReceipt returns the canonical encoding of the receipt of the transaction, consistent with its IPLD block
+func (t *Transaction) RawReceipt(ctx context.Context) (hexutil.Bytes, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return hexutil.Bytes{}, err
+	}
+	return receipt.MarshalBinary()
+}
+
 // getReceipt returns the receipt associated with this transaction, if any.
 func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
 	if _, err := t.resolve(ctx); err != nil {
@@ -287,6 +403,10 @@ func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the transactions of proxied uncle blocks have no receipts
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
 	return receipts[t.index], nil
 }
 
@@ -295,6 +415,10 @@ func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
 	if err != nil || receipt == nil {
 		return nil, err
 	}
+	// pre-Byzantium receipts have a post state root instead of a status
+	if len(receipt.PostState) > 0 {
+		return nil, nil
+	}
 	ret := hexutil.Uint64(receipt.Status)
 	return &ret, nil
 }
@@ -317,6 +441,25 @@ func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, e
 	return &ret, nil
 }
 
+// GasUsedByTransaction returns the gas used by this transaction alone, computed from the cumulative gas used
+// of its receipt minus that of the preceding receipt in the block.
+func (t *Transaction) GasUsedByTransaction(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := receipt.CumulativeGasUsed
+	if t.index > 0 {
+		receipts, err := t.block.resolveReceipts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		gasUsed -= receipts[t.index-1].CumulativeGasUsed
+	}
+	ret := hexutil.Uint64(gasUsed)
+	return &ret, nil
+}
+
 func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
 	receipt, err := t.getReceipt(ctx)
 	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
@@ -345,6 +488,33 @@ func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
 	return &ret, nil
 }
 
+// ReceiptBloom returns the logs bloom of the receipt associated with this transaction, if any.
+func (t *Transaction) ReceiptBloom(ctx context.Context) (*hexutil.Bytes, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Bytes(receipt.Bloom.Bytes())
+	return &ret, nil
+}
+
+// AccessList returns the access list of this transaction, or nil for legacy transactions which have none.
+func (t *Transaction) AccessList(ctx context.Context) (*[]*AccessTuple, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.Type() == types.LegacyTxType {
+		return nil, err
+	}
+	accessList := tx.AccessList()
+	ret := make([]*AccessTuple, 0, len(accessList))
+	for _, tuple := range accessList {
+		ret = append(ret, &AccessTuple{
+			address:     tuple.Address,
+			storageKeys: tuple.StorageKeys,
+		})
+	}
+	return &ret, nil
+}
+
 func (t *Transaction) R(ctx context.Context) (hexutil.Big, error) {
 	tx, err := t.resolve(ctx)
 	if err != nil || tx == nil {
@@ -372,6 +542,47 @@ func (t *Transaction) V(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*v), nil
 }
 
+func (t *Transaction) Signature(ctx context.Context) (*TransactionSignature, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	v, r, s := tx.RawSignatureValues()
+	return &TransactionSignature{r: hexutil.Big(*r), s: hexutil.Big(*s), v: hexutil.Big(*v), yParity: yParity(tx)}, nil
+}
+
+// YParity returns the y parity of the signature of typed transactions, or nil for legacy transactions.
+func (t *Transaction) YParity(ctx context.Context) (*hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return yParity(tx), nil
+}
+
+// ChainID returns the chain id the transaction was signed for, or nil for legacy transactions
+// which are not EIP-155 protected.
+func (t *Transaction) ChainID(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	if tx.Type() == types.LegacyTxType && !tx.Protected() {
+		return nil, nil
+	}
+	return (*hexutil.Big)(tx.ChainId()), nil
+}
+
+// yParity returns the y parity of a typed transaction's signature, which is its v value, or nil for legacy transactions
+func yParity(tx *types.Transaction) *hexutil.Uint64 {
+	if tx.Type() == types.LegacyTxType {
+		return nil
+	}
+	v, _, _ := tx.RawSignatureValues()
+	ret := hexutil.Uint64(v.Uint64())
+	return &ret
+}
+
 type BlockType int
 
 // Block represents an Ethereum block.
@@ -384,6 +595,33 @@ type Block struct {
 	header       *types.Header
 	block        *types.Block
 	receipts     []*types.Receipt
+	// uncle blocks are constructed from their header alone, they have no transactions, receipts or uncles,
+	// unless ProxyUncleBlocks is set and their transactions are served from the proxy node
+	uncle bool
+	// set for the blocks of a `blocks` list, caps the logs selections across the list
+	logsSelection *blocksLogsSelection
+}
+
+// blocksLogsSelection counts the logs selections resolved across the blocks of a single `blocks` list,
+// as every selection replays the bloom filter of every block in the list
+type blocksLogsSelection struct {
+	blocks int64
+	limit  int64
+	calls  int64 // accessed atomically
+}
+
+// check returns an error once the number of blocks in the list multiplied by the
+// number of logs selections on each block exceeds the limit
+func (s *blocksLogsSelection) check() error {
+	if s == nil || s.limit <= 0 {
+		return nil
+	}
+	calls := atomic.AddInt64(&s.calls, 1)
+	selections := (calls + s.blocks - 1) / s.blocks
+	if s.blocks*selections > s.limit {
+		return fmt.Errorf("selecting logs %d time(s) on a range of %d blocks exceeds the limit of %d", selections, s.blocks, s.limit)
+	}
+	return nil
 }
 
 // resolve returns the internal Block object representing this block, fetching
@@ -392,6 +630,20 @@ func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
 	if b.block != nil {
 		return b.block, nil
 	}
+	if b.uncle {
+		if b.backend.Config.ProxyUncleBlocks && b.backend.Config.Client != nil {
+			block, err := b.backend.ProxiedBlockByHash(ctx, b.header.Hash())
+			if err != nil {
+				return nil, err
+			}
+			if block != nil {
+				b.block = block
+				return b.block, nil
+			}
+		}
+		b.block = types.NewBlockWithHeader(b.header)
+		return b.block, nil
+	}
 	if b.numberOrHash == nil {
 		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 		b.numberOrHash = &latest
@@ -428,6 +680,9 @@ func (b *Block) resolveHeader(ctx context.Context) (*types.Header, error) {
 // resolveReceipts returns the list of receipts for this block, fetching them
 // if necessary.
 func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	if b.uncle {
+		return []*types.Receipt{}, nil
+	}
 	if b.receipts == nil {
 		hash := b.hash
 		if hash == (common.Hash{}) {
@@ -482,6 +737,26 @@ func (b *Block) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
 	return hexutil.Uint64(header.GasUsed), nil
 }
 
+// Size resolves the full block, as the size covers its transactions and uncles as well as the header
+func (b *Block) Size(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.Size()), nil
+}
+
+func (b *Block) BaseFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, nil
+	}
+	return (*hexutil.Big)(header.BaseFee), nil
+}
+
 func (b *Block) Parent(ctx context.Context) (*Block, error) {
 	// If the block header hasn't been fetched, and we'll need it, fetch it.
 	if b.numberOrHash == nil && b.header == nil {
@@ -532,6 +807,20 @@ func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
 	return header.MixDigest, nil
 }
 
+// PrevRandao returns the MixDigest of post-Merge blocks, which carries the beacon chain's prevRandao value.
+// Post-Merge blocks are identified by their zero difficulty.
+func (b *Block) PrevRandao(ctx context.Context) (*common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.Difficulty.Sign() != 0 {
+		return nil, nil
+	}
+	prevRandao := header.MixDigest
+	return &prevRandao, nil
+}
+
 func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
@@ -585,6 +874,7 @@ func (b *Block) Ommers(ctx context.Context) (*[]*Block, error) {
 			backend:      b.backend,
 			numberOrHash: &blockNumberOrHash,
 			header:       uncle,
+			uncle:        true,
 		})
 	}
 	return &ret, nil
@@ -607,15 +897,20 @@ func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
 }
 
 func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
-	h := b.hash
-	if h == (common.Hash{}) {
-		header, err := b.resolveHeader(ctx)
+	if b.header == nil && b.hash != (common.Hash{}) {
+		// retrieve the header along with the total difficulty, sparing the header's fields a round trip of their own
+		header, td, err := b.backend.Retriever.RetrieveHeaderWithTdByHash(b.hash)
 		if err != nil {
 			return hexutil.Big{}, err
 		}
-		h = header.Hash()
+		b.header = header
+		return hexutil.Big(*td), nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
 	}
-	td, err := b.backend.GetTd(h)
+	td, err := b.backend.GetTd(header.Hash())
 	if err != nil {
 		return hexutil.Big{}, err
 	}
@@ -685,6 +980,73 @@ func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
 	return &ret, nil
 }
 
+// TransactionHashes returns the hashes of the block's transactions in order, without fetching
+// the transactions unless the block has already been resolved
+func (b *Block) TransactionHashes(ctx context.Context) (*[]common.Hash, error) {
+	if b.uncle {
+		if _, err := b.resolve(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if b.block != nil {
+		ret := make([]common.Hash, 0, len(b.block.Transactions()))
+		for _, tx := range b.block.Transactions() {
+			ret = append(ret, tx.Hash())
+		}
+		return &ret, nil
+	}
+	hash := b.hash
+	if hash == (common.Hash{}) {
+		header, err := b.resolveHeader(ctx)
+		if err != nil || header == nil {
+			return nil, err
+		}
+		hash = header.Hash()
+	}
+	ret, err := b.backend.IPLDRetriever.RetrieveTxHashesByBlockHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ContractsCreatedCount returns the number of contracts created by the block's transactions,
+// or nil if the block is not canonical
+func (b *Block) ContractsCreatedCount(ctx context.Context) (*hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	canonicalHash, err := b.backend.GetCanonicalHash(header.Number.Uint64())
+	if err != nil || canonicalHash != header.Hash() {
+		return nil, err
+	}
+	count, err := b.backend.Retriever.RetrieveContractDeploymentCountByBlockNumber(header.Number.Int64())
+	if err != nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(count)
+	return &ret, nil
+}
+
+// LogCount returns the number of logs emitted by the block's transactions, or nil if the block is not canonical
+func (b *Block) LogCount(ctx context.Context) (*hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	canonicalHash, err := b.backend.GetCanonicalHash(header.Number.Uint64())
+	if err != nil || canonicalHash != header.Hash() {
+		return nil, err
+	}
+	count, err := b.backend.Retriever.RetrieveLogCountByBlockHash(header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(count)
+	return &ret, nil
+}
+
 func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
 	block, err := b.resolve(ctx)
 	if err != nil || block == nil {
@@ -719,6 +1081,7 @@ func (b *Block) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*Block
 		backend:      b.backend,
 		numberOrHash: &blockNumberOrHash,
 		header:       uncle,
+		uncle:        true,
 	}, nil
 }
 
@@ -748,6 +1111,7 @@ func runFilter(ctx context.Context, be *eth.Backend, filter *filters.Filter) ([]
 	if err != nil || logs == nil {
 		return nil, err
 	}
+	be.OrderLogs(logs)
 	ret := make([]*Log, 0, len(logs))
 	for _, log := range logs {
 		ret = append(ret, &Log{
@@ -760,6 +1124,12 @@ func runFilter(ctx context.Context, be *eth.Backend, filter *filters.Filter) ([]
 }
 
 func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	if b.uncle {
+		return []*Log{}, nil
+	}
+	if err := b.logsSelection.check(); err != nil {
+		return nil, err
+	}
 	var addresses []common.Address
 	if args.Filter.Addresses != nil {
 		addresses = *args.Filter.Addresses
@@ -768,6 +1138,9 @@ func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteri
 	if args.Filter.Topics != nil {
 		topics = *args.Filter.Topics
 	}
+	if err := b.backend.CheckLogsTopics(topics); err != nil {
+		return nil, err
+	}
 	hash := b.hash
 	if hash == (common.Hash{}) {
 		header, err := b.resolveHeader(ctx)
@@ -857,14 +1230,34 @@ func (b *Block) Call(ctx context.Context, args struct {
 // Resolver is the top-level object in the GraphQL hierarchy.
 type Resolver struct {
 	backend *eth.Backend
+	// max number of blocks in a `blocks` list multiplied by the logs selections on each block (0 = unlimited)
+	blocksLogsLimit int64
 }
 
 func (r *Resolver) Block(ctx context.Context, args struct {
 	Number *hexutil.Uint64
 	Hash   *common.Hash
+	Tag    *string
 }) (*Block, error) {
 	var block *Block
-	if args.Number != nil {
+	if args.Tag != nil {
+		if args.Number != nil || args.Hash != nil {
+			return nil, fmt.Errorf("provide either a block number, hash or tag")
+		}
+		var tag rpc.BlockNumber
+		if err := tag.UnmarshalJSON([]byte(strconv.Quote(strings.ToLower(*args.Tag)))); err != nil {
+			return nil, err
+		}
+		// the tag is resolved up front so that the block's fields all refer to the same block
+		numberOrHash, err := r.backend.ResolveBlockTag(ctx, rpc.BlockNumberOrHashWithNumber(tag))
+		if err != nil {
+			return nil, err
+		}
+		block = &Block{
+			backend:      r.backend,
+			numberOrHash: &numberOrHash,
+		}
+	} else if args.Number != nil {
 		number := rpc.BlockNumber(uint64(*args.Number))
 		numberOrHash := rpc.BlockNumberOrHashWithNumber(number)
 		block = &Block{
@@ -896,6 +1289,27 @@ func (r *Resolver) Block(ctx context.Context, args struct {
 	return block, nil
 }
 
+func (r *Resolver) CodeChunk(ctx context.Context, args struct {
+	Address common.Address
+	Block   *hexutil.Uint64
+	Offset  hexutil.Uint64
+	Length  hexutil.Uint64
+}) (hexutil.Bytes, error) {
+	numberOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if args.Block != nil {
+		numberOrHash = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(uint64(*args.Block)))
+	}
+	account := &Account{
+		backend:       r.backend,
+		address:       args.Address,
+		blockNrOrHash: numberOrHash,
+	}
+	return account.CodeChunk(ctx, struct {
+		Offset hexutil.Uint64
+		Length hexutil.Uint64
+	}{args.Offset, args.Length})
+}
+
 func (r *Resolver) Blocks(ctx context.Context, args struct {
 	From hexutil.Uint64
 	To   *hexutil.Uint64
@@ -910,17 +1324,26 @@ func (r *Resolver) Blocks(ctx context.Context, args struct {
 		if err != nil {
 			return []*Block{}, nil
 		}
-		to = rpc.BlockNumber(block.Number().Int64())
+		end, err := r.backend.BoundOpenBlockRange(int64(from), block.Number().Int64())
+		if err != nil {
+			return nil, err
+		}
+		to = rpc.BlockNumber(end)
 	}
 	if to < from {
 		return []*Block{}, nil
 	}
 	ret := make([]*Block, 0, to-from+1)
+	logsSelection := &blocksLogsSelection{
+		blocks: int64(to - from + 1),
+		limit:  r.blocksLogsLimit,
+	}
 	for i := from; i <= to; i++ {
 		numberOrHash := rpc.BlockNumberOrHashWithNumber(i)
 		ret = append(ret, &Block{
-			backend:      r.backend,
-			numberOrHash: &numberOrHash,
+			backend:       r.backend,
+			numberOrHash:  &numberOrHash,
+			logsSelection: logsSelection,
 		})
 	}
 	return ret, nil
@@ -979,6 +1402,9 @@ func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria
 	if args.Filter.Topics != nil {
 		topics = *args.Filter.Topics
 	}
+	if err := r.backend.CheckLogsTopics(topics); err != nil {
+		return nil, err
+	}
 	// Construct the range filter
 	filterSys := filters.NewFilterSystem(r.backend, filters.Config{})
 	filter := filterSys.NewRangeFilter(begin, end, addresses, topics)
@@ -1004,12 +1430,27 @@ func (s *StorageResult) IpldBlock(ctx context.Context) hexutil.Bytes {
 	return hexutil.Bytes(s.ipldBlock)
 }
 
+// GetStorageAt returns the storage value at the block with the provided hash or, if the hash is absent or
+// the zero hash, at the canonical block with the provided number. The hash is preferred if both are supplied.
 func (r *Resolver) GetStorageAt(ctx context.Context, args struct {
-	BlockHash common.Hash
-	Contract  common.Address
-	Slot      common.Hash
+	BlockHash   *common.Hash
+	BlockNumber *BigInt
+	Contract    common.Address
+	Slot        common.Hash
 }) (*StorageResult, error) {
-	cid, ipldBlock, rlpValue, err := r.backend.IPLDRetriever.RetrieveStorageAtByAddressAndStorageSlotAndBlockHash(args.Contract, args.Slot, args.BlockHash)
+	var (
+		cid                 string
+		ipldBlock, rlpValue []byte
+		err                 error
+	)
+	switch {
+	case args.BlockHash != nil && *args.BlockHash != (common.Hash{}):
+		cid, ipldBlock, rlpValue, err = r.backend.IPLDRetriever.RetrieveStorageAtByAddressAndStorageSlotAndBlockHash(args.Contract, args.Slot, *args.BlockHash)
+	case args.BlockNumber != nil:
+		cid, ipldBlock, rlpValue, err = r.backend.IPLDRetriever.RetrieveStorageAtByAddressAndStorageSlotAndBlockNumber(args.Contract, args.Slot, args.BlockNumber.ToInt().Uint64())
+	default:
+		return nil, errors.New("either blockHash or blockNumber must be provided")
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -1035,39 +1476,182 @@ func (r *Resolver) GetStorageAt(ctx context.Context, args struct {
 	return &ret, nil
 }
 
-func (r *Resolver) GetLogs(ctx context.Context, args struct {
-	BlockHash   common.Hash
-	BlockNumber *BigInt
-	Addresses   *[]common.Address
-}) (*[]*Log, error) {
-	var filter eth.ReceiptFilter
+type StateLeafResult struct {
+	cid       string
+	ipldBlock []byte
+}
 
-	if args.Addresses != nil {
-		filter.LogAddresses = make([]string, len(*args.Addresses))
-		for i, address := range *args.Addresses {
-			filter.LogAddresses[i] = address.String()
-		}
-	}
+func (s *StateLeafResult) Cid(ctx context.Context) string {
+	return s.cid
+}
 
-	// Begin tx
-	tx, err := r.backend.DB.Beginx()
-	if err != nil {
-		return nil, err
-	}
+func (s *StateLeafResult) IpldBlock(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(s.ipldBlock)
+}
 
-	filteredLogs, err := r.backend.Retriever.RetrieveFilteredGQLLogs(tx, filter, &args.BlockHash, args.BlockNumber.ToInt())
+func (r *Resolver) StateLeaf(ctx context.Context, args struct {
+	BlockHash common.Hash
+	Address   common.Address
+}) (*StateLeafResult, error) {
+	cid, ipldBlock, _, err := r.backend.IPLDRetriever.RetrieveStateLeafByAddressAndBlockHash(args.Address, args.BlockHash)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
 		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		return nil, err
+	// the account has been removed at this block
+	if cid == "" {
+		return nil, nil
 	}
 
-	rctLog := decomposeGQLLogs(filteredLogs)
+	return &StateLeafResult{cid: cid, ipldBlock: ipldBlock}, nil
+}
+
+// logsArgs are the arguments of the getLogs and getLogsPage queries
+type logsArgs struct {
+	BlockHash   *common.Hash
+	BlockNumber *BigInt
+	FromBlock   *BigInt
+	ToBlock     *BigInt
+	Addresses   *[]common.Address
+	Topics      *[][]common.Hash
+}
+
+func (r *Resolver) GetLogs(ctx context.Context, args logsArgs) (*[]*Log, error) {
+	logs, next, err := r.filterLogs(ctx, args)
 	if err != nil {
 		return nil, err
 	}
+	if next != 0 {
+		return nil, r.backend.LogsResultCountError()
+	}
+	return &logs, nil
+}
+
+// LogsPage is the result of a getLogsPage query
+type LogsPage struct {
+	logs      []*Log
+	nextBlock int64
+}
+
+func (p *LogsPage) Logs(ctx context.Context) []*Log {
+	return p.logs
+}
+
+func (p *LogsPage) Truncated(ctx context.Context) bool {
+	return p.nextBlock != 0
+}
+
+func (p *LogsPage) NextBlock(ctx context.Context) *BigInt {
+	if p.nextBlock == 0 {
+		return nil
+	}
+	return new(BigInt).SetUint64(uint64(p.nextBlock))
+}
+
+// GetLogsPage returns the logs matching the filter over the whole blocks within the configured cap,
+// along with the block the query resumes from if more logs match beyond it
+func (r *Resolver) GetLogsPage(ctx context.Context, args logsArgs) (*LogsPage, error) {
+	logs, next, err := r.filterLogs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	// a block is never split across pages, so a block exceeding the cap on its own cannot be paged
+	if next != 0 && len(logs) == 0 {
+		return nil, fmt.Errorf("the logs of block %d exceed the limit of %d on their own; narrow the filter", next, r.backend.Config.LogsMaxResults)
+	}
+	return &LogsPage{logs: logs, nextBlock: next}, nil
+}
+
+// filterLogs returns the logs matching the filter of a getLogs or getLogsPage query. Logs over a block range are
+// retrieved up to the configured cap, stopping at the first block taking them over it, whose number is returned;
+// the number is 0 if the logs are within the cap
+func (r *Resolver) filterLogs(ctx context.Context, args logsArgs) ([]*Log, int64, error) {
+	var filter eth.ReceiptFilter
+
+	// Logs are retrieved either for a block hash or over a block range (inclusive)
+	var from, to int64
+	if args.BlockHash != nil {
+		if args.FromBlock != nil || args.ToBlock != nil {
+			return nil, 0, fmt.Errorf("provide either a block hash or a block range, not both")
+		}
+	} else {
+		if args.FromBlock == nil {
+			return nil, 0, fmt.Errorf("provide a block hash or a block range")
+		}
+		from = args.FromBlock.ToInt().Int64()
+		if args.ToBlock != nil {
+			to = args.ToBlock.ToInt().Int64()
+		} else {
+			var err error
+			if to, err = r.backend.Retriever.RetrieveLastBlockNumber(); err != nil {
+				return nil, 0, err
+			}
+		}
+		if err := r.backend.CheckLogsBlockRange(from, to); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if args.Addresses != nil {
+		filter.LogAddresses = make([]string, len(*args.Addresses))
+		for i, address := range *args.Addresses {
+			filter.LogAddresses[i] = address.String()
+		}
+	}
+
+	// Topics match a prefix of the log topics, as described for BlockFilterCriteria
+	if args.Topics != nil {
+		topics := *args.Topics
+		if err := r.backend.CheckLogsTopics(topics); err != nil {
+			return nil, 0, err
+		}
+		if len(topics) > 4 {
+			// don't allow more than 4 topics
+			topics = topics[:4]
+		}
+		filter.Topics = make([][]string, len(topics))
+		for i, topicSet := range topics {
+			for _, topic := range topicSet {
+				filter.Topics[i] = append(filter.Topics[i], topic.String())
+			}
+		}
+	}
+
+	// Begin tx
+	tx, err := r.backend.DB.Beginx()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var filteredLogs []eth.LogResult
+	var next int64
+	if args.BlockHash != nil {
+		filteredLogs, err = r.backend.Retriever.RetrieveFilteredGQLLogs(tx, filter, args.BlockHash, args.BlockNumber.ToInt())
+		if err == nil {
+			err = r.backend.CheckLogsResultCount(len(filteredLogs))
+		}
+		if err != nil {
+			shared.Rollback(tx)
+			return nil, 0, err
+		}
+	} else {
+		// stop between blocks once the field's deadline has passed
+		filteredLogs, next, err = r.backend.Retriever.RetrieveFilteredCanonicalLogsInRange(ctx, tx, filter, from, to, r.backend.Config.LogsMaxResults)
+		if err != nil {
+			shared.Rollback(tx)
+			return nil, 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+
+	rctLog := decomposeGQLLogs(filteredLogs)
 
 	ret := make([]*Log, 0, 10)
 	for _, l := range rctLog {
@@ -1084,7 +1668,7 @@ func (r *Resolver) GetLogs(ctx context.Context, args struct {
 		})
 	}
 
-	return &ret, nil
+	return ret, next, nil
 }
 
 type logsCID struct {
@@ -1138,6 +1722,7 @@ type EthTransactionCID struct {
 	src       string
 	dst       string
 	ipfsBlock IPFSBlock
+	data      *hexutil.Bytes
 }
 
 func (t EthTransactionCID) Cid(ctx context.Context) string {
@@ -1164,6 +1749,20 @@ func (t EthTransactionCID) BlockByMhKey(ctx context.Context) IPFSBlock {
 	return t.ipfsBlock
 }
 
+func (t EthTransactionCID) Data(ctx context.Context) *hexutil.Bytes {
+	return t.data
+}
+
+// txInputData decodes the input data of a transaction from its IPLD block
+func txInputData(txIPLD []byte) (*hexutil.Bytes, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(txIPLD); err != nil {
+		return nil, err
+	}
+	data := hexutil.Bytes(tx.Data())
+	return &data, nil
+}
+
 type EthTransactionCIDsConnection struct {
 	nodes []*EthTransactionCID
 }
@@ -1172,6 +1771,63 @@ func (transactionCIDResult EthTransactionCIDsConnection) Nodes(ctx context.Conte
 	return transactionCIDResult.nodes
 }
 
+type GasPriceStats struct {
+	count      hexutil.Uint64
+	average    *hexutil.Big
+	percentile *hexutil.Big
+}
+
+func (s GasPriceStats) Count(ctx context.Context) hexutil.Uint64 {
+	return s.count
+}
+
+func (s GasPriceStats) Average(ctx context.Context) *hexutil.Big {
+	return s.average
+}
+
+func (s GasPriceStats) Percentile(ctx context.Context) *hexutil.Big {
+	return s.percentile
+}
+
+type GasUsedRatio struct {
+	number hexutil.Uint64
+	ratio  float64
+}
+
+func (r GasUsedRatio) Number(ctx context.Context) hexutil.Uint64 {
+	return r.number
+}
+
+func (r GasUsedRatio) Ratio(ctx context.Context) float64 {
+	return r.ratio
+}
+
+type BlockHashAt struct {
+	hash      common.Hash
+	canonical bool
+}
+
+func (h BlockHashAt) Hash(ctx context.Context) common.Hash {
+	return h.hash
+}
+
+func (h BlockHashAt) Canonical(ctx context.Context) bool {
+	return h.canonical
+}
+
+type BlockRange struct {
+	from hexutil.Uint64
+	to   hexutil.Uint64
+}
+
+func (r BlockRange) From(ctx context.Context) hexutil.Uint64 {
+	return r.from
+}
+
+func (r BlockRange) To(ctx context.Context) hexutil.Uint64 {
+	return r.to
+}
+
 type IPFSBlock struct {
 	key  string
 	data string
@@ -1197,6 +1853,8 @@ type EthHeaderCID struct {
 	receiptRoot  string
 	uncleRoot    string
 	bloom        string
+	coinbase     string
+	nodeID       string
 	transactions []*EthTransactionCID
 	ipfsBlock    IPFSBlock
 }
@@ -1245,6 +1903,14 @@ func (h EthHeaderCID) Bloom(ctx context.Context) string {
 	return h.bloom
 }
 
+func (h EthHeaderCID) Coinbase(ctx context.Context) string {
+	return h.coinbase
+}
+
+func (h EthHeaderCID) NodeId(ctx context.Context) string {
+	return h.nodeID
+}
+
 func (h EthHeaderCID) EthTransactionCidsByHeaderId(ctx context.Context) EthTransactionCIDsConnection {
 	return EthTransactionCIDsConnection{nodes: h.transactions}
 }
@@ -1254,25 +1920,86 @@ func (h EthHeaderCID) BlockByMhKey(ctx context.Context) IPFSBlock {
 }
 
 type EthHeaderCIDsConnection struct {
-	nodes []*EthHeaderCID
+	nodes    []*EthHeaderCID
+	pageInfo PageInfo
 }
 
 func (headerCIDResult EthHeaderCIDsConnection) Nodes(ctx context.Context) []*EthHeaderCID {
 	return headerCIDResult.nodes
 }
 
+func (headerCIDResult EthHeaderCIDsConnection) PageInfo(ctx context.Context) PageInfo {
+	return headerCIDResult.pageInfo
+}
+
+type PageInfo struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p PageInfo) HasNextPage(ctx context.Context) bool {
+	return p.hasNextPage
+}
+
+func (p PageInfo) EndCursor(ctx context.Context) *string {
+	return p.endCursor
+}
+
 type EthHeaderCIDCondition struct {
-	BlockNumber *BigInt
-	BlockHash   *string
+	BlockNumber     *BigInt
+	BlockHash       *string
+	FromBlockNumber *BigInt
+	ToBlockNumber   *BigInt
+}
+
+const (
+	// defaultHeaderPageSize is the number of headers in a page of allEthHeaderCids when first is not provided
+	defaultHeaderPageSize = 100
+	// maxHeaderPageSize is the max number of headers in a page of allEthHeaderCids
+	maxHeaderPageSize = 1000
+)
+
+// encodeHeaderCursor encodes the position of a header, by block number and block hash, as an opaque cursor
+func encodeHeaderCursor(blockNumber int64, blockHash string) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", blockNumber, blockHash)))
+}
+
+// decodeHeaderCursor decodes a cursor returned by encodeHeaderCursor
+func decodeHeaderCursor(cursor string) (*eth.HeaderCursor, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	blockNumber, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return &eth.HeaderCursor{BlockNumber: blockNumber, BlockHash: parts[1]}, nil
 }
 
 func (r *Resolver) AllEthHeaderCids(ctx context.Context, args struct {
 	Condition *EthHeaderCIDCondition
+	Limit     *int32
+	First     *int32
+	After     *string
 }) (*EthHeaderCIDsConnection, error) {
 	var headerCIDs []eth.HeaderCIDRecord
+	var hasNextPage bool
 	var err error
-	if args.Condition.BlockHash != nil {
-		headerCID, err := r.backend.Retriever.RetrieveHeaderAndTxCIDsByBlockHash(common.HexToHash(*args.Condition.BlockHash), args.Condition.BlockNumber.ToInt())
+	condition := args.Condition
+	if condition == nil {
+		condition = new(EthHeaderCIDCondition)
+	}
+	paginated := args.First != nil || args.After != nil || condition.FromBlockNumber != nil || condition.ToBlockNumber != nil
+	if condition.BlockHash != nil {
+		if paginated {
+			return nil, fmt.Errorf("pagination is not supported for a block hash condition")
+		}
+		headerCID, err := r.backend.Retriever.RetrieveHeaderAndTxCIDsByBlockHash(common.HexToHash(*condition.BlockHash), condition.BlockNumber.ToInt())
 		if err != nil {
 			if !strings.Contains(err.Error(), "not found") {
 				return nil, err
@@ -1280,11 +2007,58 @@ func (r *Resolver) AllEthHeaderCids(ctx context.Context, args struct {
 		} else {
 			headerCIDs = append(headerCIDs, headerCID)
 		}
-	} else if args.Condition.BlockNumber != nil {
-		headerCIDs, err = r.backend.Retriever.RetrieveHeaderAndTxCIDsByBlockNumber(args.Condition.BlockNumber.ToInt().Int64())
+	} else if condition.BlockNumber != nil && !paginated {
+		var limit int
+		if args.Limit != nil {
+			if *args.Limit < 0 {
+				return nil, fmt.Errorf("limit must not be negative")
+			}
+			limit = int(*args.Limit)
+		}
+		headerCIDs, err = r.backend.Retriever.RetrieveHeaderAndTxCIDsByBlockNumber(condition.BlockNumber.ToInt().Int64(), limit)
 		if err != nil {
 			return nil, err
 		}
+	} else if paginated {
+		if args.Limit != nil {
+			return nil, fmt.Errorf("limit cannot be combined with pagination, use first instead")
+		}
+		var from, to int64 = 0, math.MaxInt64
+		if condition.BlockNumber != nil {
+			from = condition.BlockNumber.ToInt().Int64()
+			to = from
+		}
+		if condition.FromBlockNumber != nil {
+			from = condition.FromBlockNumber.ToInt().Int64()
+		}
+		if condition.ToBlockNumber != nil {
+			to = condition.ToBlockNumber.ToInt().Int64()
+		}
+		var after *eth.HeaderCursor
+		if args.After != nil {
+			if after, err = decodeHeaderCursor(*args.After); err != nil {
+				return nil, err
+			}
+		}
+		first := defaultHeaderPageSize
+		if args.First != nil {
+			if *args.First <= 0 {
+				return nil, fmt.Errorf("first must be positive")
+			}
+			if *args.First > maxHeaderPageSize {
+				return nil, fmt.Errorf("first must not exceed %d", maxHeaderPageSize)
+			}
+			first = int(*args.First)
+		}
+		// one more header than requested is retrieved to tell whether there is a next page
+		headerCIDs, err = r.backend.Retriever.RetrieveHeaderAndTxCIDsInRange(from, to, after, first+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(headerCIDs) > first {
+			headerCIDs = headerCIDs[:first]
+			hasNextPage = true
+		}
 	} else {
 		return nil, fmt.Errorf("provide block number or block hash")
 	}
@@ -1328,6 +2102,8 @@ func (r *Resolver) AllEthHeaderCids(ctx context.Context, args struct {
 			receiptRoot: headerCID.RctRoot,
 			uncleRoot:   headerCID.UncleRoot,
 			bloom:       Bytes(headerCID.Bloom).String(),
+			coinbase:    headerCID.Coinbase,
+			nodeID:      headerCID.NodeID,
 			ipfsBlock: IPFSBlock{
 				key:  headerCID.IPLD.Key,
 				data: Bytes(headerCID.IPLD.Data).String(),
@@ -1347,14 +2123,27 @@ func (r *Resolver) AllEthHeaderCids(ctx context.Context, args struct {
 		resultNodes = append(resultNodes, &ethHeaderCIDNode)
 	}
 
+	pageInfo := PageInfo{hasNextPage: hasNextPage}
+	if len(headerCIDs) > 0 {
+		last := headerCIDs[len(headerCIDs)-1]
+		blockNumber, err := strconv.ParseInt(last.BlockNumber, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		endCursor := encodeHeaderCursor(blockNumber, last.BlockHash)
+		pageInfo.endCursor = &endCursor
+	}
+
 	return &EthHeaderCIDsConnection{
-		nodes: resultNodes,
+		nodes:    resultNodes,
+		pageInfo: pageInfo,
 	}, nil
 }
 
 func (r *Resolver) EthTransactionCidByTxHash(ctx context.Context, args struct {
 	TxHash      string
 	BlockNumber *BigInt
+	IncludeData bool
 }) (*EthTransactionCID, error) {
 	// Need not check args.BlockNumber for nil as .ToInt() uses a pointer receiver and returns nil if BlockNumber is nil
 	// https://stackoverflow.com/questions/42238624/calling-a-method-on-a-nil-struct-pointer-doesnt-panic-why-not
@@ -1364,7 +2153,7 @@ func (r *Resolver) EthTransactionCidByTxHash(ctx context.Context, args struct {
 		return nil, err
 	}
 
-	return &EthTransactionCID{
+	ethTxCID := &EthTransactionCID{
 		cid:    txCID.CID,
 		txHash: txCID.TxHash,
 		index:  int32(txCID.Index),
@@ -1374,5 +2163,286 @@ func (r *Resolver) EthTransactionCidByTxHash(ctx context.Context, args struct {
 			key:  txCID.IPLD.Key,
 			data: Bytes(txCID.IPLD.Data).String(),
 		},
+	}
+	if args.IncludeData {
+		if ethTxCID.data, err = txInputData(txCID.IPLD.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return ethTxCID, nil
+}
+
+func (r *Resolver) EthTransactionCidsByTxHashes(ctx context.Context, args struct {
+	TxHashes    []string
+	IncludeData bool
+}) (*EthTransactionCIDsConnection, error) {
+	txCIDs, err := r.backend.Retriever.RetrieveTxCIDsByHashes(args.TxHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*EthTransactionCID, len(txCIDs))
+	for i, txCID := range txCIDs {
+		nodes[i] = &EthTransactionCID{
+			cid:    txCID.CID,
+			txHash: txCID.TxHash,
+			index:  int32(txCID.Index),
+			src:    txCID.Src,
+			dst:    txCID.Dst,
+			ipfsBlock: IPFSBlock{
+				key:  txCID.IPLD.Key,
+				data: Bytes(txCID.IPLD.Data).String(),
+			},
+		}
+		if args.IncludeData {
+			if nodes[i].data, err = txInputData(txCID.IPLD.Data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &EthTransactionCIDsConnection{nodes: nodes}, nil
+}
+
+func (r *Resolver) StorageChangeBlocks(ctx context.Context, args struct {
+	Address common.Address
+	From    hexutil.Uint64
+	To      hexutil.Uint64
+}) ([]hexutil.Uint64, error) {
+	if err := r.backend.CheckBlockRange("storage change", int64(args.From), int64(args.To)); err != nil {
+		return nil, err
+	}
+	blockNumbers, err := r.backend.Retriever.RetrieveStorageChangeBlocks(args.Address, int64(args.From), int64(args.To))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]hexutil.Uint64, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		ret[i] = hexutil.Uint64(blockNumber)
+	}
+	return ret, nil
+}
+
+// StorageSlotChange is the value a storage slot changed to at a block
+type StorageSlotChange struct {
+	blockNumber int64
+	value       []byte
+}
+
+func (s StorageSlotChange) BlockNumber(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.blockNumber)
+}
+
+func (s StorageSlotChange) Value(ctx context.Context) common.Hash {
+	return common.BytesToHash(s.value)
+}
+
+func (r *Resolver) StorageSlotHistory(ctx context.Context, args struct {
+	Address common.Address
+	Slot    common.Hash
+	From    hexutil.Uint64
+	To      hexutil.Uint64
+}) ([]StorageSlotChange, error) {
+	if err := r.backend.CheckBlockRange("storage slot history", int64(args.From), int64(args.To)); err != nil {
+		return nil, err
+	}
+	changes, err := r.backend.IPLDRetriever.RetrieveStorageSlotHistory(args.Address, args.Slot, int64(args.From), int64(args.To))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]StorageSlotChange, len(changes))
+	for i, change := range changes {
+		ret[i] = StorageSlotChange{blockNumber: change.BlockNumber, value: change.Value}
+	}
+	return ret, nil
+}
+
+func (r *Resolver) EventSignatures(ctx context.Context, args struct {
+	Address common.Address
+	From    *hexutil.Uint64
+	To      *hexutil.Uint64
+}) ([]common.Hash, error) {
+	var from, to int64
+	if args.From != nil {
+		from = int64(*args.From)
+	}
+	if args.To != nil {
+		to = int64(*args.To)
+	} else {
+		var err error
+		if to, err = r.backend.Retriever.RetrieveLastBlockNumber(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.backend.CheckBlockRange("event signature", from, to); err != nil {
+		return nil, err
+	}
+
+	topics, err := r.backend.Retriever.RetrieveDistinctTopic0ForAddress(args.Address, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]common.Hash, len(topics))
+	for i, topic := range topics {
+		ret[i] = common.HexToHash(topic)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) LogBlockRange(ctx context.Context, args struct {
+	Address common.Address
+}) (*BlockRange, error) {
+	blockRange, err := r.backend.Retriever.RetrieveLogBlockRangeForAddress(args.Address)
+	if err != nil || blockRange == nil {
+		return nil, err
+	}
+
+	return &BlockRange{
+		from: hexutil.Uint64(blockRange.From),
+		to:   hexutil.Uint64(blockRange.To),
+	}, nil
+}
+
+func (r *Resolver) TransactionsByAddress(ctx context.Context, args struct {
+	Address   common.Address
+	FromBlock hexutil.Uint64
+	ToBlock   hexutil.Uint64
+}) ([]*Transaction, error) {
+	if err := r.backend.CheckBlockRange("transactions by address", int64(args.FromBlock), int64(args.ToBlock)); err != nil {
+		return nil, err
+	}
+	txs, err := r.backend.Retriever.RetrieveTxHashesByAddress(args.Address, int64(args.FromBlock), int64(args.ToBlock), r.backend.Config.TxsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Transaction, len(txs))
+	for i, tx := range txs {
+		blockNrOrHash := rpc.BlockNumberOrHashWithHash(common.HexToHash(tx.BlockHash), false)
+		ret[i] = &Transaction{
+			backend: r.backend,
+			hash:    common.HexToHash(tx.TxHash),
+			block: &Block{
+				backend:      r.backend,
+				numberOrHash: &blockNrOrHash,
+			},
+			index: uint64(tx.Index),
+		}
+	}
+	return ret, nil
+}
+
+func (r *Resolver) BlockNumberByHash(ctx context.Context, args struct {
+	Hash common.Hash
+}) (*hexutil.Uint64, error) {
+	number, err := r.backend.Retriever.RetrieveBlockNumberByHash(args.Hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(number)
+	return &ret, nil
+}
+
+func (r *Resolver) ContractDeploymentBlock(ctx context.Context, args struct {
+	Address common.Address
+}) (*hexutil.Uint64, error) {
+	number, err := r.backend.Retriever.RetrieveContractDeploymentBlock(args.Address)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(number)
+	return &ret, nil
+}
+
+func (r *Resolver) BlockHashesAt(ctx context.Context, args struct {
+	Number hexutil.Uint64
+}) ([]*BlockHashAt, error) {
+	hashes, err := r.backend.Retriever.RetrieveAllBlockHashesByNumber(int64(args.Number))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*BlockHashAt, len(hashes))
+	for i, hash := range hashes {
+		ret[i] = &BlockHashAt{
+			hash:      common.HexToHash(hash.BlockHash),
+			canonical: hash.Canonical,
+		}
+	}
+	return ret, nil
+}
+
+func (r *Resolver) RemovedAccounts(ctx context.Context, args struct {
+	BlockHash common.Hash
+}) ([]common.Hash, error) {
+	leafKeys, err := r.backend.Retriever.RetrieveRemovedAccountsByBlockHash(args.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]common.Hash, len(leafKeys))
+	for i, leafKey := range leafKeys {
+		ret[i] = common.HexToHash(leafKey)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) ActiveAddressCount(ctx context.Context, args struct {
+	BlockNumber hexutil.Uint64
+}) (hexutil.Uint64, error) {
+	count, err := r.backend.Retriever.RetrieveActiveAddressCountByBlockNumber(int64(args.BlockNumber))
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(count), nil
+}
+
+func (r *Resolver) GasUsedRatios(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   hexutil.Uint64
+}) ([]*GasUsedRatio, error) {
+	if err := r.backend.CheckGasUsedRatioRange(int64(args.From), int64(args.To)); err != nil {
+		return nil, err
+	}
+	ratios, err := r.backend.Retriever.RetrieveGasUsedRatioInRange(int64(args.From), int64(args.To))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*GasUsedRatio, len(ratios))
+	for i, ratio := range ratios {
+		ret[i] = &GasUsedRatio{
+			number: hexutil.Uint64(ratio.BlockNumber),
+			ratio:  ratio.Ratio,
+		}
+	}
+	return ret, nil
+}
+
+func (r *Resolver) GasPriceStats(ctx context.Context, args struct {
+	From       hexutil.Uint64
+	To         hexutil.Uint64
+	Percentile int32
+}) (*GasPriceStats, error) {
+	if err := r.backend.CheckBlockRange("gas price", int64(args.From), int64(args.To)); err != nil {
+		return nil, err
+	}
+	stats, err := r.backend.Retriever.RetrieveAverageGasPriceInRange(int64(args.From), int64(args.To), int(args.Percentile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GasPriceStats{
+		count:      hexutil.Uint64(stats.Count),
+		average:    (*hexutil.Big)(stats.Average),
+		percentile: (*hexutil.Big)(stats.Percentile),
 	}, nil
 }
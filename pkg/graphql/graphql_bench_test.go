@@ -0,0 +1,102 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/mailgun/groupcache/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/graphql"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+// BenchmarkBlocksRangeQuery runs a blocks range query which also resolves each block's parent, with and without
+// the header cache. The blocks are looked up by number, which the cache serves once they are a block below the
+// highest header it has seen, and the parents by hash; the header-cache-misses/op metric is the number of header
+// queries made against the database per query.
+func BenchmarkBlocksRangeQuery(b *testing.B) {
+	RegisterTestingT(b)
+	const rangeSize = 10
+
+	db := shared.SetupDB()
+	defer shared.TearDownDB(db)
+	chainConfig := *params.TestChainConfig
+	indexer := shared.SetupTestStateDiffIndexer(context.Background(), &chainConfig, test_helpers.Genesis.Hash())
+	blocks, receipts, _ := test_helpers.MakeChain(rangeSize, test_helpers.Genesis, test_helpers.TestChainGen)
+	for i, block := range blocks {
+		var rcts types.Receipts
+		if i > 0 {
+			rcts = receipts[i-1]
+		}
+		tx, err := indexer.PushBlock(block, rcts, block.Difficulty())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tx.Submit(err)).To(Succeed())
+	}
+
+	query := fmt.Sprintf(`{"query": "{ blocks(from: 1, to: %d) { number hash parent { number hash } } }"}`, rangeSize)
+	for _, cacheSize := range []int{0, 1024} {
+		b.Run(fmt.Sprintf("header cache size %d", cacheSize), func(b *testing.B) {
+			groupName := fmt.Sprintf("graphql_bench_test_%d", cacheSize)
+			defer groupcache.DeregisterGroup(groupName)
+			backend, err := eth.NewEthBackend(db, &eth.Config{
+				ChainConfig: &chainConfig,
+				VMConfig:    vm.Config{},
+				RPCGasCap:   big.NewInt(10000000000),
+				GroupCacheConfig: &shared.GroupCacheConfig{
+					StateDB: shared.GroupConfig{
+						Name:              groupName,
+						CacheSizeInMB:     8,
+						CacheExpiryInMins: 60,
+					},
+					HeaderCacheSize:           cacheSize,
+					HeaderCacheFinalizedDepth: 1,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			handler, err := graphql.NewHandler(backend, 0, 0, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rec := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(query))
+				req.Header.Set("Content-Type", "application/json")
+				handler.ServeHTTP(rec, req)
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Body.String()).ToNot(ContainSubstring(`"errors"`))
+			}
+			b.StopTimer()
+
+			stats := backend.HeaderCache.Stats()
+			b.ReportMetric(float64(stats.Misses)/float64(b.N), "header-cache-misses/op")
+			b.ReportMetric(float64(stats.Hits)/float64(b.N), "header-cache-hits/op")
+		})
+	}
+}
@@ -17,9 +17,13 @@
 package graphql_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -29,10 +33,14 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/statediff"
+	"github.com/ethereum/go-ethereum/statediff/indexer/ipld"
 	sdtypes "github.com/ethereum/go-ethereum/statediff/types"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/jmoiron/sqlx"
+	"github.com/multiformats/go-multihash"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -45,11 +53,16 @@ import (
 
 var _ = Describe("GraphQL", func() {
 	const (
-		gqlEndPoint = "127.0.0.1:8083"
+		gqlEndPoint     = "127.0.0.1:8083"
+		corsMaxAge      = 3600
+		blocksLogsLimit = 4
 	)
 	var (
 		randomAddr      = common.HexToAddress("0x1C3ab14BBaD3D99F4203bd7a11aCB94882050E6f")
 		randomHash      = crypto.Keccak256Hash(randomAddr.Bytes())
+		removedLeafKey  = crypto.Keccak256Hash([]byte("removed account"))
+		emitterAddr     = common.HexToAddress("0x5e1f3c7d9a2b4e6f8091a2b3c4d5e6f708192a3b")
+		transferTopics  = []common.Hash{graphql.TransferTopic, common.BytesToHash(test_helpers.Account1Addr.Bytes()), common.BytesToHash(test_helpers.Account2Addr.Bytes())}
 		blocks          []*types.Block
 		receipts        []types.Receipts
 		chain           *core.BlockChain
@@ -87,6 +100,21 @@ var _ = Describe("GraphQL", func() {
 
 		// make the test blockchain (and state)
 		blocks, receipts, chain = test_helpers.MakeChain(5, test_helpers.Genesis, test_helpers.TestChainGen)
+
+		// the test chain emits no events, so attach an ERC-20 transfer of i tokens to the contract calls in
+		// blocks 3 and 4 to have canonical logs to retrieve
+		for _, i := range []int{3, 4} {
+			rct := receipts[i-1][0]
+			rct.Logs = append(rct.Logs, &types.Log{
+				Address:     emitterAddr,
+				Topics:      transferTopics,
+				Data:        common.BigToHash(big.NewInt(int64(i))).Bytes(),
+				BlockNumber: blocks[i].NumberU64(),
+				TxHash:      rct.TxHash,
+				BlockHash:   blocks[i].Hash(),
+			})
+			rct.Bloom = types.CreateBloom(types.Receipts{rct})
+		}
 		params := statediff.Params{
 			IntermediateStateNodes:   true,
 			IntermediateStorageNodes: true,
@@ -127,6 +155,17 @@ var _ = Describe("GraphQL", func() {
 				Expect(err).ToNot(HaveOccurred())
 			}
 
+			// the test chain has no self-destructs, so index an account removal in the head block
+			if i == len(blocks)-1 {
+				err = transformer.PushStateNode(tx, sdtypes.StateNode{
+					LeafKey:   removedLeafKey.Bytes(),
+					Path:      []byte{'\x0f', '\x0f', '\x0f'},
+					NodeType:  sdtypes.Removed,
+					NodeValue: []byte{},
+				}, block.Hash().String())
+				Expect(err).ToNot(HaveOccurred())
+			}
+
 			err = tx.Submit(err)
 			Expect(err).ToNot(HaveOccurred())
 		}
@@ -158,7 +197,13 @@ var _ = Describe("GraphQL", func() {
 		err = tx.Submit(err)
 		Expect(err).ToNot(HaveOccurred())
 
-		graphQLServer, err = graphql.New(backend, gqlEndPoint, nil, []string{"*"}, rpc.HTTPTimeouts{})
+		graphQLServer, err = graphql.New(backend, graphql.Config{
+			Endpoint:        gqlEndPoint,
+			CORS:            []string{"*"},
+			CORSMaxAge:      corsMaxAge,
+			VHosts:          []string{"*"},
+			BlocksLogsLimit: blocksLogsLimit,
+		})
 		Expect(err).ToNot(HaveOccurred())
 
 		err = graphQLServer.Start(nil)
@@ -172,6 +217,22 @@ var _ = Describe("GraphQL", func() {
 		chain.Stop()
 	})
 
+	Describe("CORS preflight", func() {
+		It("Sets the configured Access-Control-Max-Age on preflight responses", func() {
+			req, err := http.NewRequest(http.MethodOptions, fmt.Sprintf("http://%s/graphql", gqlEndPoint), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Origin", "http://example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			res, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer res.Body.Close()
+
+			Expect(res.Header.Get("Access-Control-Allow-Origin")).To(Equal("*"))
+			Expect(res.Header.Get("Access-Control-Max-Age")).To(Equal(strconv.Itoa(corsMaxAge)))
+		})
+	})
+
 	Describe("eth_getLogs", func() {
 		It("Retrieves logs that matches the provided blockHash and contract address", func() {
 			logs, err := client.GetLogs(ctx, blockHash, []common.Address{contractAddress})
@@ -242,6 +303,127 @@ var _ = Describe("GraphQL", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(logs)).To(Equal(0))
 		})
+
+		It("Retrieves the canonical logs over a block range", func() {
+			logs, err := client.GetRangeLogs(ctx, 1, 5, []common.Address{emitterAddr})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(2))
+			for i, l := range logs {
+				Expect(l.Transaction.Hash).To(Equal(blocks[i+3].Transactions()[0].Hash()))
+			}
+
+			// the mock block at height 1 and its child at height 2 are not canonical
+			logs, err = client.GetRangeLogs(ctx, 1, 2, []common.Address{contractAddress})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logs).To(BeEmpty())
+
+			logs, err = client.GetRangeLogs(ctx, 1, 5, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(2))
+		})
+
+		It("Rejects a block range exceeding the limit", func() {
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			_, err := client.GetRangeLogs(ctx, 1, 2, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetRangeLogs(ctx, 1, 3, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("log filter spans 3 blocks, exceeding the limit of 2"))
+		})
+
+		It("Rejects logs exceeding the result cap, or truncates them to a page of whole blocks", func() {
+			all, err := client.GetRangeLogs(ctx, 1, 5, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(all)).To(Equal(2))
+
+			backend.Config.LogsMaxResults = 1
+			defer func() { backend.Config.LogsMaxResults = 0 }()
+
+			_, err = client.GetRangeLogs(ctx, 1, 5, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("log filter matches more logs than the limit of 1"))
+
+			_, err = client.GetLogs(ctx, blockHash, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("log filter matches more logs than the limit of 1"))
+
+			// the logs of blocks 3 and 4 are split across two pages
+			page, err := client.GetRangeLogsPage(ctx, 1, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Truncated).To(BeTrue())
+			Expect(page.Logs).To(Equal(all[:1]))
+			Expect(page.NextBlock).To(Equal((*hexutil.Big)(big.NewInt(4))))
+
+			page, err = client.GetRangeLogsPage(ctx, 4, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Truncated).To(BeFalse())
+			Expect(page.Logs).To(Equal(all[1:]))
+			Expect(page.NextBlock).To(BeNil())
+
+			backend.Config.LogsMaxResults = 2
+			page, err = client.GetRangeLogsPage(ctx, 1, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Truncated).To(BeFalse())
+			Expect(page.Logs).To(Equal(all))
+		})
+
+		It("Retrieves logs that match topics in multiple positions", func() {
+			logs, err := client.GetTopicFilteredLogs(ctx, blockHash, nil, [][]common.Hash{
+				{test_helpers.MockLog1.Topics[0]},
+				{test_helpers.MockLog1.Topics[1]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(1))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog1.Topics))
+
+			logs, err = client.GetTopicFilteredLogs(ctx, blockHash, nil, [][]common.Hash{
+				{test_helpers.MockLog4.Topics[0]},
+				{},
+				{test_helpers.MockLog4.Topics[2]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(1))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog4.Topics))
+		})
+
+		It("Retrieves logs that match any of the alternative topics in a position", func() {
+			logs, err := client.GetTopicFilteredLogs(ctx, blockHash, nil, [][]common.Hash{
+				{test_helpers.MockLog1.Topics[0], test_helpers.MockLog2.Topics[0]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(2))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog1.Topics))
+			Expect(logs[1].Topics).To(Equal(test_helpers.MockLog2.Topics))
+
+			logs, err = client.GetTopicFilteredLogs(ctx, blockHash, nil, [][]common.Hash{
+				{},
+				{test_helpers.MockLog2.Topics[1]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(1))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog2.Topics))
+		})
+
+		It("Retrieves no logs when the topics only match a shorter topic list", func() {
+			logs, err := client.GetTopicFilteredLogs(ctx, blockHash, nil, [][]common.Hash{
+				{test_helpers.MockLog3.Topics[0]},
+				{test_helpers.MockLog4.Topics[0]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(0))
+		})
+
+		It("Retrieves logs that match both the topics and contract addresses", func() {
+			logs, err := client.GetTopicFilteredLogs(ctx, blockHash, []common.Address{contractAddress, test_helpers.AnotherAddress2}, [][]common.Hash{
+				{test_helpers.MockLog6.Topics[0]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(logs)).To(Equal(1))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog6.Topics))
+		})
 	})
 
 	Describe("eth_getStorageAt", func() {
@@ -276,12 +458,206 @@ var _ = Describe("GraphQL", func() {
 		})
 	})
 
+	Describe("storageSlotHistory", func() {
+		It("Retrieves the values the slot changed to at each canonical block in the range", func() {
+			history, err := client.GetStorageSlotHistory(ctx, contractAddress, common.HexToHash(test_helpers.IndexOne), 0, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(history).To(Equal([]graphql.StorageSlotChangeResponse{
+				{BlockNumber: 2, Value: common.HexToHash("01")},
+				{BlockNumber: 3, Value: common.HexToHash("03")},
+				{BlockNumber: 4, Value: common.HexToHash("09")},
+				{BlockNumber: 5, Value: common.Hash{}},
+			}))
+		})
+
+		It("Retrieves only the changes within the range", func() {
+			history, err := client.GetStorageSlotHistory(ctx, contractAddress, common.HexToHash(test_helpers.IndexOne), 3, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(history).To(Equal([]graphql.StorageSlotChangeResponse{
+				{BlockNumber: 3, Value: common.HexToHash("03")},
+				{BlockNumber: 4, Value: common.HexToHash("09")},
+			}))
+		})
+
+		It("Retrieves no changes for a slot that was never set", func() {
+			history, err := client.GetStorageSlotHistory(ctx, contractAddress, randomHash, 0, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(history).To(BeEmpty())
+		})
+
+		It("Rejects a range which is reversed or exceeds the limit", func() {
+			slot := common.HexToHash(test_helpers.IndexOne)
+			_, err := client.GetStorageSlotHistory(ctx, contractAddress, slot, 4, 3)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storage slot history range from 4 is after to 3"))
+
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			history, err := client.GetStorageSlotHistory(ctx, contractAddress, slot, 3, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(history).To(HaveLen(2))
+
+			_, err = client.GetStorageSlotHistory(ctx, contractAddress, slot, 0, 5)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storage slot history range spans 6 blocks, exceeding the limit of 2"))
+		})
+	})
+
+	Describe("storageChangeBlocks", func() {
+		It("Retrieves the canonical blocks at which the storage of the contract changed", func() {
+			blockNumbers, err := client.GetStorageChangeBlocks(ctx, contractAddress, 0, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockNumbers).To(Equal([]hexutil.Uint64{2, 3, 4, 5}))
+		})
+
+		It("Retrieves only the blocks within the range", func() {
+			blockNumbers, err := client.GetStorageChangeBlocks(ctx, contractAddress, 3, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockNumbers).To(Equal([]hexutil.Uint64{3, 4}))
+		})
+
+		It("Retrieves no blocks for an address without storage", func() {
+			blockNumbers, err := client.GetStorageChangeBlocks(ctx, randomAddr, 0, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockNumbers).To(BeEmpty())
+		})
+
+		It("Rejects a range which is reversed or exceeds the limit", func() {
+			_, err := client.GetStorageChangeBlocks(ctx, contractAddress, 4, 3)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storage change range from 4 is after to 3"))
+
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			blockNumbers, err := client.GetStorageChangeBlocks(ctx, contractAddress, 3, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockNumbers).To(Equal([]hexutil.Uint64{3, 4}))
+
+			_, err = client.GetStorageChangeBlocks(ctx, contractAddress, 0, 5)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storage change range spans 6 blocks, exceeding the limit of 2"))
+		})
+	})
+
+	Describe("eth_getStorageAt by block number", func() {
+		It("Retrieves the storage value at the provided contract address and storage leaf key at the canonical block with the provided number", func() {
+			storageRes, err := client.GetStorageAtByNumber(ctx, 2, contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storageRes.Value).To(Equal(common.HexToHash("01")))
+
+			storageRes, err = client.GetStorageAtByNumber(ctx, 3, contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storageRes.Value).To(Equal(common.HexToHash("03")))
+
+			storageRes, err = client.GetStorageAtByNumber(ctx, 4, contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storageRes.Value).To(Equal(common.HexToHash("09")))
+		})
+
+		It("Retrieves the same cid and IPLD block as the lookup by block hash", func() {
+			byHash, err := client.GetStorageAt(ctx, blockHashes[3], contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			byNumber, err := client.GetStorageAtByNumber(ctx, 3, contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(byNumber).To(Equal(byHash))
+		})
+
+		It("Retrieves empty data if the slot does not exist or has been removed at the provided block number", func() {
+			storageRes, err := client.GetStorageAtByNumber(ctx, 1, contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storageRes.Value).To(Equal(common.Hash{}))
+
+			storageRes, err = client.GetStorageAtByNumber(ctx, 5, contractAddress, test_helpers.IndexOne)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storageRes.Value).To(Equal(common.Hash{}))
+
+			storageRes, err = client.GetStorageAtByNumber(ctx, 3, contractAddress, randomHash.Hex())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storageRes.Value).To(Equal(common.Hash{}))
+		})
+	})
+
+	Describe("codeChunk", func() {
+		It("Retrieves chunks which concatenate to the full contract code", func() {
+			statedb, err := chain.StateAt(blocks[3].Root())
+			Expect(err).ToNot(HaveOccurred())
+			code := statedb.GetCode(contractAddress)
+			Expect(code).ToNot(BeEmpty())
+
+			const chunkLength = 100
+			var chunks []byte
+			for offset := uint64(0); ; offset += chunkLength {
+				chunk, err := client.GetCodeChunk(ctx, contractAddress, 3, offset, chunkLength)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(chunk)).To(BeNumerically("<=", chunkLength))
+				chunks = append(chunks, chunk...)
+				if len(chunk) < chunkLength {
+					break
+				}
+			}
+			Expect(chunks).To(Equal(code))
+		})
+
+		It("Retrieves an empty chunk for an offset beyond the end of the code", func() {
+			chunk, err := client.GetCodeChunk(ctx, contractAddress, 3, 1<<20, 100)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chunk).To(BeEmpty())
+		})
+
+		It("Retrieves an empty chunk for an account without code", func() {
+			chunk, err := client.GetCodeChunk(ctx, test_helpers.Account1Addr, 3, 0, 100)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chunk).To(BeEmpty())
+		})
+	})
+
+	Describe("stateLeaf", func() {
+		It("Retrieves the cid and IPLD block of the indexed state leaf for the account at the provided block hash", func() {
+			stateLeaf, err := client.GetStateLeaf(ctx, blockHashes[3], contractAddress)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stateLeaf).ToNot(BeNil())
+
+			var indexedCID string
+			leafKey := crypto.Keccak256Hash(contractAddress.Bytes())
+			err = db.Get(&indexedCID, `SELECT cid FROM eth.state_cids WHERE state_leaf_key = $1 AND header_id = $2`,
+				leafKey.Hex(), blockHashes[3].Hex())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stateLeaf.CID).To(Equal(indexedCID))
+
+			leafCID, err := ipld.RawdataToCid(ipld.MEthStateTrie, stateLeaf.IpldBlock, multihash.KECCAK_256)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(leafCID.String()).To(Equal(stateLeaf.CID))
+
+			var leafNode [][]byte
+			err = rlp.DecodeBytes(stateLeaf.IpldBlock, &leafNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(leafNode).To(HaveLen(2))
+			var account types.StateAccount
+			err = rlp.DecodeBytes(leafNode[1], &account)
+			Expect(err).ToNot(HaveOccurred())
+
+			statedb, err := chain.StateAt(blocks[3].Root())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(account.Nonce).To(Equal(statedb.GetNonce(contractAddress)))
+			Expect(account.Balance).To(Equal(statedb.GetBalance(contractAddress)))
+			Expect(account.CodeHash).To(Equal(statedb.GetCodeHash(contractAddress).Bytes()))
+		})
+
+		It("Returns null for an account which does not exist at the provided block hash", func() {
+			stateLeaf, err := client.GetStateLeaf(ctx, blockHashes[3], randomAddr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stateLeaf).To(BeNil())
+		})
+	})
+
 	Describe("allEthHeaderCids", func() {
 		It("Retrieves header_cids that matches the provided blockNumber", func() {
-			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockNumber: new(graphql.BigInt).SetUint64(2)})
+			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockNumber: new(graphql.BigInt).SetUint64(2)}, 0)
 			Expect(err).ToNot(HaveOccurred())
 
-			headerCIDs, err := backend.Retriever.RetrieveHeaderAndTxCIDsByBlockNumber(2)
+			headerCIDs, err := backend.Retriever.RetrieveHeaderAndTxCIDsByBlockNumber(2, 0)
 			Expect(err).ToNot(HaveOccurred())
 
 			for idx, headerCID := range headerCIDs {
@@ -291,9 +667,36 @@ var _ = Describe("GraphQL", func() {
 			}
 		})
 
+		It("Retrieves the canonical header_cid first at a height with multiple headers", func() {
+			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockNumber: new(graphql.BigInt).SetUint64(1)}, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(allEthHeaderCIDsResp.Nodes)).To(Equal(2))
+			Expect(allEthHeaderCIDsResp.Nodes[0].BlockHash).To(Equal(blocks[1].Hash().String()))
+			Expect(allEthHeaderCIDsResp.Nodes[1].BlockHash).To(Equal(test_helpers.MockBlock.Hash().String()))
+
+			headerCIDs, err := backend.Retriever.RetrieveHeaderAndTxCIDsByBlockNumber(1, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(headerCIDs)).To(Equal(2))
+			for idx, headerCID := range headerCIDs {
+				compareEthHeaderCID(allEthHeaderCIDsResp.Nodes[idx], headerCID)
+			}
+		})
+
+		It("Retrieves only the canonical header_cid when limited to one header", func() {
+			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockNumber: new(graphql.BigInt).SetUint64(1)}, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(allEthHeaderCIDsResp.Nodes)).To(Equal(1))
+			Expect(allEthHeaderCIDsResp.Nodes[0].BlockHash).To(Equal(blocks[1].Hash().String()))
+
+			headerCIDs, err := backend.Retriever.RetrieveHeaderAndTxCIDsByBlockNumber(1, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(headerCIDs)).To(Equal(1))
+			Expect(headerCIDs[0].BlockHash).To(Equal(blocks[1].Hash().String()))
+		})
+
 		It("Retrieves header_cids that matches the provided blockHash", func() {
 			blockHash := blocks[1].Hash().String()
-			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockHash: &blockHash})
+			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockHash: &blockHash}, 0)
 			Expect(err).ToNot(HaveOccurred())
 
 			headerCID, err := backend.Retriever.RetrieveHeaderAndTxCIDsByBlockHash(blocks[1].Hash(), nil)
@@ -303,12 +706,97 @@ var _ = Describe("GraphQL", func() {
 			ethHeaderCID := allEthHeaderCIDsResp.Nodes[0]
 			compareEthHeaderCID(ethHeaderCID, headerCID)
 		})
+
+		It("Retrieves the coinbase and node_id of the indexed header row", func() {
+			blockHash := blocks[2].Hash().String()
+			allEthHeaderCIDsResp, err := client.AllEthHeaderCIDs(ctx, graphql.EthHeaderCIDCondition{BlockHash: &blockHash}, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(allEthHeaderCIDsResp.Nodes)).To(Equal(1))
+
+			var headerRow struct {
+				Coinbase string `db:"coinbase"`
+				NodeID   string `db:"node_id"`
+			}
+			err = db.Get(&headerRow, `SELECT coinbase, node_id FROM eth.header_cids WHERE block_hash = $1`, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(allEthHeaderCIDsResp.Nodes[0].Coinbase).To(Equal(headerRow.Coinbase))
+			Expect(allEthHeaderCIDsResp.Nodes[0].Coinbase).To(Equal(blocks[2].Coinbase().String()))
+			Expect(allEthHeaderCIDsResp.Nodes[0].NodeID).To(Equal(headerRow.NodeID))
+			Expect(allEthHeaderCIDsResp.Nodes[0].NodeID).To(Equal("1"))
+		})
+
+		It("Paginates over the headers in a block number range", func() {
+			// heights 1 and 2 each have a canonical and a non-canonical header
+			condition := graphql.EthHeaderCIDCondition{
+				FromBlockNumber: new(graphql.BigInt).SetUint64(1),
+				ToBlockNumber:   new(graphql.BigInt).SetUint64(3),
+			}
+			var hashes []string
+			var after *string
+			for _, expected := range []struct {
+				size        int
+				hasNextPage bool
+			}{{2, true}, {2, true}, {1, false}} {
+				page, err := client.AllEthHeaderCIDsPage(ctx, condition, 2, after)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(page.Nodes)).To(Equal(expected.size))
+				Expect(page.PageInfo.HasNextPage).To(Equal(expected.hasNextPage))
+				Expect(page.PageInfo.EndCursor).ToNot(BeNil())
+				for _, node := range page.Nodes {
+					hashes = append(hashes, node.BlockHash)
+				}
+				after = page.PageInfo.EndCursor
+			}
+
+			// the headers are ordered by block number and then block hash
+			Expect(hashes[:2]).To(ConsistOf(blocks[1].Hash().String(), test_helpers.MockBlock.Hash().String()))
+			Expect(hashes[2:4]).To(ConsistOf(blocks[2].Hash().String(), test_helpers.MockChild.Hash().String()))
+			Expect(hashes[4]).To(Equal(blocks[3].Hash().String()))
+
+			page, err := client.AllEthHeaderCIDsPage(ctx, condition, 2, after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Nodes).To(BeEmpty())
+			Expect(page.PageInfo.HasNextPage).To(BeFalse())
+			Expect(page.PageInfo.EndCursor).To(BeNil())
+		})
+
+		It("Paginates over the headers at a block number", func() {
+			condition := graphql.EthHeaderCIDCondition{BlockNumber: new(graphql.BigInt).SetUint64(1)}
+			page, err := client.AllEthHeaderCIDsPage(ctx, condition, 1, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(page.Nodes)).To(Equal(1))
+			Expect(page.PageInfo.HasNextPage).To(BeTrue())
+
+			next, err := client.AllEthHeaderCIDsPage(ctx, condition, 1, page.PageInfo.EndCursor)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(next.Nodes)).To(Equal(1))
+			Expect(next.PageInfo.HasNextPage).To(BeFalse())
+			Expect([]string{page.Nodes[0].BlockHash, next.Nodes[0].BlockHash}).To(ConsistOf(
+				blocks[1].Hash().String(), test_helpers.MockBlock.Hash().String(),
+			))
+		})
+
+		It("Rejects an invalid cursor", func() {
+			condition := graphql.EthHeaderCIDCondition{BlockNumber: new(graphql.BigInt).SetUint64(1)}
+			invalid := "not a cursor"
+			_, err := client.AllEthHeaderCIDsPage(ctx, condition, 1, &invalid)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid cursor"))
+		})
+
+		It("Rejects a page size over the limit", func() {
+			condition := graphql.EthHeaderCIDCondition{FromBlockNumber: new(graphql.BigInt).SetUint64(1)}
+			_, err := client.AllEthHeaderCIDsPage(ctx, condition, 1001, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("first must not exceed 1000"))
+		})
 	})
 
 	Describe("ethTransactionCidByTxHash", func() {
 		It("Retrieves tx_cid that matches the provided txHash", func() {
 			txHash := blocks[2].Transactions()[0].Hash().String()
-			ethTransactionCIDResp, err := client.EthTransactionCIDByTxHash(ctx, txHash)
+			ethTransactionCIDResp, err := client.EthTransactionCIDByTxHash(ctx, txHash, false)
 			Expect(err).ToNot(HaveOccurred())
 
 			txCID, err := backend.Retriever.RetrieveTxCIDByHash(txHash, nil)
@@ -317,42 +805,1324 @@ var _ = Describe("GraphQL", func() {
 			compareEthTxCID(*ethTransactionCIDResp, txCID)
 
 			Expect(ethTransactionCIDResp.BlockByMhKey.Data).To(Equal(graphql.Bytes(txCID.IPLD.Data).String()))
+			Expect(ethTransactionCIDResp.Data).To(BeNil())
+		})
+
+		It("Includes the tx input data only when requested", func() {
+			tx := blocks[3].Transactions()[0]
+			ethTransactionCIDResp, err := client.EthTransactionCIDByTxHash(ctx, tx.Hash().String(), true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ethTransactionCIDResp.Data).ToNot(BeNil())
+			Expect([]byte(*ethTransactionCIDResp.Data)).To(Equal(tx.Data()))
+
+			ethTransactionCIDResp, err = client.EthTransactionCIDByTxHash(ctx, tx.Hash().String(), false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ethTransactionCIDResp.Data).To(BeNil())
 		})
 	})
-})
 
-func compareEthHeaderCID(ethHeaderCID graphql.EthHeaderCIDResponse, headerCID eth.HeaderCIDRecord) {
-	blockNumber, err := strconv.ParseInt(headerCID.BlockNumber, 10, 64)
-	Expect(err).ToNot(HaveOccurred())
+	Describe("ethTransactionCidsByTxHashes", func() {
+		It("Retrieves the canonical tx_cids that match the provided txHashes", func() {
+			txHashes := []string{
+				blocks[2].Transactions()[0].Hash().String(),
+				blocks[2].Transactions()[2].Hash().String(),
+				blocks[3].Transactions()[0].Hash().String(),
+				// non-canonical tx
+				test_helpers.MockTransactions[1].Hash().String(),
+			}
+			ethTransactionCIDsResp, err := client.EthTransactionCIDsByTxHashes(ctx, txHashes, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(ethTransactionCIDsResp)).To(Equal(3))
 
-	td, err := strconv.ParseInt(headerCID.TotalDifficulty, 10, 64)
-	Expect(err).ToNot(HaveOccurred())
+			for i, txHash := range txHashes[:3] {
+				txCID, err := backend.Retriever.RetrieveTxCIDByHash(txHash, nil)
+				Expect(err).ToNot(HaveOccurred())
 
-	Expect(ethHeaderCID.CID).To(Equal(headerCID.CID))
-	Expect(ethHeaderCID.BlockNumber).To(Equal(*new(graphql.BigInt).SetUint64(uint64(blockNumber))))
-	Expect(ethHeaderCID.BlockHash).To(Equal(headerCID.BlockHash))
-	Expect(ethHeaderCID.ParentHash).To(Equal(headerCID.ParentHash))
-	Expect(ethHeaderCID.Timestamp).To(Equal(*new(graphql.BigInt).SetUint64(headerCID.Timestamp)))
-	Expect(ethHeaderCID.StateRoot).To(Equal(headerCID.StateRoot))
-	Expect(ethHeaderCID.Td).To(Equal(*new(graphql.BigInt).SetUint64(uint64(td))))
-	Expect(ethHeaderCID.TxRoot).To(Equal(headerCID.TxRoot))
-	Expect(ethHeaderCID.ReceiptRoot).To(Equal(headerCID.RctRoot))
-	Expect(ethHeaderCID.UncleRoot).To(Equal(headerCID.UncleRoot))
-	Expect(ethHeaderCID.Bloom).To(Equal(graphql.Bytes(headerCID.Bloom).String()))
+				compareEthTxCID(ethTransactionCIDsResp[i], txCID)
+				Expect(ethTransactionCIDsResp[i].BlockByMhKey.Data).To(Equal(graphql.Bytes(txCID.IPLD.Data).String()))
+				Expect(ethTransactionCIDsResp[i].Data).To(BeNil())
+			}
+		})
 
-	for tIdx, txCID := range headerCID.TransactionCIDs {
-		ethTxCID := ethHeaderCID.EthTransactionCIDsByHeaderId.Nodes[tIdx]
-		compareEthTxCID(ethTxCID, txCID)
-	}
+		It("Includes the tx input data only when requested", func() {
+			txs := []*types.Transaction{
+				blocks[2].Transactions()[2],
+				blocks[3].Transactions()[0],
+			}
+			txHashes := []string{txs[0].Hash().String(), txs[1].Hash().String()}
+			ethTransactionCIDsResp, err := client.EthTransactionCIDsByTxHashes(ctx, txHashes, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(ethTransactionCIDsResp)).To(Equal(2))
 
-	Expect(ethHeaderCID.BlockByMhKey.Data).To(Equal(graphql.Bytes(headerCID.IPLD.Data).String()))
-	Expect(ethHeaderCID.BlockByMhKey.Key).To(Equal(headerCID.IPLD.Key))
-}
+			for i, tx := range txs {
+				Expect(ethTransactionCIDsResp[i].Data).ToNot(BeNil())
+				Expect([]byte(*ethTransactionCIDsResp[i].Data)).To(Equal(tx.Data()))
+			}
+		})
+	})
 
-func compareEthTxCID(ethTxCID graphql.EthTransactionCIDResponse, txCID eth.TransactionCIDRecord) {
-	Expect(ethTxCID.CID).To(Equal(txCID.CID))
-	Expect(ethTxCID.TxHash).To(Equal(txCID.TxHash))
-	Expect(ethTxCID.Index).To(Equal(int32(txCID.Index)))
-	Expect(ethTxCID.Src).To(Equal(txCID.Src))
-	Expect(ethTxCID.Dst).To(Equal(txCID.Dst))
+	Describe("block transactionHashes", func() {
+		It("Retrieves the hashes of the block's transactions in order", func() {
+			block, err := client.GetBlockTransactionHashes(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(block.TransactionHashes)).To(Equal(len(test_helpers.MockTransactions)))
+			for i, tx := range test_helpers.MockTransactions {
+				Expect(block.TransactionHashes[i]).To(Equal(tx.Hash()))
+			}
+		})
+	})
+
+	Describe("block ommers", func() {
+		It("Retrieves empty transactions and logs for the uncles of a block", func() {
+			block, err := client.GetBlockOmmers(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(block.Ommers)).To(Equal(len(test_helpers.MockUncles)))
+
+			for _, ommer := range block.Ommers {
+				Expect(ommer.Hash).To(BeElementOf(test_helpers.MockUncles[0].Hash(), test_helpers.MockUncles[1].Hash()))
+				Expect(ommer.TransactionCount).ToNot(BeNil())
+				Expect(*ommer.TransactionCount).To(Equal(int32(0)))
+				Expect(ommer.Transactions).ToNot(BeNil())
+				Expect(ommer.Transactions).To(BeEmpty())
+				Expect(ommer.Logs).ToNot(BeNil())
+				Expect(ommer.Logs).To(BeEmpty())
+			}
+		})
+
+		It("Retrieves the transactions of the uncles from the proxy node if configured", func() {
+			server := rpc.NewServer()
+			err := server.RegisterName(eth.APIName, &mockUncleBlockAPI{
+				blocks: map[common.Hash]*types.Block{
+					test_helpers.MockUncles[0].Hash(): types.NewBlockWithHeader(test_helpers.MockUncles[0]).WithBody(test_helpers.MockTransactions, nil),
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Stop()
+
+			backend.Config.Client = rpc.DialInProc(server)
+			backend.Config.ProxyUncleBlocks = true
+			defer func() {
+				backend.Config.Client = nil
+				backend.Config.ProxyUncleBlocks = false
+			}()
+
+			block, err := client.GetBlockOmmers(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(block.Ommers)).To(Equal(len(test_helpers.MockUncles)))
+
+			for _, ommer := range block.Ommers {
+				Expect(ommer.Logs).To(BeEmpty())
+				if ommer.Hash != test_helpers.MockUncles[0].Hash() {
+					// the proxy node does not know the second uncle, which is served from its header alone
+					Expect(*ommer.TransactionCount).To(Equal(int32(0)))
+					Expect(ommer.Transactions).To(BeEmpty())
+					continue
+				}
+				Expect(*ommer.TransactionCount).To(Equal(int32(len(test_helpers.MockTransactions))))
+				Expect(len(ommer.Transactions)).To(Equal(len(test_helpers.MockTransactions)))
+				for i, tx := range ommer.Transactions {
+					Expect(tx.Hash).To(Equal(test_helpers.MockTransactions[i].Hash()))
+				}
+			}
+		})
+	})
+
+	Describe("eventSignatures", func() {
+		It("Retrieves no event signatures for logs that are not canonical", func() {
+			eventSignatures, err := client.GetEventSignatures(ctx, test_helpers.AnotherAddress1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventSignatures).To(BeEmpty())
+		})
+
+		It("Retrieves no event signatures for a contract without logs", func() {
+			eventSignatures, err := client.GetEventSignatures(ctx, contractAddress)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventSignatures).To(BeEmpty())
+		})
+
+		It("Rejects a range which is reversed or exceeds the limit", func() {
+			_, err := client.GetEventSignaturesInRange(ctx, emitterAddr, 3, 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("event signature range from 3 is after to 1"))
+
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			_, err = client.GetEventSignaturesInRange(ctx, emitterAddr, 3, 4)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetEventSignaturesInRange(ctx, emitterAddr, 2, 4)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("event signature range spans 3 blocks, exceeding the limit of 2"))
+
+			// the default range spans all of the blocks up to the latest
+			_, err = client.GetEventSignatures(ctx, emitterAddr)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeding the limit of 2"))
+		})
+	})
+
+	Describe("transfers", func() {
+		It("Retrieves the transfers emitted by the canonical blocks only", func() {
+			// a non-canonical sibling of blocks[3], emitting a transfer of its own
+			txs := blocks[3].Transactions()
+			rcts := make(types.Receipts, len(txs))
+			for i, tx := range txs {
+				rcts[i] = &types.Receipt{
+					Type:              tx.Type(),
+					Status:            types.ReceiptStatusSuccessful,
+					CumulativeGasUsed: uint64(i+1) * params.TxGas,
+					Logs:              []*types.Log{},
+					TxHash:            tx.Hash(),
+				}
+			}
+			rcts[0].Logs = append(rcts[0].Logs, &types.Log{
+				Address: emitterAddr,
+				Topics:  transferTopics,
+				Data:    common.BigToHash(big.NewInt(1000)).Bytes(),
+				TxHash:  txs[0].Hash(),
+			})
+			rcts[0].Bloom = types.CreateBloom(types.Receipts{rcts[0]})
+			header := test_helpers.NewOrphanSibling(blocks[3].Header(), "transfers")
+			orphan := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, chainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(orphan, rcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			transfers, err := client.GetTransfers(ctx, emitterAddr, 1, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(transfers)).To(Equal(2))
+			for i, transfer := range transfers {
+				Expect(transfer.From).To(Equal(test_helpers.Account1Addr))
+				Expect(transfer.To).To(Equal(test_helpers.Account2Addr))
+				Expect(transfer.Value).To(Equal((*hexutil.Big)(big.NewInt(int64(i + 3)))))
+				Expect(transfer.TxHash).To(Equal(blocks[i+3].Transactions()[0].Hash()))
+			}
+		})
+
+		It("Rejects a range with more transfer logs than the limit", func() {
+			backend.Config.LogsMaxResults = 1
+			defer func() { backend.Config.LogsMaxResults = 0 }()
+
+			transfers, err := client.GetTransfers(ctx, emitterAddr, 1, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(transfers)).To(Equal(1))
+
+			_, err = client.GetTransfers(ctx, emitterAddr, 1, 5)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("log filter matches more logs than the limit of 1"))
+		})
+	})
+
+	Describe("logBlockRange", func() {
+		It("Retrieves the range of canonical blocks the contract emitted logs at", func() {
+			blockRange, err := client.GetLogBlockRange(ctx, emitterAddr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockRange).To(Equal(&graphql.BlockRangeResponse{From: 3, To: 4}))
+		})
+
+		It("Retrieves no range for logs that are not canonical", func() {
+			blockRange, err := client.GetLogBlockRange(ctx, test_helpers.AnotherAddress1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockRange).To(BeNil())
+		})
+
+		It("Retrieves no range for a contract without logs", func() {
+			blockRange, err := client.GetLogBlockRange(ctx, contractAddress)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blockRange).To(BeNil())
+		})
+	})
+
+	Describe("transactionsByAddress", func() {
+		It("Retrieves the canonical txs sent from or to the address in block and index order", func() {
+			signer := types.LatestSigner(chainConfig)
+			type txPosition struct {
+				hash   common.Hash
+				number uint64
+				index  int32
+			}
+			// the non-canonical mock block at height 1 is not included
+			expected := make([]txPosition, 0)
+			for _, block := range blocks[1:] {
+				for i, tx := range block.Transactions() {
+					from, err := types.Sender(signer, tx)
+					Expect(err).ToNot(HaveOccurred())
+					if from == test_helpers.TestBankAddress || (tx.To() != nil && *tx.To() == test_helpers.TestBankAddress) {
+						expected = append(expected, txPosition{tx.Hash(), block.NumberU64(), int32(i)})
+					}
+				}
+			}
+			Expect(expected).ToNot(BeEmpty())
+
+			txs, err := client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 0, uint64(len(blocks)-1))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(txs).To(HaveLen(len(expected)))
+			for i, tx := range txs {
+				Expect(tx.Hash).To(Equal(expected[i].hash))
+				Expect(uint64(tx.Block.Number)).To(Equal(expected[i].number))
+				Expect(tx.Index).To(Equal(expected[i].index))
+			}
+		})
+
+		It("Retrieves only the txs within the block range", func() {
+			all, err := client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 0, uint64(len(blocks)-1))
+			Expect(err).ToNot(HaveOccurred())
+			expected := make([]graphql.TransactionByAddressResponse, 0)
+			for _, tx := range all {
+				if tx.Block.Number == 2 {
+					expected = append(expected, tx)
+				}
+			}
+			Expect(expected).ToNot(BeEmpty())
+
+			txs, err := client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 2, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(txs).To(Equal(expected))
+		})
+
+		It("Rejects a range which is reversed or exceeds the limit", func() {
+			_, err := client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 3, 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("transactions by address range from 3 is after to 1"))
+
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			_, err = client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 1, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 1, 3)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("transactions by address range spans 3 blocks, exceeding the limit of 2"))
+		})
+
+		It("Rejects an address with more txs in the range than the limit", func() {
+			last := uint64(len(blocks) - 1)
+			txs, err := client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 0, last)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(txs)).To(BeNumerically(">", 1))
+
+			backend.Config.TxsMaxResults = len(txs) - 1
+			defer func() { backend.Config.TxsMaxResults = 0 }()
+
+			_, err = client.GetTransactionsByAddress(ctx, test_helpers.TestBankAddress, 0, last)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("more than %d transactions", len(txs)-1)))
+		})
+	})
+
+	Describe("blockNumberByHash", func() {
+		It("Retrieves the number of a canonical block", func() {
+			for _, block := range blocks {
+				number, err := client.GetBlockNumberByHash(ctx, block.Hash())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(number).ToNot(BeNil())
+				Expect(uint64(*number)).To(Equal(block.NumberU64()))
+			}
+		})
+
+		It("Fails for a block that is not canonical", func() {
+			_, err := client.GetBlockNumberByHash(ctx, blockHash)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not canonical"))
+		})
+
+		It("Retrieves null for a block that is not indexed", func() {
+			number, err := client.GetBlockNumberByHash(ctx, common.HexToHash("0x01"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(number).To(BeNil())
+		})
+	})
+
+	Describe("contractDeploymentBlock", func() {
+		It("Retrieves the block the contract was deployed at", func() {
+			number, err := client.GetContractDeploymentBlock(ctx, test_helpers.ContractAddr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(number).ToNot(BeNil())
+			Expect(uint64(*number)).To(Equal(blocks[2].NumberU64()))
+		})
+
+		It("Retrieves null for an account without code", func() {
+			number, err := client.GetContractDeploymentBlock(ctx, test_helpers.Account1Addr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(number).To(BeNil())
+
+			number, err = client.GetContractDeploymentBlock(ctx, randomAddr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(number).To(BeNil())
+		})
+	})
+
+	Describe("block tags", func() {
+		It("Resolves the pending block to the latest block without a proxy node", func() {
+			latest := blocks[len(blocks)-1]
+			for _, tag := range []string{"LATEST", "PENDING"} {
+				block, err := client.GetBlockByTag(ctx, tag)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uint64(block.Number)).To(Equal(latest.NumberU64()))
+				Expect(block.Hash).To(Equal(latest.Hash()))
+			}
+		})
+
+		It("Fails to resolve the safe and finalized blocks without a proxy node", func() {
+			for _, tag := range []string{"SAFE", "FINALIZED"} {
+				_, err := client.GetBlockByTag(ctx, tag)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("requires a proxy node"))
+			}
+		})
+
+		It("Resolves the safe and finalized blocks through the proxy node, and the pending block to the latest block", func() {
+			// the proxy node's pending block is the one after its head, which is never indexed
+			head := len(blocks) - 1
+			server := rpc.NewServer()
+			err := server.RegisterName(eth.APIName, &mockBlockTagAPI{
+				numbers: map[rpc.BlockNumber]uint64{
+					rpc.PendingBlockNumber:   uint64(head + 1),
+					rpc.SafeBlockNumber:      3,
+					rpc.FinalizedBlockNumber: 2,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Stop()
+
+			backend.Config.Client = rpc.DialInProc(server)
+			defer func() { backend.Config.Client = nil }()
+
+			for tag, number := range map[string]int{"PENDING": head, "SAFE": 3, "FINALIZED": 2} {
+				block, err := client.GetBlockByTag(ctx, tag)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uint64(block.Number)).To(Equal(blocks[number].NumberU64()))
+				Expect(block.Hash).To(Equal(blocks[number].Hash()))
+			}
+		})
+	})
+
+	Describe("blockHashesAt", func() {
+		It("Retrieves the canonical and non-canonical block hashes at a height", func() {
+			hashes, err := client.GetBlockHashesAt(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(hashes)).To(Equal(2))
+			Expect(hashes[0].Hash).To(Equal(blocks[1].Hash()))
+			Expect(hashes[0].Canonical).To(BeTrue())
+			Expect(hashes[1].Hash).To(Equal(blockHash))
+			Expect(hashes[1].Canonical).To(BeFalse())
+		})
+
+		It("Retrieves no block hashes at a height that is not indexed", func() {
+			hashes, err := client.GetBlockHashesAt(ctx, 1000)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hashes).To(BeEmpty())
+		})
+	})
+
+	Describe("removedAccounts", func() {
+		It("Retrieves the leaf keys of the accounts removed in the block", func() {
+			removedAccounts, err := client.GetRemovedAccounts(ctx, blocks[len(blocks)-1].Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removedAccounts).To(Equal([]common.Hash{removedLeafKey}))
+		})
+
+		It("Retrieves no removed accounts for a block without self-destructs", func() {
+			removedAccounts, err := client.GetRemovedAccounts(ctx, blocks[3].Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removedAccounts).To(BeEmpty())
+		})
+
+		It("Retrieves no removed accounts for a block that is not canonical", func() {
+			removedAccounts, err := client.GetRemovedAccounts(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removedAccounts).To(BeEmpty())
+		})
+	})
+
+	Describe("gasPriceStats", func() {
+		It("Retrieves the gas price stats of the canonical txs in the range", func() {
+			// the txs of the test chain are unpriced, while the non-canonical mock block at height 1 has priced txs
+			stats, err := client.GetGasPriceStats(ctx, 1, 2, 50)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Count).To(Equal(hexutil.Uint64(len(blocks[1].Transactions()) + len(blocks[2].Transactions()))))
+			Expect(stats.Average).ToNot(BeNil())
+			Expect(stats.Average.ToInt().Sign()).To(Equal(0))
+			Expect(stats.Percentile).ToNot(BeNil())
+			Expect(stats.Percentile.ToInt().Sign()).To(Equal(0))
+		})
+
+		It("Retrieves no gas price stats for a range without txs", func() {
+			stats, err := client.GetGasPriceStats(ctx, 6, 10, 50)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Count).To(Equal(hexutil.Uint64(0)))
+			Expect(stats.Average).To(BeNil())
+			Expect(stats.Percentile).To(BeNil())
+		})
+
+		It("Rejects a range which is reversed or exceeds the limit", func() {
+			_, err := client.GetGasPriceStats(ctx, 3, 1, 50)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("gas price range from 3 is after to 1"))
+
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			_, err = client.GetGasPriceStats(ctx, 1, 2, 50)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetGasPriceStats(ctx, 1, 3, 50)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("gas price range spans 3 blocks, exceeding the limit of 2"))
+		})
+	})
+
+	Describe("gasUsedRatios", func() {
+		It("Retrieves the gas used ratios of the canonical blocks in the range", func() {
+			ratios, err := client.GetGasUsedRatios(ctx, 1, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(ratios)).To(Equal(3))
+			for i, ratio := range ratios {
+				block := blocks[i+1]
+				Expect(uint64(ratio.Number)).To(Equal(block.NumberU64()))
+				Expect(ratio.Ratio).To(Equal(float64(block.GasUsed()) / float64(block.GasLimit())))
+			}
+		})
+
+		It("Retrieves no gas used ratios for a range that is not indexed", func() {
+			ratios, err := client.GetGasUsedRatios(ctx, 1000, 1010)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ratios).To(BeEmpty())
+		})
+
+		It("Rejects a range which is reversed or exceeds the limit", func() {
+			_, err := client.GetGasUsedRatios(ctx, 3, 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("gas used ratio range from 3 is after to 1"))
+
+			backend.Config.LogsMaxBlockRange = 2
+			defer func() { backend.Config.LogsMaxBlockRange = 0 }()
+
+			_, err = client.GetGasUsedRatios(ctx, 1, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetGasUsedRatios(ctx, 1, 3)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("gas used ratio range spans 3 blocks, exceeding the limit of 2"))
+		})
+	})
+
+	Describe("activeAddressCount", func() {
+		It("Counts the distinct senders and recipients of the canonical txs in the block", func() {
+			// in block 2 the test bank pays account 1, which pays account 2 and creates a contract
+			count, err := client.GetActiveAddressCount(ctx, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(uint64(3)))
+		})
+
+		It("Ignores the txs of non-canonical blocks at the same height", func() {
+			// the non-canonical mock block at height 1 has txs between other addresses
+			count, err := client.GetActiveAddressCount(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(uint64(2)))
+		})
+
+		It("Counts no addresses for a block without txs", func() {
+			count, err := client.GetActiveAddressCount(ctx, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(uint64(0)))
+		})
+	})
+
+	Describe("block contractsCreatedCount", func() {
+		It("Counts the contracts created in a canonical block", func() {
+			// in block 2 account 1 creates the test contract
+			block, err := client.GetBlockContractsCreatedCount(ctx, blockHashes[2])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(block.ContractsCreatedCount).ToNot(BeNil())
+			Expect(uint64(*block.ContractsCreatedCount)).To(Equal(uint64(1)))
+
+			block, err = client.GetBlockContractsCreatedCount(ctx, blockHashes[3])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(block.ContractsCreatedCount).ToNot(BeNil())
+			Expect(uint64(*block.ContractsCreatedCount)).To(Equal(uint64(0)))
+		})
+
+		It("Returns null for a block which is not canonical", func() {
+			block, err := client.GetBlockContractsCreatedCount(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(block.ContractsCreatedCount).To(BeNil())
+		})
+	})
+
+	Describe("transaction raw encodings", func() {
+		It("Retrieves the raw transaction and receipt as stored in their IPLD blocks", func() {
+			for _, tx := range blocks[2].Transactions() {
+				res, err := client.GetTransactionRaw(ctx, tx.Hash())
+				Expect(err).ToNot(HaveOccurred())
+
+				_, txIPLD, err := backend.IPLDRetriever.RetrieveTransactionByTxHash(tx.Hash())
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]byte(res.Raw)).To(Equal(txIPLD))
+
+				_, rctIPLD, err := backend.IPLDRetriever.RetrieveReceiptByHash(tx.Hash())
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]byte(res.RawReceipt)).To(Equal(rctIPLD))
+			}
+		})
+	})
+
+	Describe("transaction methodSelector", func() {
+		It("Retrieves the selector of the method called by a contract call", func() {
+			tx := blocks[3].Transactions()[0]
+			res, err := client.GetTransactionMethodSelector(ctx, tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.MethodSelector).ToNot(BeNil())
+			Expect(*res.MethodSelector).To(Equal("0x65f3c31a"))
+		})
+
+		It("Retrieves null for a plain value transfer", func() {
+			tx := blocks[1].Transactions()[0]
+			Expect(tx.Data()).To(BeEmpty())
+			res, err := client.GetTransactionMethodSelector(ctx, tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.MethodSelector).To(BeNil())
+		})
+	})
+
+	Describe("block size", func() {
+		It("Retrieves the size of the full block, including its transactions", func() {
+			block, err := client.GetBlockSize(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uint64(block.Size)).To(Equal(uint64(test_helpers.MockBlock.Size())))
+			Expect(uint64(block.Size)).To(BeNumerically(">", uint64(types.NewBlockWithHeader(test_helpers.MockBlock.Header()).Size())))
+
+			block, err = client.GetBlockSize(ctx, blockHashes[2])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uint64(block.Size)).To(Equal(uint64(blocks[2].Size())))
+		})
+	})
+
+	Describe("block cliqueSigners", func() {
+		It("Retrieves no Clique fields for a chain which does not use Clique", func() {
+			block, err := client.GetBlockCliqueSigners(ctx, blockHashes[2])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(block.CliqueSigners).To(BeNil())
+		})
+
+		It("Retrieves the signers of the Clique genesis block, which has no sealer", func() {
+			cliqueConfig := *chainConfig
+			cliqueConfig.Clique = &params.CliqueConfig{Period: 15, Epoch: 30000}
+			backend.Config.ChainConfig = &cliqueConfig
+			defer func() { backend.Config.ChainConfig = chainConfig }()
+
+			// a non-canonical sibling of the genesis block, with Clique formatted extra-data and a zero seal
+			header := test_helpers.NewOrphanSibling(blocks[0].Header(), "")
+			header.Extra = append(make([]byte, 32), test_helpers.Account1Addr.Bytes()...)
+			header.Extra = append(header.Extra, make([]byte, crypto.SignatureLength)...)
+			genesis := types.NewBlockWithHeader(header)
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, chainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(genesis, types.Receipts{}, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			block, err := client.GetBlockCliqueSigners(ctx, genesis.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(block.CliqueSigners).ToNot(BeNil())
+			Expect(block.CliqueSigners.Signers).To(Equal([]common.Address{test_helpers.Account1Addr}))
+			Expect(block.CliqueSigners.Seal).To(Equal(hexutil.Bytes(make([]byte, crypto.SignatureLength))))
+			Expect(block.CliqueSigners.Sealer).To(BeNil())
+		})
+	})
+
+	Describe("block logCount", func() {
+		It("Counts the logs emitted in a canonical block", func() {
+			for i := 1; i < len(blocks); i++ {
+				var expected uint64
+				for _, rct := range receipts[i-1] {
+					expected += uint64(len(rct.Logs))
+				}
+				block, err := client.GetBlockLogCount(ctx, blockHashes[i])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(block.LogCount).ToNot(BeNil())
+				Expect(uint64(*block.LogCount)).To(Equal(expected))
+			}
+		})
+
+		It("Returns null for a block which is not canonical", func() {
+			block, err := client.GetBlockLogCount(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(block.LogCount).To(BeNil())
+		})
+	})
+
+	Describe("blocks with logs", func() {
+		It("Retrieves the logs of each block in a range within the limit", func() {
+			resp, err := client.GetBlocksLogs(ctx, 0, blocksLogsLimit-1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp)).To(Equal(blocksLogsLimit))
+			for i, block := range resp {
+				Expect(block.Number).To(Equal(hexutil.Uint64(i)))
+				Expect(block.Logs).ToNot(BeNil())
+			}
+		})
+
+		It("Rejects selecting the logs of each block in a range over the limit", func() {
+			_, err := client.GetBlocksLogs(ctx, 0, blocksLogsLimit)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeds the limit of 4"))
+		})
+	})
+
+	Describe("blocks without an end", func() {
+		AfterEach(func() {
+			backend.Config.BlocksMaxOpenRange = 0
+			backend.Config.BlocksTruncateOpenRange = false
+		})
+
+		It("Ranges to the head when there is no limit", func() {
+			resp, err := client.GetBlocksFrom(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp)).To(BeNumerically(">", 2))
+			Expect(resp[len(resp)-1].Number).To(Equal(hexutil.Uint64(blocks[len(blocks)-1].NumberU64())))
+		})
+
+		It("Rejects a range to a head beyond the limit", func() {
+			backend.Config.BlocksMaxOpenRange = 2
+			_, err := client.GetBlocksFrom(ctx, 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeding the limit of 2"))
+		})
+
+		It("Truncates a range to a head beyond the limit when configured to", func() {
+			backend.Config.BlocksMaxOpenRange = 2
+			backend.Config.BlocksTruncateOpenRange = true
+			resp, err := client.GetBlocksFrom(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp).To(Equal([]graphql.BlockNumberResponse{{Number: 1}, {Number: 2}}))
+		})
+	})
+
+	Describe("unknown input fields", func() {
+		query := `query Logs {
+			logs(filter: {fromBlock: 1, toBlock: 5, notAField: {nested: ["a\"}", 1]}, addresses: []}) { index }
+			...blockLogs
+		}
+		fragment blockLogs on Query {
+			block(number: 1) { ... on Block { logs(filter: {addresses: [], alsoNotAField: "ünïcode"}) { index } } }
+		}`
+		serve := func(lenient bool) string {
+			handler, err := graphql.NewHandler(backend, blocksLogsLimit, 0, lenient)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := json.Marshal(map[string]string{"query": query})
+			Expect(err).ToNot(HaveOccurred())
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			return rec.Body.String()
+		}
+
+		It("Rejects a query with unknown input fields by default", func() {
+			res := serve(false)
+			Expect(res).To(ContainSubstring(`"errors"`))
+			Expect(res).To(ContainSubstring(`In field \"notAField\": Unknown field.`))
+		})
+
+		It("Ignores unknown input fields in lenient mode", func() {
+			res := serve(true)
+			Expect(res).ToNot(ContainSubstring(`"errors"`))
+			var resp struct {
+				Data struct {
+					Logs  []interface{}
+					Block struct {
+						Logs []interface{}
+					}
+				}
+			}
+			Expect(json.Unmarshal([]byte(res), &resp)).To(Succeed())
+			Expect(resp.Data.Logs).ToNot(BeNil())
+			Expect(resp.Data.Block.Logs).ToNot(BeNil())
+		})
+	})
+
+	Describe("logs topics limits", func() {
+		topics := [][]common.Hash{
+			{common.HexToHash("0x01"), common.HexToHash("0x02")},
+			{common.HexToHash("0x03")},
+		}
+
+		AfterEach(func() {
+			backend.Config.LogsMaxTopicsPerPosition = 0
+			backend.Config.LogsMaxTopics = 0
+		})
+
+		It("Retrieves logs for a filter within the limits", func() {
+			backend.Config.LogsMaxTopicsPerPosition = 2
+			backend.Config.LogsMaxTopics = 3
+			_, err := client.GetFilteredLogs(ctx, 1, 5, topics)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Rejects a filter with too many topics at a position", func() {
+			backend.Config.LogsMaxTopicsPerPosition = 1
+			_, err := client.GetFilteredLogs(ctx, 1, 5, topics)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeding the limit of 1 per position"))
+		})
+
+		It("Rejects a filter with too many topics in total", func() {
+			backend.Config.LogsMaxTopics = 2
+			_, err := client.GetFilteredLogs(ctx, 1, 5, topics)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exceeding the limit of 2"))
+		})
+	})
+
+	Describe("gasUsedByTransaction", func() {
+		It("Retrieves the per transaction gas used, summing to the gas used by the block", func() {
+			block := blocks[2]
+			resp, err := client.GetBlockGasUsed(ctx, block.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(block.Transactions())))
+			Expect(len(resp.Transactions)).To(BeNumerically(">", 1))
+
+			var total uint64
+			for i, tx := range resp.Transactions {
+				Expect(uint64(tx.GasUsedByTransaction)).To(Equal(receipts[1][i].GasUsed))
+				total += uint64(tx.GasUsedByTransaction)
+			}
+			Expect(total).To(Equal(block.GasUsed()))
+			Expect(uint64(resp.GasUsed)).To(Equal(block.GasUsed()))
+		})
+	})
+
+	Describe("baseFeePerGas", func() {
+		It("Retrieves no base fee for a block mined before London", func() {
+			resp, err := client.GetBlockBaseFee(ctx, blocks[2].Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.BaseFeePerGas).To(BeNil())
+		})
+
+		It("Retrieves the base fee of a block mined after London", func() {
+			londonConfig := *chainConfig
+			londonConfig.LondonBlock = test_helpers.LondonBlockNum
+			londonBlock := test_helpers.MockLondonBlock
+
+			// indexed with a low total difficulty so that it is a non-canonical sibling and the rest of the chain is unaffected
+			indexer := shared.SetupTestStateDiffIndexer(ctx, &londonConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(londonBlock, test_helpers.MockLondonReceipts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockBaseFee(ctx, londonBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.BaseFeePerGas).ToNot(BeNil())
+			Expect(resp.BaseFeePerGas.ToInt().Cmp(londonBlock.BaseFee())).To(Equal(0))
+		})
+	})
+
+	Describe("prevRandao", func() {
+		It("Retrieves no prevRandao for a block mined before the Merge", func() {
+			resp, err := client.GetBlockMixHash(ctx, blocks[2].Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.MixHash).To(Equal(blocks[2].MixDigest()))
+			Expect(resp.PrevRandao).To(BeNil())
+		})
+
+		It("Retrieves the same value for mixHash and prevRandao of a block produced after the Merge", func() {
+			// a non-canonical sibling of blocks[3], so the rest of the chain is unaffected
+			header := test_helpers.NewOrphanSibling(blocks[3].Header(), "prevRandao")
+			header.Difficulty = big.NewInt(0)
+			header.MixDigest = crypto.Keccak256Hash([]byte("prevRandao"))
+			mergeBlock := types.NewBlock(header, nil, nil, nil, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, chainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(mergeBlock, types.Receipts{}, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockMixHash(ctx, mergeBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.MixHash).To(Equal(header.MixDigest))
+			Expect(resp.PrevRandao).ToNot(BeNil())
+			Expect(*resp.PrevRandao).To(Equal(resp.MixHash))
+		})
+	})
+
+	Describe("receiptBloom", func() {
+		It("Retrieves the receipt bloom of each tx, matching the receipt's logs", func() {
+			resp, err := client.GetBlockReceiptBlooms(ctx, blockHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(test_helpers.MockReceipts)))
+
+			for i, tx := range resp.Transactions {
+				Expect(tx.Hash).To(Equal(test_helpers.MockTransactions[i].Hash()))
+				bloom := types.BytesToBloom(tx.ReceiptBloom)
+				Expect(bloom).To(Equal(test_helpers.MockReceipts[i].Bloom))
+				Expect(len(tx.Logs)).To(Equal(len(test_helpers.MockReceipts[i].Logs)))
+				for _, log := range tx.Logs {
+					for _, topic := range log.Topics {
+						Expect(bloom.Test(topic.Bytes())).To(BeTrue())
+					}
+				}
+			}
+		})
+	})
+
+	Describe("effectiveTip", func() {
+		It("Retrieves no effective tip for txs mined before London", func() {
+			resp, err := client.GetBlockEffectiveTips(ctx, blocks[2].Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(blocks[2].Transactions())))
+			for _, tx := range resp.Transactions {
+				Expect(tx.EffectiveTip).To(BeNil())
+			}
+		})
+
+		It("Retrieves the effective tip of legacy and dynamic fee txs mined after London", func() {
+			londonConfig := *chainConfig
+			londonConfig.LondonBlock = big.NewInt(0)
+			signer := types.LatestSigner(&londonConfig)
+			baseFee := big.NewInt(1000)
+			to := test_helpers.Account1Addr
+
+			legacyTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    0,
+				GasPrice: big.NewInt(1200),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(1),
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+			// capped by the max fee
+			cappedTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   londonConfig.ChainID,
+				Nonce:     1,
+				GasTipCap: big.NewInt(50),
+				GasFeeCap: big.NewInt(1030),
+				Gas:       params.TxGas,
+				To:        &to,
+				Value:     big.NewInt(1),
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+			// capped by the max priority fee
+			tippedTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   londonConfig.ChainID,
+				Nonce:     2,
+				GasTipCap: big.NewInt(50),
+				GasFeeCap: big.NewInt(2000),
+				Gas:       params.TxGas,
+				To:        &to,
+				Value:     big.NewInt(1),
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+
+			txs := types.Transactions{legacyTx, cappedTx, tippedTx}
+			rcts := make(types.Receipts, len(txs))
+			for i, tx := range txs {
+				rcts[i] = &types.Receipt{
+					Type:              tx.Type(),
+					Status:            types.ReceiptStatusSuccessful,
+					CumulativeGasUsed: uint64(i+1) * params.TxGas,
+					Logs:              []*types.Log{},
+					TxHash:            tx.Hash(),
+				}
+			}
+			// a non-canonical sibling of blocks[4], so the rest of the chain is unaffected
+			header := test_helpers.NewOrphanSibling(blocks[4].Header(), "london")
+			header.BaseFee = baseFee
+			londonBlock := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, &londonConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(londonBlock, rcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockEffectiveTips(ctx, londonBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(txs)))
+
+			expectedTips := []*big.Int{big.NewInt(200), big.NewInt(30), big.NewInt(50)}
+			for i, tx := range resp.Transactions {
+				Expect(tx.Hash).To(Equal(txs[i].Hash()))
+				Expect(tx.EffectiveTip).ToNot(BeNil())
+				Expect(tx.EffectiveTip.ToInt().Cmp(expectedTips[i])).To(Equal(0))
+			}
+		})
+	})
+
+	Describe("transaction from", func() {
+		It("Recovers the sender of legacy and typed txs", func() {
+			londonConfig := *chainConfig
+			londonConfig.LondonBlock = big.NewInt(0)
+			signer := types.LatestSigner(&londonConfig)
+			to := test_helpers.Account2Addr
+
+			legacyTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    0,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(1),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			accessListTx, err := types.SignTx(types.NewTx(&types.AccessListTx{
+				ChainID:  londonConfig.ChainID,
+				Nonce:    1,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(1),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			dynamicFeeTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   londonConfig.ChainID,
+				Nonce:     2,
+				GasTipCap: big.NewInt(50),
+				GasFeeCap: big.NewInt(2000),
+				Gas:       params.TxGas,
+				To:        &to,
+				Value:     big.NewInt(1),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+
+			txs := types.Transactions{legacyTx, accessListTx, dynamicFeeTx}
+			rcts := make(types.Receipts, len(txs))
+			for i, tx := range txs {
+				rcts[i] = &types.Receipt{
+					Type:              tx.Type(),
+					Status:            types.ReceiptStatusSuccessful,
+					CumulativeGasUsed: uint64(i+1) * params.TxGas,
+					Logs:              []*types.Log{},
+					TxHash:            tx.Hash(),
+				}
+			}
+			// a non-canonical sibling of blocks[3], so the rest of the chain is unaffected
+			header := test_helpers.NewOrphanSibling(blocks[3].Header(), "senders")
+			header.BaseFee = big.NewInt(1000)
+			londonBlock := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, &londonConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(londonBlock, rcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockTransactionSenders(ctx, londonBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(txs)))
+			for i, tx := range resp.Transactions {
+				Expect(tx.Hash).To(Equal(txs[i].Hash()))
+				Expect(tx.From.Address).To(Equal(test_helpers.Account1Addr))
+			}
+		})
+	})
+
+	Describe("signature", func() {
+		It("Retrieves the signature values of legacy and typed txs, with the y parity of typed txs", func() {
+			londonConfig := *chainConfig
+			londonConfig.LondonBlock = big.NewInt(0)
+			signer := types.LatestSigner(&londonConfig)
+			to := test_helpers.Account2Addr
+
+			legacyTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    0,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(2),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			accessListTx, err := types.SignTx(types.NewTx(&types.AccessListTx{
+				ChainID:  londonConfig.ChainID,
+				Nonce:    1,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(2),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			dynamicFeeTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   londonConfig.ChainID,
+				Nonce:     2,
+				GasTipCap: big.NewInt(50),
+				GasFeeCap: big.NewInt(2000),
+				Gas:       params.TxGas,
+				To:        &to,
+				Value:     big.NewInt(2),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+
+			txs := types.Transactions{legacyTx, accessListTx, dynamicFeeTx}
+			rcts := make(types.Receipts, len(txs))
+			for i, tx := range txs {
+				rcts[i] = &types.Receipt{
+					Type:              tx.Type(),
+					Status:            types.ReceiptStatusSuccessful,
+					CumulativeGasUsed: uint64(i+1) * params.TxGas,
+					Logs:              []*types.Log{},
+					TxHash:            tx.Hash(),
+				}
+			}
+			// a non-canonical sibling of blocks[3], so the rest of the chain is unaffected
+			header := test_helpers.NewOrphanSibling(blocks[3].Header(), "signatures")
+			header.BaseFee = big.NewInt(1000)
+			londonBlock := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, &londonConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(londonBlock, rcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockTransactionSignatures(ctx, londonBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(txs)))
+			for i, tx := range resp.Transactions {
+				Expect(tx.Hash).To(Equal(txs[i].Hash()))
+				v, r, s := txs[i].RawSignatureValues()
+				Expect(tx.Signature.R.ToInt()).To(Equal(r))
+				Expect(tx.Signature.S.ToInt()).To(Equal(s))
+				Expect(tx.Signature.V.ToInt()).To(Equal(v))
+				if txs[i].Type() == types.LegacyTxType {
+					Expect(tx.Signature.YParity).To(BeNil())
+				} else {
+					Expect(tx.Signature.YParity).ToNot(BeNil())
+					Expect(uint64(*tx.Signature.YParity)).To(Equal(v.Uint64()))
+				}
+			}
+		})
+	})
+
+	Describe("yParity and chainID", func() {
+		It("Retrieves the y parity of typed txs and the chain id of protected txs", func() {
+			londonConfig := *chainConfig
+			londonConfig.LondonBlock = big.NewInt(0)
+			signer := types.LatestSigner(&londonConfig)
+			to := test_helpers.Account2Addr
+
+			unprotectedTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    0,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(2),
+			}), types.HomesteadSigner{}, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			legacyTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    1,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(2),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			accessListTx, err := types.SignTx(types.NewTx(&types.AccessListTx{
+				ChainID:  londonConfig.ChainID,
+				Nonce:    2,
+				GasPrice: big.NewInt(2000),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(2),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+			dynamicFeeTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   londonConfig.ChainID,
+				Nonce:     3,
+				GasTipCap: big.NewInt(50),
+				GasFeeCap: big.NewInt(2000),
+				Gas:       params.TxGas,
+				To:        &to,
+				Value:     big.NewInt(2),
+			}), signer, test_helpers.Account1Key)
+			Expect(err).ToNot(HaveOccurred())
+
+			txs := types.Transactions{unprotectedTx, legacyTx, accessListTx, dynamicFeeTx}
+			rcts := make(types.Receipts, len(txs))
+			for i, tx := range txs {
+				rcts[i] = &types.Receipt{
+					Type:              tx.Type(),
+					Status:            types.ReceiptStatusSuccessful,
+					CumulativeGasUsed: uint64(i+1) * params.TxGas,
+					Logs:              []*types.Log{},
+					TxHash:            tx.Hash(),
+				}
+			}
+			// a non-canonical sibling of blocks[3], so the rest of the chain is unaffected
+			header := test_helpers.NewOrphanSibling(blocks[3].Header(), "chain ids")
+			header.BaseFee = big.NewInt(1000)
+			londonBlock := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, &londonConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(londonBlock, rcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockTransactionChainIDs(ctx, londonBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(txs)))
+			for i, tx := range resp.Transactions {
+				Expect(tx.Hash).To(Equal(txs[i].Hash()))
+			}
+
+			Expect(resp.Transactions[0].YParity).To(BeNil())
+			Expect(resp.Transactions[0].ChainID).To(BeNil())
+			Expect(resp.Transactions[1].YParity).To(BeNil())
+			Expect(resp.Transactions[1].ChainID).ToNot(BeNil())
+			Expect(resp.Transactions[1].ChainID.ToInt()).To(Equal(londonConfig.ChainID))
+			for _, i := range []int{2, 3} {
+				v, _, _ := txs[i].RawSignatureValues()
+				Expect(resp.Transactions[i].YParity).ToNot(BeNil())
+				Expect(uint64(*resp.Transactions[i].YParity)).To(Equal(v.Uint64()))
+				Expect(resp.Transactions[i].ChainID).ToNot(BeNil())
+				Expect(resp.Transactions[i].ChainID.ToInt()).To(Equal(londonConfig.ChainID))
+			}
+		})
+	})
+
+	Describe("accessList", func() {
+		It("Retrieves the access list of typed txs and none for legacy txs", func() {
+			berlinConfig := *chainConfig
+			berlinConfig.BerlinBlock = big.NewInt(0)
+			signer := types.LatestSigner(&berlinConfig)
+			to := test_helpers.Account2Addr
+			accessList := types.AccessList{
+				{
+					Address:     test_helpers.ContractAddr,
+					StorageKeys: []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+				},
+				{
+					Address:     test_helpers.Account1Addr,
+					StorageKeys: []common.Hash{},
+				},
+			}
+
+			legacyTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    0,
+				GasPrice: big.NewInt(1),
+				Gas:      params.TxGas,
+				To:       &to,
+				Value:    big.NewInt(1),
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+			accessListTx, err := types.SignTx(types.NewTx(&types.AccessListTx{
+				ChainID:    berlinConfig.ChainID,
+				Nonce:      1,
+				GasPrice:   big.NewInt(1),
+				Gas:        params.TxGas,
+				To:         &to,
+				Value:      big.NewInt(1),
+				AccessList: accessList,
+			}), signer, test_helpers.TestBankKey)
+			Expect(err).ToNot(HaveOccurred())
+
+			txs := types.Transactions{legacyTx, accessListTx}
+			rcts := make(types.Receipts, len(txs))
+			for i, tx := range txs {
+				rcts[i] = &types.Receipt{
+					Type:              tx.Type(),
+					Status:            types.ReceiptStatusSuccessful,
+					CumulativeGasUsed: uint64(i+1) * params.TxGas,
+					Logs:              []*types.Log{},
+					TxHash:            tx.Hash(),
+				}
+			}
+			// a non-canonical sibling of blocks[1], so the rest of the chain is unaffected
+			header := test_helpers.NewOrphanSibling(blocks[1].Header(), "access list")
+			berlinBlock := types.NewBlock(header, txs, nil, rcts, new(trie.Trie))
+
+			indexer := shared.SetupTestStateDiffIndexer(ctx, &berlinConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(berlinBlock, rcts, big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.GetBlockAccessLists(ctx, berlinBlock.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Transactions)).To(Equal(len(txs)))
+
+			Expect(resp.Transactions[0].Hash).To(Equal(legacyTx.Hash()))
+			Expect(resp.Transactions[0].AccessList).To(BeNil())
+
+			Expect(resp.Transactions[1].Hash).To(Equal(accessListTx.Hash()))
+			Expect(len(resp.Transactions[1].AccessList)).To(Equal(len(accessList)))
+			for i, tuple := range resp.Transactions[1].AccessList {
+				Expect(tuple.Address).To(Equal(accessList[i].Address))
+				Expect(tuple.StorageKeys).To(Equal(accessList[i].StorageKeys))
+			}
+		})
+	})
+})
+
+func compareEthHeaderCID(ethHeaderCID graphql.EthHeaderCIDResponse, headerCID eth.HeaderCIDRecord) {
+	blockNumber, err := strconv.ParseInt(headerCID.BlockNumber, 10, 64)
+	Expect(err).ToNot(HaveOccurred())
+
+	td, err := strconv.ParseInt(headerCID.TotalDifficulty, 10, 64)
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(ethHeaderCID.CID).To(Equal(headerCID.CID))
+	Expect(ethHeaderCID.BlockNumber).To(Equal(*new(graphql.BigInt).SetUint64(uint64(blockNumber))))
+	Expect(ethHeaderCID.BlockHash).To(Equal(headerCID.BlockHash))
+	Expect(ethHeaderCID.ParentHash).To(Equal(headerCID.ParentHash))
+	Expect(ethHeaderCID.Timestamp).To(Equal(*new(graphql.BigInt).SetUint64(headerCID.Timestamp)))
+	Expect(ethHeaderCID.StateRoot).To(Equal(headerCID.StateRoot))
+	Expect(ethHeaderCID.Td).To(Equal(*new(graphql.BigInt).SetUint64(uint64(td))))
+	Expect(ethHeaderCID.TxRoot).To(Equal(headerCID.TxRoot))
+	Expect(ethHeaderCID.ReceiptRoot).To(Equal(headerCID.RctRoot))
+	Expect(ethHeaderCID.UncleRoot).To(Equal(headerCID.UncleRoot))
+	Expect(ethHeaderCID.Bloom).To(Equal(graphql.Bytes(headerCID.Bloom).String()))
+	Expect(ethHeaderCID.Coinbase).To(Equal(headerCID.Coinbase))
+	Expect(ethHeaderCID.NodeID).To(Equal(headerCID.NodeID))
+
+	for tIdx, txCID := range headerCID.TransactionCIDs {
+		ethTxCID := ethHeaderCID.EthTransactionCIDsByHeaderId.Nodes[tIdx]
+		compareEthTxCID(ethTxCID, txCID)
+	}
+
+	Expect(ethHeaderCID.BlockByMhKey.Data).To(Equal(graphql.Bytes(headerCID.IPLD.Data).String()))
+	Expect(ethHeaderCID.BlockByMhKey.Key).To(Equal(headerCID.IPLD.Key))
+}
+
+func compareEthTxCID(ethTxCID graphql.EthTransactionCIDResponse, txCID eth.TransactionCIDRecord) {
+	Expect(ethTxCID.CID).To(Equal(txCID.CID))
+	Expect(ethTxCID.TxHash).To(Equal(txCID.TxHash))
+	Expect(ethTxCID.Index).To(Equal(int32(txCID.Index)))
+	Expect(ethTxCID.Src).To(Equal(txCID.Src))
+	Expect(ethTxCID.Dst).To(Equal(txCID.Dst))
+}
+
+// mockBlockTagAPI serves the numbers of the blocks referenced by the block tags, as a proxy node would
+type mockBlockTagAPI struct {
+	numbers map[rpc.BlockNumber]uint64
+}
+
+func (api *mockBlockTagAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	n, ok := api.numbers[number]
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{"number": hexutil.Uint64(n)}, nil
+}
+
+// mockUncleBlockAPI serves the full blocks of uncles by their hash, as a proxy node would
+type mockUncleBlockAPI struct {
+	blocks map[common.Hash]*types.Block
+}
+
+func (api *mockUncleBlockAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	block, ok := api.blocks[hash]
+	if !ok {
+		return nil, nil
+	}
+	return eth.RPCMarshalBlock(block, true, fullTx)
 }
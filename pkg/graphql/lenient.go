@@ -0,0 +1,194 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/types"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// lenientHandler answers GraphQL queries like relay.Handler, but first drops the fields of the query's input object
+// literals that the schema does not define, so that newer clients can be served by an older schema.
+// The unknown fields of input objects passed as variables are ignored by the executor in either mode.
+type lenientHandler struct {
+	schema *graphql.Schema
+}
+
+func (h *lenientHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := stripUnknownInputFields(h.schema.ASTSchema(), params.Query)
+	response := h.schema.Exec(r.Context(), query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseJSON)
+}
+
+// stripUnknownInputFields removes the fields of the input object literals of a query that are not defined by the
+// input object type they are passed as, checking the arguments of each selected field against the schema.
+// A query which can't be parsed is returned as is, for the executor to report its errors.
+func stripUnknownInputFields(schema *types.Schema, query string) string {
+	// the parser's locations mix byte and rune offsets past non-ASCII characters, which can only be part of strings
+	// and comments, so a copy with an ASCII placeholder for each of them is parsed to locate the fields by rune offset
+	runes := []rune(query)
+	ascii := make([]byte, len(runes))
+	for i, r := range runes {
+		switch {
+		case r < utf8.RuneSelf:
+			ascii[i] = byte(r)
+		case r == '\uFEFF':
+			// the byte order mark is ignored like whitespace
+			ascii[i] = ' '
+		default:
+			ascii[i] = '_'
+		}
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: string(ascii)})
+	if err != nil {
+		return query
+	}
+	s := &inputStripper{schema: schema}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			s.stripSelections(def.SelectionSet, schema.EntryPoints[def.Operation])
+		case *ast.FragmentDefinition:
+			s.stripSelections(def.SelectionSet, schema.Types[def.TypeCondition.Name.Value])
+		}
+	}
+	if len(s.unknown) == 0 {
+		return query
+	}
+
+	// the fields are cut from the back so that the offsets of the ones before them stay valid
+	sort.Slice(s.unknown, func(i, j int) bool { return s.unknown[i].Start > s.unknown[j].Start })
+	for _, loc := range s.unknown {
+		runes = append(runes[:loc.Start], runes[loc.End:]...)
+	}
+	return string(runes)
+}
+
+// inputStripper collects the locations of the unknown input object fields of a query
+type inputStripper struct {
+	schema  *types.Schema
+	unknown []*ast.Location
+}
+
+// stripSelections checks the arguments of the fields selected on a type, and of the fields selected beneath them
+func (s *inputStripper) stripSelections(set *ast.SelectionSet, parent types.NamedType) {
+	if set == nil || parent == nil {
+		return
+	}
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			field := fieldDefinition(parent, selection.Name.Value)
+			if field == nil {
+				continue
+			}
+			for _, arg := range selection.Arguments {
+				if def := field.Arguments.Get(arg.Name.Value); def != nil {
+					s.stripValue(arg.Value, def.Type)
+				}
+			}
+			s.stripSelections(selection.SelectionSet, namedType(field.Type))
+		case *ast.InlineFragment:
+			typ := parent
+			if selection.TypeCondition != nil {
+				typ = s.schema.Types[selection.TypeCondition.Name.Value]
+			}
+			s.stripSelections(selection.SelectionSet, typ)
+		}
+	}
+}
+
+// stripValue collects the unknown fields of the input object literals of a value of the provided type
+func (s *inputStripper) stripValue(value ast.Value, t types.Type) {
+	switch t := t.(type) {
+	case *types.NonNull:
+		s.stripValue(value, t.OfType)
+	case *types.List:
+		list, ok := value.(*ast.ListValue)
+		if !ok {
+			// input coercion allows a single item in place of a list
+			s.stripValue(value, t.OfType)
+			return
+		}
+		for _, elem := range list.Values {
+			s.stripValue(elem, t.OfType)
+		}
+	case *types.InputObject:
+		obj, ok := value.(*ast.ObjectValue)
+		if !ok {
+			return
+		}
+		for _, field := range obj.Fields {
+			def := t.Values.Get(field.Name.Value)
+			if def == nil {
+				s.unknown = append(s.unknown, field.Loc)
+				continue
+			}
+			s.stripValue(field.Value, def.Type)
+		}
+	}
+}
+
+// fieldDefinition returns the definition of the named field of an object or interface type, if it has one
+func fieldDefinition(t types.NamedType, name string) *types.FieldDefinition {
+	switch t := t.(type) {
+	case *types.ObjectTypeDefinition:
+		return t.Fields.Get(name)
+	case *types.InterfaceTypeDefinition:
+		return t.Fields.Get(name)
+	}
+	return nil
+}
+
+// namedType unwraps the list and non-null wrappers of a type
+func namedType(t types.Type) types.NamedType {
+	for {
+		switch wrapper := t.(type) {
+		case *types.NonNull:
+			t = wrapper.OfType
+		case *types.List:
+			t = wrapper.OfType
+		default:
+			named, _ := t.(types.NamedType)
+			return named
+		}
+	}
+}
@@ -47,11 +47,33 @@ const schema string = `
         # Code contains the smart contract code for this account, if the account
         # is a (non-self-destructed) contract.
         code: Bytes!
+        # CodeChunk is the slice of the code starting at offset, of at most length
+        # bytes, so that large contract code can be fetched in parts.
+        codeChunk(offset: Long!, length: Long!): Bytes!
         # Storage provides access to the storage of a contract account, indexed
         # by its 32 byte slot identifier.
         storage(slot: Bytes32!): Bytes32!
     }
 
+    # AccessTuple is an entry of an EIP-2930 transaction access list.
+    type AccessTuple {
+        # Address is the address of the accessed account.
+        address: Address!
+        # StorageKeys is the list of accessed storage keys of the account.
+        storageKeys: [Bytes32!]!
+    }
+
+    # TransactionSignature holds the raw signature values of a transaction.
+    type TransactionSignature {
+        r: BigInt!
+        s: BigInt!
+        v: BigInt!
+        # YParity is the parity of the y coordinate of the signature point, which is
+        # the v value of EIP-2930 and EIP-1559 transactions. This field will be null
+        # for legacy transactions.
+        yParity: Long
+    }
+
     # Log is an Ethereum event log.
     type Log {
         # Index is the index of this log in the block.
@@ -98,18 +120,27 @@ const schema string = `
         value: BigInt!
         # GasPrice is the price offered to miners for gas, in wei per unit.
         gasPrice: BigInt!
+        # EffectiveTip is the tip per unit of gas paid to the miner, i.e. the minimum of
+        # the max priority fee and the max fee minus the block's base fee, or the gas
+        # price minus the base fee for legacy transactions. This field will be null if
+        # the transaction has not yet been mined or was mined before London.
+        effectiveTip: BigInt
         # Gas is the maximum amount of gas this transaction can consume.
         gas: Long!
         # InputData is the data supplied to the target of the transaction.
         inputData: Bytes!
+        # MethodSelector is the hex encoded first 4 bytes of the input data, which
+        # select the contract method being called. If the input data is shorter,
+        # as for plain value transfers, this field will be null.
+        methodSelector: String
         # Block is the block this transaction was mined in. This will be null if
         # the transaction has not yet been mined.
         block: Block
 
         # Status is the return status of the transaction. This will be 1 if the
         # transaction succeeded, or 0 if it failed (due to a revert, or due to
-        # running out of gas). If the transaction has not yet been mined, this
-        # field will be null.
+        # running out of gas). If the transaction has not yet been mined, or the
+        # receipt is a pre-Byzantium receipt without a status, this field will be null.
         status: Long
         # GasUsed is the amount of gas that was used processing this transaction.
         # If the transaction has not yet been mined, this field will be null.
@@ -118,6 +149,10 @@ const schema string = `
         # this transaction. If the transaction has not yet been mined, this field
         # will be null.
         cumulativeGasUsed: Long
+        # GasUsedByTransaction is the gas used by this transaction alone, computed
+        # as its cumulative gas used minus that of the preceding transaction in the
+        # block. If the transaction has not yet been mined, this field will be null.
+        gasUsedByTransaction: Long
         # CreatedContract is the account that was created by a contract creation
         # transaction. If the transaction was not a contract creation transaction,
         # or it has not yet been mined, this field will be null.
@@ -125,9 +160,33 @@ const schema string = `
         # Logs is a list of log entries emitted by this transaction. If the
         # transaction has not yet been mined, this field will be null.
         logs: [Log!]
+        # ReceiptBloom is the bloom filter of the logs emitted by this transaction,
+        # which can be used to check whether the receipt may contain a log before
+        # fetching it. If the transaction has not yet been mined, this field will be null.
+        receiptBloom: Bytes
+        # AccessList is the list of addresses and storage keys the transaction
+        # plans to access. This field will be null for legacy transactions.
+        accessList: [AccessTuple!]
         r: BigInt!
         s: BigInt!
         v: BigInt!
+        # Signature holds the r, s and v values of the transaction, and the y parity
+        # of typed transactions, resolved together.
+        signature: TransactionSignature
+        # YParity is the parity of the y coordinate of the signature point. This
+        # field will be null for legacy transactions, whose v value encodes it.
+        yParity: Long
+        # ChainID is the chain id the transaction was signed for. This field will
+        # be null for legacy transactions which are not EIP-155 protected.
+        chainID: BigInt
+        # Raw is the canonical encoding of the transaction, as stored in its IPLD block.
+        # For legacy transactions this is the RLP encoding, for typed transactions the
+        # type byte followed by the RLP encoding of the payload.
+        raw: Bytes!
+        # RawReceipt is the canonical encoding of the receipt of the transaction, as
+        # stored in its IPLD block. If the transaction has not yet been mined, this
+        # field will be empty.
+        rawReceipt: Bytes!
     }
 
     # BlockFilterCriteria encapsulates log filter criteria for a filter applied
@@ -173,17 +232,31 @@ const schema string = `
         miner(block: Long): Account!
         # ExtraData is an arbitrary data field supplied by the miner.
         extraData: Bytes!
+        # CliqueSigners is the extraData of a Clique proof-of-authority block split into its fields.
+        # If the chain does not use Clique, this field will be null.
+        cliqueSigners: CliqueExtraData
         # GasLimit is the maximum amount of gas that was available to transactions in this block.
         gasLimit: Long!
         # GasUsed is the amount of gas that was used executing transactions in this block.
         gasUsed: Long!
+        # Size is the size in bytes of the RLP encoding of this block, including its
+        # transactions and ommers.
+        size: Long!
+        # BaseFeePerGas is the fee per unit of gas burned by the protocol in this block.
+        # It is null for blocks mined before London (EIP-1559).
+        baseFeePerGas: BigInt
         # Timestamp is the unix timestamp at which this block was mined.
         timestamp: Long!
         # LogsBloom is a bloom filter that can be used to check if a block may
         # contain log entries matching a filter.
         logsBloom: Bytes!
         # MixHash is the hash that was used as an input to the PoW process.
+        # After the Merge this field carries the beacon chain's prevRandao value.
         mixHash: Bytes32!
+        # PrevRandao is the randomness value from the beacon chain, equivalent to
+        # mixHash for blocks produced after the Merge. If the block was produced
+        # before the Merge, this field will be null.
+        prevRandao: Bytes32
         # Difficulty is a measure of the difficulty of mining this block.
         difficulty: BigInt!
         # TotalDifficulty is the sum of all difficulty values up to and including
@@ -206,6 +279,16 @@ const schema string = `
         # Transactions is a list of transactions associated with this block. If
         # transactions are unavailable for this block, this field will be null.
         transactions: [Transaction!]
+        # ContractsCreatedCount is the number of contracts created by the transactions
+        # in this block. If the block is not canonical, this field will be null.
+        contractsCreatedCount: Long
+        # LogCount is the number of logs emitted by the transactions in this block.
+        # If the block is not canonical, this field will be null.
+        logCount: Long
+        # TransactionHashes is the list of the hashes of the transactions in this
+        # block, in order. If transactions are unavailable for this block, this
+        # field will be null.
+        transactionHashes: [Bytes32!]
         # TransactionAt returns the transaction at the specified index. If
         # transactions are unavailable for this block, or if the index is out of
         # bounds, this field will be null.
@@ -270,6 +353,57 @@ const schema string = `
         topics: [[Bytes32!]!]
     }
 
+    # The value a storage slot changed to at a block, zero if the slot was cleared.
+    type StorageSlotChange {
+        blockNumber: Long!
+        value: Bytes32!
+    }
+
+    # CliqueExtraData is the extraData of a Clique proof-of-authority block.
+    type CliqueExtraData {
+        # Vanity is the 32 byte prefix the signer is free to set.
+        vanity: Bytes!
+        # Signers is the list of authorized signers, only set at checkpoint blocks.
+        signers: [Address!]!
+        # Seal is the signer's signature of the block.
+        seal: Bytes!
+        # Sealer is the signer recovered from the seal. The genesis block is not sealed, and
+        # has no sealer.
+        sealer: Address
+    }
+
+    # BlockTag references a block relative to the head of the chain.
+    enum BlockTag {
+        EARLIEST
+        LATEST
+        PENDING
+        SAFE
+        FINALIZED
+    }
+
+    # LogsPage is a page of the logs matching a getLogsPage filter.
+    type LogsPage {
+        # Logs are the matching logs of the whole blocks within the configured cap.
+        logs: [Log!]!
+        # Truncated is set if more logs match the filter beyond the cap.
+        truncated: Boolean!
+        # NextBlock is the first block whose logs are not in the page, from which the query resumes,
+        # null unless the page is truncated.
+        nextBlock: BigInt
+    }
+
+    # Transfer is a decoded ERC-20 or ERC-721 Transfer(address,address,uint256) event.
+    type Transfer {
+        from: Address!
+        to: Address!
+        # Value is the amount transferred by an ERC-20 transfer, null for ERC-721 transfers.
+        value: BigInt
+        # TokenId is the token transferred by an ERC-721 transfer, null for ERC-20 transfers.
+        tokenId: BigInt
+        txHash: Bytes32!
+        logIndex: Long!
+    }
+
     # Storage trie value with IPLD data.
     type StorageResult {
         value: Bytes32!
@@ -281,9 +415,21 @@ const schema string = `
         ipldBlock: Bytes!
     }
 
+    # State trie leaf node with IPLD data.
+    type StateLeafResult {
+        # CID for the state trie leaf IPLD block.
+        cid: String!
+
+        # State trie leaf IPLD block.
+        ipldBlock: Bytes!
+    }
+
     input EthHeaderCidCondition {
         blockNumber: BigInt
         blockHash: String
+        # FromBlockNumber and ToBlockNumber bound the block numbers of paginated headers (inclusive).
+        fromBlockNumber: BigInt
+        toBlockNumber: BigInt
     }
 
     type EthTransactionCid {
@@ -293,6 +439,8 @@ const schema string = `
         src: String!
         dst: String!
         blockByMhKey: IPFSBlock!
+        # Data is the input data of the transaction; it is only included when requested with includeData.
+        data: Bytes
     }
 
     type EthTransactionCidsConnection {
@@ -316,39 +464,159 @@ const schema string = `
         receiptRoot: String!
         uncleRoot: String!
         bloom: String!
+        # Coinbase is the address of the beneficiary of the block, as indexed.
+        coinbase: String!
+        # NodeId is the ID of the indexer node which produced the header record.
+        nodeId: String!
         ethTransactionCidsByHeaderId: EthTransactionCidsConnection!
         blockByMhKey: IPFSBlock!
     }
 
     type EthHeaderCidsConnection {
         nodes: [EthHeaderCid]!
+        pageInfo: PageInfo!
+    }
+
+    # PageInfo describes the position of a page within a paginated listing.
+    type PageInfo {
+        # HasNextPage is true if there are more results after this page.
+        hasNextPage: Boolean!
+        # EndCursor is the cursor of the last result of this page, to be passed as after to get the next page.
+        endCursor: String
+    }
+
+    # GasPriceStats holds the effective gas price statistics of the canonical transactions in a block range.
+    type GasPriceStats {
+        # Count is the number of transactions in the range.
+        count: Long!
+        # Average is the average effective gas price, null if there are no transactions in the range.
+        average: BigInt
+        # Percentile is the requested percentile of the effective gas prices, null if there are no transactions in the range.
+        percentile: BigInt
+    }
+
+    # GasUsedRatio is the ratio of the gas used to the gas limit of a canonical block.
+    type GasUsedRatio {
+        # Number is the number of the block.
+        number: Long!
+        # Ratio is the gas used divided by the gas limit, 0 if the block has no gas limit.
+        ratio: Float!
+    }
+
+    # BlockHashAt is the hash of a block indexed at a height.
+    type BlockHashAt {
+        # Hash is the hash of the block.
+        hash: Bytes32!
+        # Canonical is true if the block is the canonical block at the height.
+        canonical: Boolean!
+    }
+
+    # BlockRange is an inclusive range of block numbers.
+    type BlockRange {
+        from: Long!
+        to: Long!
     }
 
     type Query {
-        # Block fetches an Ethereum block by number or by hash. If neither is
-        # supplied, the most recent known block is returned.
-        block(number: Long, hash: Bytes32): Block
+        # Block fetches an Ethereum block by number, by hash, or by tag. If none is
+        # supplied, the most recent known block is returned. The safe and finalized
+        # tags are resolved through the proxy node, while pending, which is never
+        # indexed, resolves to the most recent known block.
+        block(number: Long, hash: Bytes32, tag: BlockTag): Block
 
         # Blocks returns all the blocks between two numbers, inclusive. If
-        # to is not supplied, it defaults to the most recent known block.
+        # to is not supplied, it defaults to the most recent known block, subject
+        # to the server's limit on the length of such a range.
         blocks(from: Long!, to: Long): [Block!]!
 
         # Transaction returns a transaction specified by its hash.
         transaction(hash: Bytes32!): Transaction
 
+        # CodeChunk returns the slice of the contract's code starting at offset, of at
+        # most length bytes, at the given block. The block defaults to the most recent known block.
+        codeChunk(address: Address!, block: Long, offset: Long!, length: Long!): Bytes!
+
         # Logs returns log entries matching the provided filter.
         logs(filter: FilterCriteria!): [Log!]!
 
-        # Get storage slot by block hash and contract address.
-        getStorageAt(blockHash: Bytes32!, contract: Address!, slot: Bytes32!): StorageResult
+        # Get storage slot by block hash or canonical block number and contract address.
+        # If both a (non-zero) block hash and a block number are supplied, the hash is preferred.
+        getStorageAt(blockHash: Bytes32, blockNumber: BigInt, contract: Address!, slot: Bytes32!): StorageResult
+
+        # Get the state trie leaf for an account by block hash and address.
+        # Returns null if the account does not exist at the given block.
+        stateLeaf(blockHash: Bytes32!, address: Address!): StateLeafResult
+
+        # Get contract logs by block hash, or over a range (inclusive) of canonical blocks with toBlock defaulting to the latest block,
+        # and contract address.
+        # Fails if the logs exceed the configured cap on the results of a single query.
+        getLogs(blockHash: Bytes32, blockNumber: BigInt, fromBlock: BigInt, toBlock: BigInt, addresses: [Address!], topics: [[Bytes32!]!]): [Log!]
 
-        # Get contract logs by block hash and contract address.
-        getLogs(blockHash: Bytes32!, blockNumber: BigInt, addresses: [Address!]): [Log!]
+        # Get contract logs as for getLogs, truncated to the whole blocks within the configured cap on the results of a single query.
+        # Truncated is set if more logs match beyond the cap, in which case the query resumes with nextBlock as its fromBlock.
+        # Fails if the logs of a single block exceed the cap.
+        getLogsPage(blockHash: Bytes32, blockNumber: BigInt, fromBlock: BigInt, toBlock: BigInt, addresses: [Address!], topics: [[Bytes32!]!]): LogsPage!
 
         # PostGraphile alternative to get headers with transactions using block number or block hash.
-        allEthHeaderCids(condition: EthHeaderCidCondition): EthHeaderCidsConnection
+        # Headers at a block number are returned canonical first, optionally limited to the given number of headers.
+        # Paginating with first and after instead returns the headers in a block number range ordered by block number and hash,
+        # in pages of 100 headers unless first is provided, which can be at most 1000.
+        allEthHeaderCids(condition: EthHeaderCidCondition, limit: Int, first: Int, after: String): EthHeaderCidsConnection
 
         # PostGraphile alternative to get transactions using transaction hash.
-        ethTransactionCidByTxHash(txHash: String!, blockNumber: BigInt): EthTransactionCid
+        ethTransactionCidByTxHash(txHash: String!, blockNumber: BigInt, includeData: Boolean = false): EthTransactionCid
+
+        # Get the canonical transactions for multiple transaction hashes in a single query.
+        ethTransactionCidsByTxHashes(txHashes: [String!]!, includeData: Boolean = false): EthTransactionCidsConnection!
+
+        # Get the canonical block numbers in the range (inclusive) at which the storage of the contract changed.
+        # The range is subject to the server's limit on the blocks spanned by a logs range.
+        storageChangeBlocks(address: Address!, from: Long!, to: Long!): [Long!]!
+
+        # Get the values a storage slot of the contract changed to at the canonical blocks in the range (inclusive).
+        # The range is subject to the server's limit on the blocks spanned by a logs range.
+        storageSlotHistory(address: Address!, slot: Bytes32!, from: Long!, to: Long!): [StorageSlotChange!]!
+
+        # Get the distinct event signatures (topic0) of the canonical logs emitted by the contract in the range (inclusive).
+        # The range defaults to all blocks up to the latest, and is subject to the server's limit on the blocks spanned by a logs range.
+        eventSignatures(address: Address!, from: Long, to: Long): [Bytes32!]!
+
+        # Get the range of the first and last canonical blocks at which the contract emitted logs, null if it has not emitted any.
+        logBlockRange(address: Address!): BlockRange
+
+        # Get the decoded ERC-20 and ERC-721 transfers emitted by the contract over a block range (inclusive),
+        # with toBlock defaulting to the latest block. The query fails if the transfer logs exceed the server's limit on logs.
+        transfers(address: Address!, fromBlock: Long!, toBlock: Long): [Transfer!]!
+
+        # Get the canonical transactions sent from or to the address over a block range (inclusive),
+        # ordered by block number and then index.
+        # The range is subject to the server's limit on the blocks spanned by a logs range, and the query fails if it
+        # matches more transactions than the server's limit.
+        transactionsByAddress(address: Address!, fromBlock: Long!, toBlock: Long!): [Transaction!]!
+
+        # Get the number of the canonical block with the hash, without decoding its header.
+        # Returns null if the block is not indexed, and fails if it is not canonical.
+        blockNumberByHash(hash: Bytes32!): Long
+
+        # Get the number of the earliest canonical block at which the account held code,
+        # i.e. the block the contract was deployed at. Returns null if it never held code.
+        contractDeploymentBlock(address: Address!): Long
+
+        # Get the hashes of all the blocks indexed at the height, canonical or orphaned, the canonical block first.
+        blockHashesAt(number: Long!): [BlockHashAt!]!
+
+        # Get the leaf keys of the accounts removed (e.g. self-destructed) in the block, if it is canonical.
+        removedAccounts(blockHash: Bytes32!): [Bytes32!]!
+
+        # Get the average and a percentile (0-100) of the effective gas prices paid by the canonical transactions in the range (inclusive).
+        # The range is subject to the server's limit on the blocks spanned by a logs range.
+        gasPriceStats(from: Long!, to: Long!, percentile: Int = 50): GasPriceStats!
+
+        # Get the ratio of the gas used to the gas limit of each canonical block in the range (inclusive), ordered by number.
+        # The range is subject to the server's limit on the blocks spanned by a logs range.
+        gasUsedRatios(from: Long!, to: Long!): [GasUsedRatio!]!
+
+        # Get the number of distinct addresses sending or receiving the canonical transactions at the block number.
+        activeAddressCount(blockNumber: Long!): Long!
     }
 `
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/node"
@@ -27,30 +28,37 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/rs/cors"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
 )
 
+// Config holds the settings of a GraphQL service.
+type Config struct {
+	Endpoint        string           // The host:port endpoint for the service.
+	CORS            []string         // Allowed CORS domains
+	CORSMaxAge      int              // Seconds browsers may cache CORS preflight results for, 0 omits the header
+	VHosts          []string         // Recognised vhosts
+	Timeouts        rpc.HTTPTimeouts // Timeout settings for HTTP requests.
+	BlocksLogsLimit int64            // Max blocks in a `blocks` list times the logs selections on each block, 0 disables the cap
+	FieldTimeout    time.Duration    // Max duration of each resolver field, including the fields selected beneath it, 0 disables the bound
+	LenientInput    bool             // Ignore the unknown fields of input objects instead of rejecting the query
+}
+
 // Service encapsulates a GraphQL service.
 type Service struct {
-	endpoint string           // The host:port endpoint for this service.
-	cors     []string         // Allowed CORS domains
-	vhosts   []string         // Recognised vhosts
-	timeouts rpc.HTTPTimeouts // Timeout settings for HTTP requests.
-	backend  *eth.Backend     // The backend that queries will operate onn.
-	handler  http.Handler     // The `http.Handler` used to answer queries.
-	listener net.Listener     // The listening socket.
+	config   Config       // The settings of the service.
+	backend  *eth.Backend // The backend that queries will operate onn.
+	handler  http.Handler // The `http.Handler` used to answer queries.
+	listener net.Listener // The listening socket.
 }
 
 // New constructs a new GraphQL service instance.
-func New(backend *eth.Backend, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) (*Service, error) {
+func New(backend *eth.Backend, config Config) (*Service, error) {
 	return &Service{
-		endpoint: endpoint,
-		cors:     cors,
-		vhosts:   vhosts,
-		timeouts: timeouts,
-		backend:  backend,
+		config:  config,
+		backend: backend,
 	}, nil
 }
 
@@ -64,15 +72,16 @@ func (s *Service) APIs() []rpc.API { return nil }
 // layer was also initialized to spawn any goroutines required by the service.
 func (s *Service) Start(server *p2p.Server) error {
 	var err error
-	s.handler, err = NewHandler(s.backend)
+	s.handler, err = NewHandler(s.backend, s.config.BlocksLogsLimit, s.config.FieldTimeout, s.config.LenientInput)
 	if err != nil {
 		return err
 	}
 
-	handler := node.NewHTTPHandlerStack(s.handler, s.cors, s.vhosts, nil)
+	// CORS is handled here rather than by the node handler stack so that the preflight max age can be configured
+	handler := node.NewHTTPHandlerStack(newCorsHandler(s.handler, s.config.CORS, s.config.CORSMaxAge), nil, s.config.VHosts, nil)
 
 	// start http server
-	_, addr, err := node.StartHTTPEndpoint(s.endpoint, rpc.DefaultHTTPTimeouts, handler)
+	_, addr, err := node.StartHTTPEndpoint(s.config.Endpoint, rpc.DefaultHTTPTimeouts, handler)
 	if err != nil {
 		utils.Fatalf("Could not start RPC api: %v", err)
 	}
@@ -81,16 +90,40 @@ func (s *Service) Start(server *p2p.Server) error {
 	return nil
 }
 
+// newCorsHandler wraps the handler with CORS support for the allowed origins;
+// CORS support is disabled if no origins are provided
+func newCorsHandler(srv http.Handler, allowedOrigins []string, maxAge int) http.Handler {
+	if len(allowedOrigins) == 0 {
+		return srv
+	}
+	c := cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{http.MethodPost, http.MethodGet},
+		AllowedHeaders: []string{"*"},
+		MaxAge:         maxAge,
+	})
+	return c.Handler(srv)
+}
+
 // newHandler returns a new `http.Handler` that will answer GraphQL queries.
 // It additionally exports an interactive query browser on the / endpoint.
-func NewHandler(backend *eth.Backend) (http.Handler, error) {
-	q := Resolver{backend}
-
-	s, err := graphql.ParseSchema(schema, &q)
+// A positive fieldTimeout bounds each resolver field, failing the field with a deadline error once it expires.
+// With lenientInput, the unknown fields of the input objects of a query are dropped rather than failing it.
+func NewHandler(backend *eth.Backend, blocksLogsLimit int64, fieldTimeout time.Duration, lenientInput bool) (http.Handler, error) {
+	q := Resolver{backend: backend, blocksLogsLimit: blocksLogsLimit}
+
+	var opts []graphql.SchemaOpt
+	if fieldTimeout > 0 {
+		opts = append(opts, graphql.Tracer(NewFieldTimeoutTracer(fieldTimeout)))
+	}
+	s, err := graphql.ParseSchema(schema, &q, opts...)
 	if err != nil {
 		return nil, err
 	}
-	h := &relay.Handler{Schema: s}
+	var h http.Handler = &relay.Handler{Schema: s}
+	if lenientInput {
+		h = &lenientHandler{schema: s}
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", GraphiQL{})
@@ -105,7 +138,7 @@ func (s *Service) Stop() error {
 	if s.listener != nil {
 		s.listener.Close()
 		s.listener = nil
-		log.Debugf("graphQL endpoint closed for url %s", fmt.Sprintf("http://%s", s.endpoint))
+		log.Debugf("graphQL endpoint closed for url %s", fmt.Sprintf("http://%s", s.config.Endpoint))
 	}
 	return nil
 }
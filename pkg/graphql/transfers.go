@@ -0,0 +1,147 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+// TransferTopic is the topic of the Transfer(address,address,uint256) event shared by ERC-20 and ERC-721
+var TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Transfer is a decoded ERC-20 or ERC-721 transfer event
+type Transfer struct {
+	from     common.Address
+	to       common.Address
+	value    *big.Int
+	tokenID  *big.Int
+	txHash   common.Hash
+	logIndex uint
+}
+
+func (t *Transfer) From(ctx context.Context) common.Address {
+	return t.from
+}
+
+func (t *Transfer) To(ctx context.Context) common.Address {
+	return t.to
+}
+
+// Value is the amount of an ERC-20 transfer, null for ERC-721 transfers
+func (t *Transfer) Value(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(t.value)
+}
+
+// TokenId is the token of an ERC-721 transfer, null for ERC-20 transfers
+func (t *Transfer) TokenId(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(t.tokenID)
+}
+
+func (t *Transfer) TxHash(ctx context.Context) common.Hash {
+	return t.txHash
+}
+
+func (t *Transfer) LogIndex(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.logIndex)
+}
+
+// DecodeTransfer decodes a Transfer event log, returning nil if it is neither an ERC-20 nor an ERC-721 transfer.
+// Both standards index the sender and recipient; ERC-20 carries the value in the data while ERC-721 indexes the token id.
+func DecodeTransfer(log *types.Log) *Transfer {
+	if len(log.Topics) < 3 || log.Topics[0] != TransferTopic {
+		return nil
+	}
+	transfer := &Transfer{
+		from:     common.BytesToAddress(log.Topics[1].Bytes()),
+		to:       common.BytesToAddress(log.Topics[2].Bytes()),
+		txHash:   log.TxHash,
+		logIndex: log.Index,
+	}
+	switch {
+	case len(log.Topics) == 3 && len(log.Data) == common.HashLength:
+		transfer.value = new(big.Int).SetBytes(log.Data)
+	case len(log.Topics) == 4 && len(log.Data) == 0:
+		transfer.tokenID = log.Topics[3].Big()
+	default:
+		return nil
+	}
+	return transfer
+}
+
+// Transfers returns the decoded ERC-20 and ERC-721 transfers emitted by the contract over a block range (inclusive),
+// with toBlock defaulting to the latest block
+func (r *Resolver) Transfers(ctx context.Context, args struct {
+	Address   common.Address
+	FromBlock hexutil.Uint64
+	ToBlock   *hexutil.Uint64
+}) ([]*Transfer, error) {
+	from := int64(args.FromBlock)
+	var to int64
+	if args.ToBlock != nil {
+		to = int64(*args.ToBlock)
+	} else {
+		var err error
+		if to, err = r.backend.Retriever.RetrieveLastBlockNumber(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.backend.CheckLogsBlockRange(from, to); err != nil {
+		return nil, err
+	}
+
+	filter := eth.ReceiptFilter{
+		LogAddresses: []string{args.Address.String()},
+		Topics:       [][]string{{TransferTopic.String()}},
+	}
+
+	tx, err := r.backend.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	// transfers of reorged blocks are not reported
+	filteredLogs, next, err := r.backend.Retriever.RetrieveFilteredCanonicalLogsInRange(ctx, tx, filter, from, to, r.backend.Config.LogsMaxResults)
+	if err != nil {
+		shared.Rollback(tx)
+		return nil, err
+	}
+	if next != 0 {
+		shared.Rollback(tx)
+		return nil, r.backend.LogsResultCountError()
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Transfer, 0)
+	for _, l := range decomposeGQLLogs(filteredLogs) {
+		if transfer := DecodeTransfer(l.Log); transfer != nil {
+			ret = append(ret, transfer)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,87 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package graphql_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/graphql"
+)
+
+var _ = Describe("DecodeTransfer", func() {
+	var (
+		ctx    = context.Background()
+		from   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		to     = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		txHash = common.HexToHash("0x03")
+	)
+
+	It("Decodes an ERC-20 transfer, with the value in the data", func() {
+		transfer := graphql.DecodeTransfer(&types.Log{
+			Topics: []common.Hash{graphql.TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:   common.BigToHash(big.NewInt(1000)).Bytes(),
+			TxHash: txHash,
+			Index:  2,
+		})
+		Expect(transfer).ToNot(BeNil())
+		Expect(transfer.From(ctx)).To(Equal(from))
+		Expect(transfer.To(ctx)).To(Equal(to))
+		Expect(transfer.Value(ctx)).To(Equal((*hexutil.Big)(big.NewInt(1000))))
+		Expect(transfer.TokenId(ctx)).To(BeNil())
+		Expect(transfer.TxHash(ctx)).To(Equal(txHash))
+		Expect(transfer.LogIndex(ctx)).To(Equal(hexutil.Uint64(2)))
+	})
+
+	It("Decodes an ERC-721 transfer, with the token id indexed", func() {
+		transfer := graphql.DecodeTransfer(&types.Log{
+			Topics: []common.Hash{graphql.TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(42))},
+			TxHash: txHash,
+			Index:  5,
+		})
+		Expect(transfer).ToNot(BeNil())
+		Expect(transfer.From(ctx)).To(Equal(from))
+		Expect(transfer.To(ctx)).To(Equal(to))
+		Expect(transfer.Value(ctx)).To(BeNil())
+		Expect(transfer.TokenId(ctx)).To(Equal((*hexutil.Big)(big.NewInt(42))))
+		Expect(transfer.TxHash(ctx)).To(Equal(txHash))
+		Expect(transfer.LogIndex(ctx)).To(Equal(hexutil.Uint64(5)))
+	})
+
+	It("Does not decode other logs", func() {
+		// another event
+		Expect(graphql.DecodeTransfer(&types.Log{
+			Topics: []common.Hash{common.HexToHash("0x01"), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:   common.BigToHash(big.NewInt(1000)).Bytes(),
+		})).To(BeNil())
+		// a transfer missing its value
+		Expect(graphql.DecodeTransfer(&types.Log{
+			Topics: []common.Hash{graphql.TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		})).To(BeNil())
+		// a transfer missing its recipient
+		Expect(graphql.DecodeTransfer(&types.Log{
+			Topics: []common.Hash{graphql.TransferTopic, common.BytesToHash(from.Bytes())},
+			Data:   common.BigToHash(big.NewInt(1000)).Bytes(),
+		})).To(BeNil())
+	})
+})
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
@@ -40,17 +41,18 @@ const (
 )
 
 // Peek at the request and update the Context accordingly (eg, API method, user ID, etc.)
-func preprocessRequest(r *http.Request) (*http.Request, error) {
+// The body of the request is returned along with it.
+func preprocessRequest(r *http.Request) (*http.Request, []byte, error) {
 	// Generate a unique ID for this request.
 	uniqId, err := uuid.NewUUID()
 	if nil != err {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Read the body so that we can peek inside.
 	body, err := io.ReadAll(r.Body)
 	if nil != err {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Replace it with a re-readable copy.
@@ -61,7 +63,7 @@ func preprocessRequest(r *http.Request) (*http.Request, error) {
 	if len(body) > 0 {
 		err = json.Unmarshal(body, &result)
 		if nil != err {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -90,14 +92,31 @@ func preprocessRequest(r *http.Request) (*http.Request, error) {
 	ctx = context.WithValue(ctx, log.CtxKeyUserId, userId)
 	ctx = context.WithValue(ctx, log.CtxKeyConn, conn)
 
-	return r.WithContext(ctx), nil
+	return r.WithContext(ctx), body, nil
+}
+
+// meteredResponseWriter passes the body written to a response on to the writer observing it, before it is sent
+type meteredResponseWriter struct {
+	http.ResponseWriter
+	written io.Writer
+}
+
+func (w *meteredResponseWriter) Write(b []byte) (int, error) {
+	w.written.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *meteredResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
 
 // HTTPMiddleware http connection metric reader
 func HTTPMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		r, err := preprocessRequest(r)
+		r, body, err := preprocessRequest(r)
 		if nil != err {
 			log.WithError(err).Error("Error preprocessing request")
 			w.WriteHeader(http.StatusBadRequest)
@@ -111,7 +130,13 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 		}
 
 		log.Debugx(ctx, "START")
-		next.ServeHTTP(w, r)
+		if metrics {
+			t := newRPCTracker()
+			t.requestScanner().Write(body)
+			next.ServeHTTP(&meteredResponseWriter{ResponseWriter: w, written: t.responseScanner()}, r)
+		} else {
+			next.ServeHTTP(w, r)
+		}
 		duration := time.Now().Sub(start)
 		log.Debugxf(context.WithValue(ctx, log.CtxKeyDuration, duration.Milliseconds()), "END")
 
@@ -121,7 +146,7 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// WSMiddleware websocket connection counter
+// WSMiddleware websocket connection counter, also recording the calls served over the connection
 func WSMiddleware(next http.Handler) http.Handler {
 	if !metrics {
 		return next
@@ -129,18 +154,20 @@ func WSMiddleware(next http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		wsCount.Inc()
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(&wsHijacker{ResponseWriter: w, tracker: newRPCTracker()}, r)
 		wsCount.Dec()
 	})
 }
 
-// IPCMiddleware unix-socket connection counter
-func IPCMiddleware(server *rpc.Server, client rpc.Conn) {
-	if metrics {
-		ipcCount.Inc()
-	}
-	server.ServeCodec(rpc.NewCodec(client), 0)
-	if metrics {
-		ipcCount.Dec()
+// IPCMiddleware unix-socket connection counter, also recording the calls served over the connection
+func IPCMiddleware(server *rpc.Server, client net.Conn) {
+	if !metrics {
+		server.ServeCodec(rpc.NewCodec(client), 0)
+		return
 	}
+
+	ipcCount.Inc()
+	t := newRPCTracker()
+	server.ServeCodec(rpc.NewCodec(&meteredConn{Conn: client, read: t.requestScanner(), written: t.responseScanner()}), 0)
+	ipcCount.Dec()
 }
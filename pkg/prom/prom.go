@@ -28,6 +28,9 @@ const (
 	subsystemHTTP = "http"
 	subsystemWS   = "ws"
 	subsystemIPC  = "ipc"
+	subsystemRPC  = "rpc"
+
+	subsystemSubscription = "subscription"
 )
 
 var (
@@ -37,6 +40,11 @@ var (
 	httpDuration *prometheus.HistogramVec
 	wsCount      prometheus.Gauge
 	ipcCount     prometheus.Gauge
+	rpcDuration  *prometheus.HistogramVec
+	rpcErrors    *prometheus.CounterVec
+
+	subscriptionBlocked *prometheus.CounterVec
+	subscriptionDropped *prometheus.CounterVec
 )
 
 // Init module initialization
@@ -70,6 +78,48 @@ func Init() {
 		Name:      "count",
 		Help:      "unix socket connection count",
 	})
+
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystemRPC,
+		Name:      "duration",
+		Help:      "rpc call duration",
+	}, []string{"method"})
+
+	rpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemRPC,
+		Name:      "errors",
+		Help:      "rpc call error count",
+	}, []string{"method"})
+
+	subscriptionBlocked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemSubscription,
+		Name:      "blocked",
+		Help:      "count of sends blocked on a full subscription channel",
+	}, []string{"type"})
+
+	subscriptionDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemSubscription,
+		Name:      "dropped",
+		Help:      "count of subscriptions closed as slow consumers",
+	}, []string{"type"})
+}
+
+// SubscriptionBlocked counts a send of the given subscription type which blocked on a full channel
+func SubscriptionBlocked(subType string) {
+	if metrics {
+		subscriptionBlocked.WithLabelValues(subType).Inc()
+	}
+}
+
+// SubscriptionDropped counts a subscription of the given type closed as a slow consumer
+func SubscriptionDropped(subType string) {
+	if metrics {
+		subscriptionDropped.WithLabelValues(subType).Inc()
+	}
 }
 
 // RegisterDBCollector create metric colletor for given connection
@@ -0,0 +1,294 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package prom
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// unknownRPCMethod labels the calls of methods the server does not serve, to bound the label values
+	unknownRPCMethod = "unknown"
+	// methodNotFoundCode is the JSON-RPC error code for calls of methods that do not exist
+	methodNotFoundCode = -32601
+	// maxScannedLength bounds the keys and values kept by an rpcScanner, longer ones are discarded
+	maxScannedLength = 256
+)
+
+// rpcMessage holds the fields of a JSON-RPC request or response needed to pair them up
+type rpcMessage struct {
+	ID     string // The raw JSON id, empty for notifications
+	Method string
+	Failed bool // Whether the message is an error response
+	Code   int  // The error code of an error response
+}
+
+type pendingCall struct {
+	method string
+	start  time.Time
+}
+
+// rpcTracker pairs the JSON-RPC calls read from a connection with the responses written to it,
+// recording the latency and errors of each call by method
+type rpcTracker struct {
+	mu      sync.Mutex
+	pending map[string]pendingCall
+}
+
+func newRPCTracker() *rpcTracker {
+	return &rpcTracker{pending: make(map[string]pendingCall)}
+}
+
+// request starts timing a call; notifications, which have no id, get no response and are not timed
+func (t *rpcTracker) request(msg rpcMessage) {
+	if msg.Method == "" || msg.ID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending[msg.ID] = pendingCall{method: msg.Method, start: time.Now()}
+}
+
+// response records the call answered by a response
+func (t *rpcTracker) response(msg rpcMessage) {
+	t.mu.Lock()
+	call, ok := t.pending[msg.ID]
+	delete(t.pending, msg.ID)
+	t.mu.Unlock()
+	if msg.ID == "" || !ok {
+		return
+	}
+	method := call.method
+	if msg.Failed && msg.Code == methodNotFoundCode {
+		method = unknownRPCMethod
+	}
+	observeRPC(method, time.Since(call.start), msg.Failed)
+}
+
+// rpcScanner scans a stream of JSON-RPC messages, single or batched, as it is written to it, passing the id, method
+// and error code of each message on without retaining the rest of it, such as the result of a call
+type rpcScanner struct {
+	observe func(rpcMessage)
+
+	frames   []scanFrame // The objects and arrays enclosing the current position
+	msgDepth int         // The depth of the messages in the current value, 2 for a batch
+	msg      rpcMessage
+
+	inString bool
+	escaped  bool
+	inKey    bool
+	key      []byte
+
+	field    string // The message field whose value is being captured, if any
+	value    []byte
+	overflow bool
+}
+
+type scanFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+}
+
+func newRPCScanner(observe func(rpcMessage)) *rpcScanner {
+	return &rpcScanner{observe: observe}
+}
+
+func (s *rpcScanner) Write(p []byte) (int, error) {
+	for _, c := range p {
+		s.scan(c)
+	}
+	return len(p), nil
+}
+
+func (s *rpcScanner) top() *scanFrame {
+	if len(s.frames) == 0 {
+		return nil
+	}
+	return &s.frames[len(s.frames)-1]
+}
+
+func (s *rpcScanner) scan(c byte) {
+	if s.inString {
+		end := !s.escaped && c == '"'
+		s.escaped = !s.escaped && c == '\\'
+		if end {
+			s.inString = false
+		}
+		if s.inKey {
+			if end {
+				s.inKey = false
+				s.top().key = string(s.key)
+			} else if len(s.key) < maxScannedLength {
+				s.key = append(s.key, c)
+			}
+			return
+		}
+		s.capture(c)
+		return
+	}
+
+	switch c {
+	case ' ', '\t', '\r', '\n':
+	case '"':
+		s.inString = true
+		if top := s.top(); top != nil && top.object && top.expectKey {
+			s.inKey = true
+			s.key = s.key[:0]
+			return
+		}
+		s.startValue(c)
+		s.capture(c)
+	case ':':
+		if top := s.top(); top != nil {
+			top.expectKey = false
+		}
+	case ',':
+		s.endValue()
+		if top := s.top(); top != nil && top.object {
+			top.expectKey = true
+			top.key = ""
+		}
+	case '{', '[':
+		s.startValue(c)
+		s.frames = append(s.frames, scanFrame{object: c == '{', expectKey: c == '{'})
+		if len(s.frames) == s.msgDepth && c == '{' {
+			s.msg = rpcMessage{}
+		}
+	case '}', ']':
+		s.endValue()
+		if len(s.frames) == 0 {
+			return
+		}
+		if len(s.frames) == s.msgDepth && s.top().object {
+			s.observe(s.msg)
+		}
+		s.frames = s.frames[:len(s.frames)-1]
+	default:
+		s.startValue(c)
+		s.capture(c)
+	}
+}
+
+// startValue notes the fields of interest of a message as their values start
+func (s *rpcScanner) startValue(c byte) {
+	depth := len(s.frames)
+	if depth == 0 {
+		s.msgDepth = 1
+		if c == '[' {
+			s.msgDepth = 2
+		}
+		return
+	}
+	top := s.top()
+	if !top.object || s.field != "" {
+		return
+	}
+	switch {
+	case depth == s.msgDepth && (top.key == "id" || top.key == "method"):
+		s.field = top.key
+	case depth == s.msgDepth && top.key == "error" && c == '{':
+		s.msg.Failed = true
+	case depth == s.msgDepth+1 && top.key == "code" && s.frames[depth-2].key == "error":
+		s.field = top.key
+	}
+	if s.field != "" {
+		if c == '{' || c == '[' {
+			// only scalar values are captured
+			s.field = ""
+			return
+		}
+		s.value = s.value[:0]
+		s.overflow = false
+	}
+}
+
+// capture appends a byte of the scalar value being captured
+func (s *rpcScanner) capture(c byte) {
+	if s.field == "" {
+		return
+	}
+	if len(s.value) >= maxScannedLength {
+		s.overflow = true
+		return
+	}
+	s.value = append(s.value, c)
+}
+
+// endValue sets the field whose value was being captured on the message
+func (s *rpcScanner) endValue() {
+	field := s.field
+	s.field = ""
+	if field == "" || s.overflow {
+		return
+	}
+	switch field {
+	case "id":
+		if id := string(s.value); id != "null" {
+			s.msg.ID = id
+		}
+	case "method":
+		json.Unmarshal(s.value, &s.msg.Method)
+	case "code":
+		s.msg.Code, _ = strconv.Atoi(string(s.value))
+	}
+}
+
+// requestScanner returns a scanner starting the timing of the calls written to it
+func (t *rpcTracker) requestScanner() *rpcScanner {
+	return newRPCScanner(t.request)
+}
+
+// responseScanner returns a scanner recording the calls answered by the responses written to it
+func (t *rpcTracker) responseScanner() *rpcScanner {
+	return newRPCScanner(t.response)
+}
+
+// meteredConn passes the bytes read from and written to a connection on to the writers observing them; written
+// bytes are observed before they are sent, so that a call is recorded before its client sees the response
+type meteredConn struct {
+	net.Conn
+	read    io.Writer
+	written io.Writer
+}
+
+func (c *meteredConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Write(b[:n])
+	return n, err
+}
+
+func (c *meteredConn) Write(b []byte) (int, error) {
+	c.written.Write(b)
+	return c.Conn.Write(b)
+}
+
+// observeRPC records the latency of a call, and its failure if it failed
+func observeRPC(method string, duration time.Duration, failed bool) {
+	if !metrics {
+		return
+	}
+	rpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+	if failed {
+		rpcErrors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,201 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package prom_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/prom"
+)
+
+type testService struct{}
+
+func (testService) Echo(s string) string {
+	return s
+}
+
+func (testService) Fail() error {
+	return errors.New("failed")
+}
+
+// metricValue returns the value of the counter, or the sample count of the histogram, of the named metric with the method label
+func metricValue(name, method string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() != "method" || label.GetValue() != method {
+					continue
+				}
+				if metric.GetCounter() != nil {
+					return metric.GetCounter().GetValue()
+				}
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+// metricsSpecs describes the metrics recorded for the calls served over a transport, dialing a client with dial;
+// the closer returned with the client closes it, and waits for the server to be done with its connection
+func metricsSpecs(batches bool, dial func(server *rpc.Server) (*rpc.Client, func())) {
+	var (
+		server *rpc.Server
+		client *rpc.Client
+		closer func()
+	)
+
+	BeforeEach(func() {
+		server = rpc.NewServer()
+		Expect(server.RegisterName("test", testService{})).To(Succeed())
+		client, closer = dial(server)
+	})
+
+	AfterEach(func() {
+		closer()
+		server.Stop()
+	})
+
+	It("Records the latency of calls by method", func() {
+		var res string
+		Expect(client.Call(&res, "test_echo", "hello")).To(Succeed())
+		Expect(res).To(Equal("hello"))
+		Expect(metricValue("ipld_eth_server_rpc_duration", "test_echo")).To(Equal(float64(1)))
+		Expect(metricValue("ipld_eth_server_rpc_errors", "test_echo")).To(Equal(float64(0)))
+	})
+
+	It("Increments the error counter of a method after a failed call", func() {
+		Expect(client.Call(nil, "test_fail")).ToNot(Succeed())
+		Expect(metricValue("ipld_eth_server_rpc_errors", "test_fail")).To(Equal(float64(1)))
+
+		Expect(client.Call(nil, "test_fail")).ToNot(Succeed())
+		Expect(metricValue("ipld_eth_server_rpc_errors", "test_fail")).To(Equal(float64(2)))
+		Expect(metricValue("ipld_eth_server_rpc_duration", "test_fail")).To(Equal(float64(2)))
+	})
+
+	It("Labels calls of unknown methods as unknown", func() {
+		Expect(client.Call(nil, "test_missing")).ToNot(Succeed())
+		Expect(metricValue("ipld_eth_server_rpc_errors", "unknown")).To(Equal(float64(1)))
+		Expect(metricValue("ipld_eth_server_rpc_errors", "test_missing")).To(Equal(float64(0)))
+	})
+
+	It("Records each call of a batch", func() {
+		if !batches {
+			Skip("batches are not served over this transport")
+		}
+		var res string
+		batch := []rpc.BatchElem{
+			{Method: "test_echo", Args: []interface{}{"hello"}, Result: &res},
+			{Method: "test_fail"},
+			{Method: "test_missing"},
+		}
+		Expect(client.BatchCall(batch)).To(Succeed())
+		Expect(res).To(Equal("hello"))
+		Expect(batch[1].Error).To(HaveOccurred())
+		Expect(metricValue("ipld_eth_server_rpc_duration", "test_echo")).To(Equal(float64(1)))
+		Expect(metricValue("ipld_eth_server_rpc_errors", "test_fail")).To(Equal(float64(1)))
+		Expect(metricValue("ipld_eth_server_rpc_errors", "unknown")).To(Equal(float64(1)))
+	})
+}
+
+var _ = Describe("RPC metrics", func() {
+	var (
+		defaultRegisterer prometheus.Registerer
+		defaultGatherer   prometheus.Gatherer
+	)
+
+	BeforeEach(func() {
+		// each test registers the metrics anew with a registry of its own
+		defaultRegisterer, defaultGatherer = prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+		registry := prometheus.NewRegistry()
+		prometheus.DefaultRegisterer = registry
+		prometheus.DefaultGatherer = registry
+		prom.Init()
+	})
+
+	AfterEach(func() {
+		prometheus.DefaultRegisterer, prometheus.DefaultGatherer = defaultRegisterer, defaultGatherer
+	})
+
+	Describe("over IPC", func() {
+		metricsSpecs(true, func(server *rpc.Server) (*rpc.Client, func()) {
+			serverConn, clientConn := net.Pipe()
+			served := make(chan struct{})
+			go func() {
+				prom.IPCMiddleware(server, serverConn)
+				close(served)
+			}()
+			client, err := rpc.DialIO(context.Background(), clientConn, clientConn)
+			Expect(err).ToNot(HaveOccurred())
+			return client, func() {
+				// the client does not close the connection it was dialed over
+				clientConn.Close()
+				client.Close()
+				<-served
+			}
+		})
+	})
+
+	Describe("over HTTP", func() {
+		// the HTTP middleware only accepts single calls
+		metricsSpecs(false, func(server *rpc.Server) (*rpc.Client, func()) {
+			httpServer := httptest.NewServer(prom.HTTPMiddleware(server))
+			client, err := rpc.Dial(httpServer.URL)
+			Expect(err).ToNot(HaveOccurred())
+			return client, func() {
+				client.Close()
+				httpServer.Close()
+			}
+		})
+	})
+
+	Describe("over websocket", func() {
+		metricsSpecs(true, func(server *rpc.Server) (*rpc.Client, func()) {
+			var served sync.WaitGroup
+			handler := prom.WSMiddleware(server.WebsocketHandler([]string{"*"}))
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served.Add(1)
+				defer served.Done()
+				handler.ServeHTTP(w, r)
+			}))
+			client, err := rpc.Dial("ws" + strings.TrimPrefix(httpServer.URL, "http"))
+			Expect(err).ToNot(HaveOccurred())
+			return client, func() {
+				// the server does not wait for the hijacked connection to close
+				client.Close()
+				served.Wait()
+				httpServer.Close()
+			}
+		})
+	})
+})
@@ -0,0 +1,155 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package prom
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// wsHijacker hands the websocket handler a connection which observes the JSON-RPC messages served over it
+type wsHijacker struct {
+	http.ResponseWriter
+	tracker *rpcTracker
+}
+
+func (w *wsHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not implement http.Hijacker")
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	mc := &meteredConn{
+		Conn:    conn,
+		read:    &wsFrameScanner{out: w.tracker.requestScanner()},
+		written: &wsFrameScanner{out: w.tracker.responseScanner(), handshake: true},
+	}
+	if rw.Reader.Buffered() > 0 {
+		// the upgrader refuses connections with data buffered ahead of the handshake
+		return mc, rw, nil
+	}
+	return mc, bufio.NewReadWriter(bufio.NewReader(mc), bufio.NewWriter(mc)), nil
+}
+
+var handshakeEnd = []byte("\r\n\r\n")
+
+// wsFrameScanner scans a stream of websocket frames as it is written to it, writing the unmasked payloads of the
+// data frames on; the server's handshake response precedes its frames, and is skipped
+type wsFrameScanner struct {
+	out io.Writer
+
+	handshake bool // Whether the handshake response is being skipped
+	matched   int  // The length of the handshake terminator matched so far
+
+	header    []byte // The header of the next frame read so far
+	remaining uint64 // The length of the current frame's payload left to read
+	control   bool
+	masked    bool
+	mask      [4]byte
+	maskPos   int
+	buf       []byte
+}
+
+func (s *wsFrameScanner) Write(p []byte) (int, error) {
+	n := len(p)
+	for s.handshake && len(p) > 0 {
+		if p[0] == handshakeEnd[s.matched] {
+			s.matched++
+		} else if p[0] == handshakeEnd[0] {
+			s.matched = 1
+		} else {
+			s.matched = 0
+		}
+		p = p[1:]
+		s.handshake = s.matched < len(handshakeEnd)
+	}
+
+	for len(p) > 0 {
+		if s.remaining == 0 {
+			s.header = append(s.header, p[0])
+			p = p[1:]
+			s.parseHeader()
+			continue
+		}
+		chunk := p
+		if uint64(len(chunk)) > s.remaining {
+			chunk = chunk[:s.remaining]
+		}
+		p = p[len(chunk):]
+		s.remaining -= uint64(len(chunk))
+		if s.control {
+			continue
+		}
+		if s.masked {
+			s.buf = append(s.buf[:0], chunk...)
+			for i := range s.buf {
+				s.buf[i] ^= s.mask[s.maskPos%4]
+				s.maskPos++
+			}
+			chunk = s.buf
+		}
+		s.out.Write(chunk)
+	}
+	return n, nil
+}
+
+// parseHeader starts the frame once its header has been read in full
+func (s *wsFrameScanner) parseHeader() {
+	if len(s.header) < 2 {
+		return
+	}
+	size := 2
+	length := uint64(s.header[1] & 0x7f)
+	switch length {
+	case 126:
+		size += 2
+	case 127:
+		size += 8
+	}
+	masked := s.header[1]&0x80 != 0
+	if masked {
+		size += 4
+	}
+	if len(s.header) < size {
+		return
+	}
+
+	switch length {
+	case 126:
+		length = uint64(s.header[2])<<8 | uint64(s.header[3])
+	case 127:
+		length = 0
+		for _, b := range s.header[2:10] {
+			length = length<<8 | uint64(b)
+		}
+	}
+	// control frames have the high bit of the opcode set
+	s.control = s.header[0]&0x08 != 0
+	s.masked = masked
+	if masked {
+		copy(s.mask[:], s.header[size-4:size])
+	}
+	s.maskPos = 0
+	s.remaining = length
+	s.header = s.header[:0]
+}
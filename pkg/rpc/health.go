@@ -0,0 +1,98 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+)
+
+// HealthPath is the path the health check is served at alongside the HTTP RPC server
+const HealthPath = "/health"
+
+// IndexHeadRetriever retrieves the head of the index
+type IndexHeadRetriever interface {
+	RetrieveLastBlockNumber() (int64, error)
+}
+
+// NodeHeadRetriever retrieves the head of the proxied node
+type NodeHeadRetriever interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Health is the status reported by the health check
+type Health struct {
+	IndexHead int64  `json:"indexHead"`
+	NodeHead  *int64 `json:"nodeHead,omitempty"`
+	Lag       *int64 `json:"lag,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthHandler reports the head of the index and, if there is a proxied node, how far the index lags behind it
+type healthHandler struct {
+	index  IndexHeadRetriever
+	node   NodeHeadRetriever
+	maxLag int64
+}
+
+// NewHealthHandler creates a health check handler; it is unhealthy when the index lags the node by more than maxLag blocks
+// (0 = unbounded), and the node is not consulted if it is nil
+func NewHealthHandler(index IndexHeadRetriever, node NodeHeadRetriever, maxLag int64) http.Handler {
+	return &healthHandler{index: index, node: node, maxLag: maxLag}
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health, status := h.check(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		log.Errorf("health check response failed: %s", err)
+	}
+}
+
+func (h *healthHandler) check(ctx context.Context) (Health, int) {
+	var health Health
+	indexHead, err := h.index.RetrieveLastBlockNumber()
+	if err != nil {
+		health.Error = err.Error()
+		return health, http.StatusServiceUnavailable
+	}
+	health.IndexHead = indexHead
+	if h.node == nil {
+		return health, http.StatusOK
+	}
+
+	nodeHead, err := h.node.BlockNumber(ctx)
+	if err != nil {
+		health.Error = err.Error()
+		return health, http.StatusServiceUnavailable
+	}
+	lag := int64(nodeHead) - indexHead
+	if lag < 0 {
+		lag = 0
+	}
+	health.NodeHead = new(int64)
+	*health.NodeHead = int64(nodeHead)
+	health.Lag = &lag
+	if h.maxLag > 0 && lag > h.maxLag {
+		return health, http.StatusServiceUnavailable
+	}
+	return health, http.StatusOK
+}
@@ -0,0 +1,103 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/rpc"
+)
+
+type mockIndex struct {
+	head int64
+	err  error
+}
+
+func (m mockIndex) RetrieveLastBlockNumber() (int64, error) {
+	return m.head, m.err
+}
+
+type mockNode struct {
+	head uint64
+	err  error
+}
+
+func (m mockNode) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, m.err
+}
+
+// getHealth hits the health endpoint served by the handler
+func getHealth(handler http.Handler) (int, map[string]interface{}) {
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + rpc.HealthPath)
+	Expect(err).ToNot(HaveOccurred())
+	defer res.Body.Close()
+	Expect(res.Header.Get("Content-Type")).To(Equal("application/json"))
+
+	var health map[string]interface{}
+	Expect(json.NewDecoder(res.Body).Decode(&health)).To(Succeed())
+	return res.StatusCode, health
+}
+
+var _ = Describe("health check", func() {
+	It("Reports the index head, node head and lag", func() {
+		status, health := getHealth(rpc.NewHealthHandler(mockIndex{head: 95}, mockNode{head: 100}, 10))
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(health).To(Equal(map[string]interface{}{
+			"indexHead": float64(95),
+			"nodeHead":  float64(100),
+			"lag":       float64(5),
+		}))
+	})
+
+	It("Is unavailable when the lag exceeds the threshold", func() {
+		status, health := getHealth(rpc.NewHealthHandler(mockIndex{head: 89}, mockNode{head: 100}, 10))
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(health["lag"]).To(Equal(float64(11)))
+	})
+
+	It("Does not bound the lag without a threshold", func() {
+		status, health := getHealth(rpc.NewHealthHandler(mockIndex{head: 1}, mockNode{head: 100}, 0))
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(health["lag"]).To(Equal(float64(99)))
+	})
+
+	It("Reports only the index head without a node", func() {
+		status, health := getHealth(rpc.NewHealthHandler(mockIndex{head: 95}, nil, 10))
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(health).To(Equal(map[string]interface{}{"indexHead": float64(95)}))
+	})
+
+	It("Is unavailable when a head cannot be retrieved", func() {
+		status, health := getHealth(rpc.NewHealthHandler(mockIndex{err: errors.New("no rows")}, mockNode{head: 100}, 10))
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(health["error"]).To(Equal("no rows"))
+
+		status, health = getHealth(rpc.NewHealthHandler(mockIndex{head: 95}, mockNode{err: errors.New("node down")}, 10))
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(health["error"]).To(Equal("node down"))
+	})
+})
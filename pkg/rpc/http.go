@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
 	"github.com/ethereum/go-ethereum/cmd/utils"
@@ -28,14 +29,21 @@ import (
 )
 
 // StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules.
-func StartHTTPEndpoint(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) (*rpc.Server, error) {
+// The health check, if provided, is served at HealthPath alongside it.
+func StartHTTPEndpoint(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, health http.Handler) (*rpc.Server, error) {
 
 	srv := rpc.NewServer()
 	err := node.RegisterApis(apis, modules, srv)
 	if err != nil {
 		utils.Fatalf("Could not register HTTP API: %w", err)
 	}
-	handler := prom.HTTPMiddleware(node.NewHTTPHandlerStack(srv, cors, vhosts, nil))
+	var handler http.Handler = prom.HTTPMiddleware(node.NewHTTPHandlerStack(srv, cors, vhosts, nil))
+	if health != nil {
+		mux := http.NewServeMux()
+		mux.Handle(HealthPath, health)
+		mux.Handle("/", handler)
+		handler = mux
+	}
 
 	// start http server
 	_, addr, err := node.StartHTTPEndpoint(endpoint, rpc.DefaultHTTPTimeouts, handler)
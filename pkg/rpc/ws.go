@@ -17,41 +17,37 @@
 package rpc
 
 import (
-	"net"
+	"fmt"
 	"net/http"
 
-	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/prom"
 )
 
-// StartWSEndpoint starts a websocket endpoint.
-func StartWSEndpoint(endpoint string, apis []rpc.API, modules []string, wsOrigins []string) (net.Listener, *rpc.Server, error) {
-	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-
-	// Register all the APIs exposed by the services
-	handler := rpc.NewServer()
-	err = node.RegisterApis(apis, modules, handler)
+// StartWSEndpoint starts the websocket RPC endpoint, configured with modules/origins/timeouts
+// in the same way as the HTTP endpoint. It serves eth_subscribe alongside the plain RPC methods.
+// The returned http.Server is closed to stop listening on the endpoint.
+func StartWSEndpoint(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, timeouts rpc.HTTPTimeouts) (*http.Server, *rpc.Server, error) {
+
+	srv := rpc.NewServer()
+	err := node.RegisterApis(apis, modules, srv)
 	if err != nil {
-		utils.Fatalf("Could not register WS API: %w", err)
+		return nil, nil, fmt.Errorf("could not register WS API: %w", err)
 	}
+	handler := prom.WSMiddleware(node.NewWSHandlerStack(srv.WebsocketHandler(wsOrigins), nil))
 
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	// start ws server
+	httpSrv, addr, err := node.StartHTTPEndpoint(endpoint, timeouts, handler)
+	if err != nil {
 		return nil, nil, err
 	}
+	extapiURL := fmt.Sprintf("ws://%v/", addr)
+	log.Infof("WS endpoint opened %s", extapiURL)
 
-	wsServer := NewWSServer(wsOrigins, handler)
-	wsServer.Handler = prom.WSMiddleware(wsServer.Handler)
-	go wsServer.Serve(listener)
-
-	return listener, handler, err
-
+	return httpSrv, srv, nil
 }
 
 // NewWSServer creates a new websocket RPC server around an API provider.
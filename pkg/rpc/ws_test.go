@@ -0,0 +1,88 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpc_test
+
+import (
+	"context"
+	"net"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	srpc "github.com/cerc-io/ipld-eth-server/v4/pkg/rpc"
+)
+
+const wsEndpoint = "127.0.0.1:8095"
+
+type mockBlockNumberAPI struct {
+	head uint64
+}
+
+func (api *mockBlockNumberAPI) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(api.head)
+}
+
+var _ = Describe("WS endpoint", func() {
+	It("Serves RPC calls over a websocket connection", func() {
+		httpSrv, srv, err := srpc.StartWSEndpoint(wsEndpoint, []rpc.API{{
+			Namespace: "eth",
+			Service:   &mockBlockNumberAPI{head: 42},
+		}}, []string{"eth"}, []string{"*"}, rpc.HTTPTimeouts{})
+		Expect(err).ToNot(HaveOccurred())
+		defer srv.Stop()
+		defer httpSrv.Close()
+
+		client, err := rpc.DialWebsocket(context.Background(), "ws://"+wsEndpoint, "")
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		var head hexutil.Uint64
+		err = client.CallContext(context.Background(), &head, "eth_blockNumber")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(head).To(Equal(hexutil.Uint64(42)))
+	})
+
+	It("Frees the endpoint once closed", func() {
+		httpSrv, srv, err := srpc.StartWSEndpoint(wsEndpoint, []rpc.API{{
+			Namespace: "eth",
+			Service:   &mockBlockNumberAPI{},
+		}}, []string{"eth"}, []string{"*"}, rpc.HTTPTimeouts{})
+		Expect(err).ToNot(HaveOccurred())
+		defer srv.Stop()
+
+		Expect(httpSrv.Close()).To(Succeed())
+		// the listener is served, and so closed, from its own goroutine
+		Eventually(func() error {
+			listener, err := net.Listen("tcp", wsEndpoint)
+			if err != nil {
+				return err
+			}
+			return listener.Close()
+		}).Should(Succeed())
+	})
+
+	It("Returns the error of an API that cannot be registered", func() {
+		_, _, err := srpc.StartWSEndpoint(wsEndpoint, []rpc.API{{
+			Namespace: "eth",
+			Service:   struct{}{},
+		}}, []string{"eth"}, []string{"*"}, rpc.HTTPTimeouts{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("could not register WS API"))
+	})
+})
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/statediff/types"
 
@@ -60,7 +62,7 @@ func (api *PublicServerAPI) Stream(ctx context.Context, params eth.SubscriptionS
 	go func() {
 		// subscribe to events from the SyncPublishScreenAndServe service
 		payloadChannel := make(chan SubscriptionPayload, PayloadChanBufferSize)
-		quitChan := make(chan bool, 1)
+		quitChan := make(chan error, 1)
 		go api.w.Subscribe(rpcSub.ID, payloadChannel, quitChan, params)
 
 		// loop and await payloads and relay them to the subscriber using notifier
@@ -75,8 +77,106 @@ func (api *PublicServerAPI) Stream(ctx context.Context, params eth.SubscriptionS
 			case <-rpcSub.Err():
 				api.w.Unsubscribe(rpcSub.ID)
 				return
-			case <-quitChan:
+			case err := <-quitChan:
 				// don't need to unsubscribe from the watcher, the service does so before sending the quit signal this way
+				// the error the subscription was closed for is relayed to the subscriber before returning
+				if err != nil {
+					if err := notifier.Notify(rpcSub.ID, SubscriptionPayload{Err: err.Error(), Flag: EmptyFlag}); err != nil {
+						log.Error("Failed to send watcher error packet", "err", err)
+					}
+				}
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// PublicEthSubscriptionAPI serves the standard eth_subscribe subscriptions from the payloads served by the watcher
+// It is registered under the eth namespace alongside the eth.PublicEthAPI
+type PublicEthSubscriptionAPI struct {
+	w Server
+}
+
+// NewPublicEthSubscriptionAPI creates a new PublicEthSubscriptionAPI with the provided underlying Server process
+func NewPublicEthSubscriptionAPI(w Server) *PublicEthSubscriptionAPI {
+	return &PublicEthSubscriptionAPI{
+		w: w,
+	}
+}
+
+// NewHeads sends the header of each payload as it is served, in the standard RPC header format
+func (api *PublicEthSubscriptionAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	// ensure that the RPC connection supports subscriptions
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	// create subscription and start waiting for headers
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headerChannel := make(chan *ethtypes.Header, PayloadChanBufferSize)
+		quitChan := make(chan error, 1)
+		api.w.SubscribeNewHeads(rpcSub.ID, headerChannel, quitChan)
+
+		// loop and await headers and relay them to the subscriber using notifier
+		for {
+			select {
+			case header := <-headerChannel:
+				if err := notifier.Notify(rpcSub.ID, eth.RPCMarshalHeader(header)); err != nil {
+					log.Error("Failed to send newHeads header", "err", err)
+					api.w.Unsubscribe(rpcSub.ID)
+					return
+				}
+			case <-rpcSub.Err():
+				api.w.Unsubscribe(rpcSub.ID)
+				return
+			case <-quitChan:
+				// the service removes the subscription before sending the quit signal
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// Logs sends the logs matching the filter criteria as they are served, in the standard RPC log format
+// An empty address list matches logs from any address, and an empty topic position matches any topic
+func (api *PublicEthSubscriptionAPI) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc.Subscription, error) {
+	// ensure that the RPC connection supports subscriptions
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	// create subscription and start waiting for logs
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		logsChannel := make(chan []*ethtypes.Log, PayloadChanBufferSize)
+		quitChan := make(chan error, 1)
+		api.w.SubscribeLogs(rpcSub.ID, eth.NewReceiptFilter(crit), logsChannel, quitChan)
+
+		// loop and await logs and relay them to the subscriber using notifier
+		for {
+			select {
+			case logs := <-logsChannel:
+				for _, l := range logs {
+					if err := notifier.Notify(rpcSub.ID, l); err != nil {
+						log.Error("Failed to send log", "err", err)
+						api.w.Unsubscribe(rpcSub.ID)
+						return
+					}
+				}
+			case <-rpcSub.Err():
+				api.w.Unsubscribe(rpcSub.ID)
+				return
+			case <-quitChan:
+				// the service removes the subscription before sending the quit signal
 				return
 			}
 		}
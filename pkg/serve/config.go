@@ -32,6 +32,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/spf13/viper"
 
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/prom"
 	ethServerShared "github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
 )
@@ -46,14 +47,42 @@ const (
 	SERVER_MAX_OPEN_CONNECTIONS = "SERVER_MAX_OPEN_CONNECTIONS"
 	SERVER_MAX_CONN_LIFETIME    = "SERVER_MAX_CONN_LIFETIME"
 
-	ETH_DEFAULT_SENDER_ADDR    = "ETH_DEFAULT_SENDER_ADDR"
-	ETH_RPC_GAS_CAP            = "ETH_RPC_GAS_CAP"
-	ETH_CHAIN_CONFIG           = "ETH_CHAIN_CONFIG"
-	ETH_SUPPORTS_STATEDIFF     = "ETH_SUPPORTS_STATEDIFF"
-	ETH_STATEDIFF_TIMEOUT      = "ETH_STATEDIFF_TIMEOUT"
-	ETH_FORWARD_ETH_CALLS      = "ETH_FORWARD_ETH_CALLS"
-	ETH_FORWARD_GET_STORAGE_AT = "ETH_FORWARD_GET_STORAGE_AT"
-	ETH_PROXY_ON_ERROR         = "ETH_PROXY_ON_ERROR"
+	SERVER_DISABLE_STATE_SUBSCRIPTIONS   = "SERVER_DISABLE_STATE_SUBSCRIPTIONS"
+	SERVER_DISABLE_STORAGE_SUBSCRIPTIONS = "SERVER_DISABLE_STORAGE_SUBSCRIPTIONS"
+	SERVER_WARM_UP_HEAD                  = "SERVER_WARM_UP_HEAD"
+	SERVER_HEALTH_MAX_LAG                = "SERVER_HEALTH_MAX_LAG"
+	SERVER_DRAIN_TIMEOUT                 = "SERVER_DRAIN_TIMEOUT"
+	SERVER_SLOW_CONSUMER_POLICY          = "SERVER_SLOW_CONSUMER_POLICY"
+	SERVER_SLOW_CONSUMER_TIMEOUT         = "SERVER_SLOW_CONSUMER_TIMEOUT"
+
+	ETH_SERVER_GRAPHQL_CORS              = "ETH_SERVER_GRAPHQL_CORS"
+	ETH_SERVER_GRAPHQL_CORS_MAX_AGE      = "ETH_SERVER_GRAPHQL_CORS_MAX_AGE"
+	ETH_SERVER_GRAPHQL_BLOCKS_LOGS_LIMIT = "ETH_SERVER_GRAPHQL_BLOCKS_LOGS_LIMIT"
+	ETH_SERVER_GRAPHQL_FIELD_TIMEOUT     = "ETH_SERVER_GRAPHQL_FIELD_TIMEOUT"
+	ETH_SERVER_GRAPHQL_LENIENT_INPUT     = "ETH_SERVER_GRAPHQL_LENIENT_INPUT"
+
+	ETH_DEFAULT_SENDER_ADDR          = "ETH_DEFAULT_SENDER_ADDR"
+	ETH_RPC_GAS_CAP                  = "ETH_RPC_GAS_CAP"
+	ETH_CHAIN_CONFIG                 = "ETH_CHAIN_CONFIG"
+	ETH_SUPPORTS_STATEDIFF           = "ETH_SUPPORTS_STATEDIFF"
+	ETH_STATEDIFF_TIMEOUT            = "ETH_STATEDIFF_TIMEOUT"
+	ETH_FORWARD_ETH_CALLS            = "ETH_FORWARD_ETH_CALLS"
+	ETH_FORWARD_GET_STORAGE_AT       = "ETH_FORWARD_GET_STORAGE_AT"
+	ETH_PROXY_ON_ERROR               = "ETH_PROXY_ON_ERROR"
+	ETH_TRACE_MAX_CALL_DEPTH         = "ETH_TRACE_MAX_CALL_DEPTH"
+	ETH_TRACE_MAX_OPCODES            = "ETH_TRACE_MAX_OPCODES"
+	ETH_LOGS_MAX_AGE                 = "ETH_LOGS_MAX_AGE"
+	ETH_LOGS_MAX_TOPICS_PER_POSITION = "ETH_LOGS_MAX_TOPICS_PER_POSITION"
+	ETH_LOGS_MAX_TOPICS              = "ETH_LOGS_MAX_TOPICS"
+	ETH_LOGS_MAX_BLOCK_RANGE         = "ETH_LOGS_MAX_BLOCK_RANGE"
+	ETH_LOGS_MAX_RESULTS             = "ETH_LOGS_MAX_RESULTS"
+	ETH_LOGS_ORDER                   = "ETH_LOGS_ORDER"
+	ETH_TXS_MAX_RESULTS              = "ETH_TXS_MAX_RESULTS"
+	ETH_BLOCKS_MAX_OPEN_RANGE        = "ETH_BLOCKS_MAX_OPEN_RANGE"
+	ETH_BLOCKS_TRUNCATE_OPEN_RANGE   = "ETH_BLOCKS_TRUNCATE_OPEN_RANGE"
+	ETH_RETRIEVER_QUERY_TIMEOUT      = "ETH_RETRIEVER_QUERY_TIMEOUT"
+	ETH_BALANCE_ONLY_ACCOUNT_DECODE  = "ETH_BALANCE_ONLY_ACCOUNT_DECODE"
+	ETH_PROXY_UNCLE_BLOCKS           = "ETH_PROXY_UNCLE_BLOCKS"
 
 	VALIDATOR_ENABLED         = "VALIDATOR_ENABLED"
 	VALIDATOR_EVERY_NTH_BLOCK = "VALIDATOR_EVERY_NTH_BLOCK"
@@ -64,6 +93,11 @@ type Config struct {
 	DB       *sqlx.DB
 	DBConfig postgres.Config
 
+	// Read replicas the DB connection pool is spread across, along with the primary database
+	DBReplicas             []string
+	DBReplicaMode          ethServerShared.ReplicaMode
+	DBReplicaRetryInterval time.Duration
+
 	WSEnabled  bool
 	WSEndpoint string
 
@@ -73,8 +107,34 @@ type Config struct {
 	IPCEnabled  bool
 	IPCEndpoint string
 
+	// Reject subscriptions requesting state or storage data
+	DisableStateSubscriptions   bool
+	DisableStorageSubscriptions bool
+
+	// Prefetch the head header and receipts on start
+	WarmUpHead bool
+
+	// Max number of blocks the index can lag behind the proxied node before the health check fails (0 = unbounded)
+	HealthMaxLag int64
+
+	// Max time to wait on shutdown for in-flight subscription backfills to finish (0 = close them immediately)
+	DrainTimeout time.Duration
+
+	// How subscriptions with a full channel are handled, and how long a send blocks under the block policy
+	SlowConsumerPolicy  SlowConsumerPolicy
+	SlowConsumerTimeout time.Duration
+
 	EthGraphqlEnabled  bool
 	EthGraphqlEndpoint string
+	// Allowed CORS origins for the eth graphql server and how many seconds browsers may cache preflight results for
+	EthGraphqlCORS       []string
+	EthGraphqlCORSMaxAge int
+	// Max number of blocks in a graphql `blocks` list multiplied by the logs selections on each block
+	EthGraphqlBlocksLogsLimit int64
+	// Max duration of each graphql resolver field, including the fields selected beneath it (0 = unbounded)
+	EthGraphqlFieldTimeout time.Duration
+	// Whether the unknown fields of graphql input objects are ignored rather than failing the query
+	EthGraphqlLenientInput bool
 
 	IpldGraphqlEnabled          bool
 	IpldGraphqlEndpoint         string
@@ -92,8 +152,43 @@ type Config struct {
 	ForwardEthCalls     bool
 	ForwardGetStorageAt bool
 	ProxyOnError        bool
+	LogsMaxAge          uint64
 	NodeNetworkID       string
 
+	// Limits on the topics of log filters
+	LogsMaxTopicsPerPosition int
+	LogsMaxTopics            int
+
+	// Limit on the blocks spanned by the block range of a graphql getLogs or other range query
+	LogsMaxBlockRange int64
+
+	// Limit on the logs served by a single query, which graphql getLogsPage truncates to, and on the logs retrieved
+	// by a single retriever log query
+	LogsMaxResults int
+
+	// Order in which logs spanning multiple blocks are served
+	LogsOrder eth.LogsOrder
+
+	// Limit on the transactions served by a graphql transactionsByAddress query
+	TxsMaxResults int
+
+	// Limit on the blocks of a graphql blocks range without an end, and whether to truncate rather than reject it
+	BlocksMaxOpenRange      int64
+	BlocksTruncateOpenRange bool
+
+	// Maximum duration of a single retriever query (0 = no timeout)
+	RetrieverQueryTimeout time.Duration
+
+	// Whether eth_getBalance decodes only the balance field of the account
+	BalanceOnlyAccountDecode bool
+
+	// Whether the full blocks of uncles are served from the proxy node
+	ProxyUncleBlocks bool
+
+	// Guards for debug_traceCall
+	TraceMaxCallDepth int
+	TraceMaxOpcodes   uint64
+
 	// Cache configuration.
 	GroupCache *ethServerShared.GroupCacheConfig
 
@@ -115,8 +210,37 @@ func NewConfig() (*Config, error) {
 	viper.BindEnv("ethereum.forwardEthCalls", ETH_FORWARD_ETH_CALLS)
 	viper.BindEnv("ethereum.forwardGetStorageAt", ETH_FORWARD_GET_STORAGE_AT)
 	viper.BindEnv("ethereum.proxyOnError", ETH_PROXY_ON_ERROR)
+	viper.BindEnv("ethereum.traceMaxCallDepth", ETH_TRACE_MAX_CALL_DEPTH)
+	viper.BindEnv("ethereum.traceMaxOpcodes", ETH_TRACE_MAX_OPCODES)
+	viper.BindEnv("ethereum.logsMaxAge", ETH_LOGS_MAX_AGE)
+	viper.BindEnv("ethereum.logsMaxTopicsPerPosition", ETH_LOGS_MAX_TOPICS_PER_POSITION)
+	viper.BindEnv("ethereum.logsMaxTopics", ETH_LOGS_MAX_TOPICS)
+	viper.BindEnv("ethereum.logsMaxBlockRange", ETH_LOGS_MAX_BLOCK_RANGE)
+	viper.BindEnv("ethereum.logsMaxResults", ETH_LOGS_MAX_RESULTS)
+	viper.BindEnv("ethereum.logsOrder", ETH_LOGS_ORDER)
+	viper.BindEnv("ethereum.txsMaxResults", ETH_TXS_MAX_RESULTS)
+	viper.BindEnv("ethereum.blocksMaxOpenRange", ETH_BLOCKS_MAX_OPEN_RANGE)
+	viper.BindEnv("ethereum.blocksTruncateOpenRange", ETH_BLOCKS_TRUNCATE_OPEN_RANGE)
+	viper.BindEnv("ethereum.retrieverQueryTimeout", ETH_RETRIEVER_QUERY_TIMEOUT)
+	viper.BindEnv("ethereum.balanceOnlyAccountDecode", ETH_BALANCE_ONLY_ACCOUNT_DECODE)
+	viper.BindEnv("ethereum.proxyUncleBlocks", ETH_PROXY_UNCLE_BLOCKS)
+	viper.BindEnv("server.disableStateSubscriptions", SERVER_DISABLE_STATE_SUBSCRIPTIONS)
+	viper.BindEnv("server.disableStorageSubscriptions", SERVER_DISABLE_STORAGE_SUBSCRIPTIONS)
+	viper.BindEnv("server.warmUpHead", SERVER_WARM_UP_HEAD)
+	viper.BindEnv("server.healthMaxLag", SERVER_HEALTH_MAX_LAG)
+	viper.BindEnv("server.drainTimeout", SERVER_DRAIN_TIMEOUT)
+	viper.BindEnv("server.slowConsumerPolicy", SERVER_SLOW_CONSUMER_POLICY)
+	viper.BindEnv("server.slowConsumerTimeout", SERVER_SLOW_CONSUMER_TIMEOUT)
+	viper.BindEnv("eth.server.graphqlCors", ETH_SERVER_GRAPHQL_CORS)
+	viper.BindEnv("eth.server.graphqlCorsMaxAge", ETH_SERVER_GRAPHQL_CORS_MAX_AGE)
+	viper.BindEnv("eth.server.graphqlBlocksLogsLimit", ETH_SERVER_GRAPHQL_BLOCKS_LOGS_LIMIT)
+	viper.BindEnv("eth.server.graphqlFieldTimeout", ETH_SERVER_GRAPHQL_FIELD_TIMEOUT)
+	viper.BindEnv("eth.server.graphqlLenientInput", ETH_SERVER_GRAPHQL_LENIENT_INPUT)
 
 	c.dbInit()
+	if err := c.dbReplicasInit(); err != nil {
+		return nil, err
+	}
 	ethHTTP := viper.GetString("ethereum.httpPath")
 	ethHTTPEndpoint := fmt.Sprintf("http://%s", ethHTTP)
 	nodeInfo, cli, err := getEthNodeAndClient(ethHTTPEndpoint)
@@ -129,6 +253,33 @@ func NewConfig() (*Config, error) {
 	c.ForwardEthCalls = viper.GetBool("ethereum.forwardEthCalls")
 	c.ForwardGetStorageAt = viper.GetBool("ethereum.forwardGetStorageAt")
 	c.ProxyOnError = viper.GetBool("ethereum.proxyOnError")
+	c.TraceMaxCallDepth = viper.GetInt("ethereum.traceMaxCallDepth")
+	c.TraceMaxOpcodes = viper.GetUint64("ethereum.traceMaxOpcodes")
+	c.LogsMaxAge = viper.GetUint64("ethereum.logsMaxAge")
+	c.LogsMaxTopicsPerPosition = viper.GetInt("ethereum.logsMaxTopicsPerPosition")
+	c.LogsMaxTopics = viper.GetInt("ethereum.logsMaxTopics")
+	if viper.IsSet("ethereum.logsMaxBlockRange") {
+		c.LogsMaxBlockRange = viper.GetInt64("ethereum.logsMaxBlockRange")
+	} else {
+		c.LogsMaxBlockRange = ethServerShared.DefaultLogsMaxBlockRange
+	}
+	c.LogsMaxResults = viper.GetInt("ethereum.logsMaxResults")
+	if c.LogsOrder, err = eth.ParseLogsOrder(viper.GetString("ethereum.logsOrder")); err != nil {
+		return nil, err
+	}
+	c.TxsMaxResults = viper.GetInt("ethereum.txsMaxResults")
+	c.BlocksMaxOpenRange = viper.GetInt64("ethereum.blocksMaxOpenRange")
+	c.BlocksTruncateOpenRange = viper.GetBool("ethereum.blocksTruncateOpenRange")
+	c.BalanceOnlyAccountDecode = viper.GetBool("ethereum.balanceOnlyAccountDecode")
+	c.ProxyUncleBlocks = viper.GetBool("ethereum.proxyUncleBlocks")
+	if queryTimeout := viper.GetString("ethereum.retrieverQueryTimeout"); queryTimeout != "" {
+		if c.RetrieverQueryTimeout, err = time.ParseDuration(queryTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if c.RetrieverQueryTimeout < 0 {
+		return nil, errors.New("ethereum.retrieverQueryTimeout < 0")
+	}
 	c.EthHttpEndpoint = ethHTTPEndpoint
 
 	// websocket server
@@ -157,6 +308,30 @@ func NewConfig() (*Config, error) {
 	}
 	c.IPCEnabled = ipcEnabled
 
+	c.DisableStateSubscriptions = viper.GetBool("server.disableStateSubscriptions")
+	c.DisableStorageSubscriptions = viper.GetBool("server.disableStorageSubscriptions")
+	c.WarmUpHead = viper.GetBool("server.warmUpHead")
+	c.HealthMaxLag = viper.GetInt64("server.healthMaxLag")
+	if drainTimeout := viper.GetString("server.drainTimeout"); drainTimeout != "" {
+		if c.DrainTimeout, err = time.ParseDuration(drainTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if c.DrainTimeout < 0 {
+		return nil, errors.New("server.drainTimeout < 0")
+	}
+	if c.SlowConsumerPolicy, err = ParseSlowConsumerPolicy(viper.GetString("server.slowConsumerPolicy")); err != nil {
+		return nil, err
+	}
+	if slowConsumerTimeout := viper.GetString("server.slowConsumerTimeout"); slowConsumerTimeout != "" {
+		if c.SlowConsumerTimeout, err = time.ParseDuration(slowConsumerTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if c.SlowConsumerTimeout < 0 {
+		return nil, errors.New("server.slowConsumerTimeout < 0")
+	}
+
 	// http server
 	httpEnabled := viper.GetBool("eth.server.http")
 	if httpEnabled {
@@ -176,6 +351,18 @@ func NewConfig() (*Config, error) {
 			ethGraphqlPath = "127.0.0.1:8082"
 		}
 		c.EthGraphqlEndpoint = ethGraphqlPath
+		c.EthGraphqlCORS = viper.GetStringSlice("eth.server.graphqlCors")
+		c.EthGraphqlCORSMaxAge = viper.GetInt("eth.server.graphqlCorsMaxAge")
+		c.EthGraphqlBlocksLogsLimit = viper.GetInt64("eth.server.graphqlBlocksLogsLimit")
+		if fieldTimeout := viper.GetString("eth.server.graphqlFieldTimeout"); fieldTimeout != "" {
+			if c.EthGraphqlFieldTimeout, err = time.ParseDuration(fieldTimeout); err != nil {
+				return nil, err
+			}
+		}
+		if c.EthGraphqlFieldTimeout < 0 {
+			return nil, errors.New("eth.server.graphqlFieldTimeout < 0")
+		}
+		c.EthGraphqlLenientInput = viper.GetBool("eth.server.graphqlLenientInput")
 	}
 	c.EthGraphqlEnabled = ethGraphqlEnabled
 
@@ -208,7 +395,13 @@ func NewConfig() (*Config, error) {
 	c.IpldGraphqlEnabled = ipldGraphqlEnabled
 
 	overrideDBConnConfig(&c.DBConfig)
-	serveDB, err := ethServerShared.NewDB(c.DBConfig.DbConnectionString(), c.DBConfig)
+	var serveDB *sqlx.DB
+	if len(c.DBReplicas) > 0 {
+		connectStrings := append([]string{c.DBConfig.DbConnectionString()}, c.DBReplicas...)
+		serveDB, err = ethServerShared.NewReplicaDB(connectStrings, c.DBConfig, c.DBReplicaMode, c.DBReplicaRetryInterval)
+	} else {
+		serveDB, err = ethServerShared.NewDB(c.DBConfig.DbConnectionString(), c.DBConfig)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -283,6 +476,26 @@ func (c *Config) dbInit() {
 	c.DBConfig.MaxConnLifetime = time.Duration(viper.GetInt("database.maxLifetime"))
 }
 
+func (c *Config) dbReplicasInit() error {
+	viper.BindEnv("database.replicas", DATABASE_REPLICAS)
+	viper.BindEnv("database.replicaMode", DATABASE_REPLICA_MODE)
+	viper.BindEnv("database.replicaRetryInterval", DATABASE_REPLICA_RETRY_INTERVAL)
+
+	c.DBReplicas = viper.GetStringSlice("database.replicas")
+	mode, err := ethServerShared.ParseReplicaMode(viper.GetString("database.replicaMode"))
+	if err != nil {
+		return err
+	}
+	c.DBReplicaMode = mode
+	c.DBReplicaRetryInterval = ethServerShared.DefaultReplicaRetryInterval
+	if retryInterval := viper.GetString("database.replicaRetryInterval"); retryInterval != "" {
+		if c.DBReplicaRetryInterval, err = time.ParseDuration(retryInterval); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Config) loadGroupCacheConfig() {
 	viper.BindEnv("groupcache.pool.enabled", ethServerShared.GcachePoolEnabled)
 	viper.BindEnv("groupcache.pool.httpEndpoint", ethServerShared.GcachePoolHttpPath)
@@ -290,6 +503,8 @@ func (c *Config) loadGroupCacheConfig() {
 	viper.BindEnv("groupcache.statedb.cacheSizeInMB", ethServerShared.GcacheStatedbCacheSize)
 	viper.BindEnv("groupcache.statedb.cacheExpiryInMins", ethServerShared.GcacheStatedbCacheExpiry)
 	viper.BindEnv("groupcache.statedb.logStatsIntervalInSecs", ethServerShared.GcacheStatedbLogStatsInterval)
+	viper.BindEnv("groupcache.headerCacheSize", ethServerShared.GcacheHeaderCacheSize)
+	viper.BindEnv("groupcache.headerCacheFinalizedDepth", ethServerShared.GcacheHeaderCacheDepth)
 
 	gcc := ethServerShared.GroupCacheConfig{}
 	gcc.Pool.Enabled = viper.GetBool("groupcache.pool.enabled")
@@ -302,6 +517,8 @@ func (c *Config) loadGroupCacheConfig() {
 	gcc.StateDB.CacheSizeInMB = viper.GetInt("groupcache.statedb.cacheSizeInMB")
 	gcc.StateDB.CacheExpiryInMins = viper.GetInt("groupcache.statedb.cacheExpiryInMins")
 	gcc.StateDB.LogStatsIntervalInSecs = viper.GetInt("groupcache.statedb.logStatsIntervalInSecs")
+	gcc.HeaderCacheSize = viper.GetInt("groupcache.headerCacheSize")
+	gcc.HeaderCacheFinalizedDepth = viper.GetUint64("groupcache.headerCacheFinalizedDepth")
 
 	c.GroupCache = &gcc
 }
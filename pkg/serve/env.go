@@ -26,6 +26,10 @@ const (
 	DATABASE_MAX_IDLE_CONNECTIONS = "DATABASE_MAX_IDLE_CONNECTIONS"
 	DATABASE_MAX_OPEN_CONNECTIONS = "DATABASE_MAX_OPEN_CONNECTIONS"
 	DATABASE_MAX_CONN_LIFETIME    = "DATABASE_MAX_CONN_LIFETIME"
+
+	DATABASE_REPLICAS               = "DATABASE_REPLICAS"
+	DATABASE_REPLICA_MODE           = "DATABASE_REPLICA_MODE"
+	DATABASE_REPLICA_RETRY_INTERVAL = "DATABASE_REPLICA_RETRY_INTERVAL"
 )
 
 // GetEthNodeAndClient returns eth node info and client from path url
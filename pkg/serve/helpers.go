@@ -16,7 +16,11 @@
 
 package serve
 
-import "github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+import (
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+)
 
 func sendNonBlockingErr(sub Subscription, err error) {
 	log.Error(err)
@@ -28,10 +32,14 @@ func sendNonBlockingErr(sub Subscription, err error) {
 }
 
 func sendNonBlockingQuit(sub Subscription) {
+	sendNonBlockingQuitSignal(sub.QuitChan, sub.ID, nil)
+}
+
+func sendNonBlockingQuitSignal(quitChan chan<- error, id rpc.ID, err error) {
 	select {
-	case sub.QuitChan <- true:
-		log.Infof("closing subscription %s", sub.ID)
+	case quitChan <- err:
+		log.Infof("closing subscription %s", id)
 	default:
-		log.Infof("unable to close subscription %s; channel has no receiver", sub.ID)
+		log.Infof("unable to close subscription %s; channel has no receiver", id)
 	}
 }
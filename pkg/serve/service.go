@@ -17,29 +17,46 @@
 package serve
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/eth/tracers"
 	ethnode "github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/admin"
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/debug"
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
 	"github.com/cerc-io/ipld-eth-server/v4/pkg/net"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/prom"
 )
 
 const (
 	PayloadChanBufferSize = 2000
+
+	// subscription types, as labelled in the subscription metrics
+	streamSubscriptionType   = "stream"
+	newHeadsSubscriptionType = "newHeads"
+	logsSubscriptionType     = "logs"
+)
+
+var (
+	errStateSubscriptionsDisabled   = errors.New("state subscriptions are disabled on this server; subscribe with the state filter turned off")
+	errStorageSubscriptionsDisabled = errors.New("storage subscriptions are disabled on this server; subscribe with the storage filter turned off")
+	errServerStopping               = errors.New("eth ipld server is shutting down")
+	errSlowConsumer                 = errors.New("subscription closed as a slow consumer; its channel was full")
 )
 
 // Server is the top level interface for streaming, converting to IPLDs, publishing,
@@ -53,7 +70,11 @@ type Server interface {
 	// Pub-Sub handling event loop
 	Serve(wg *sync.WaitGroup, screenAndServePayload <-chan eth.ConvertedPayload)
 	// Method to subscribe to the service
-	Subscribe(id rpc.ID, sub chan<- SubscriptionPayload, quitChan chan<- bool, params eth.SubscriptionSettings)
+	Subscribe(id rpc.ID, sub chan<- SubscriptionPayload, quitChan chan<- error, params eth.SubscriptionSettings)
+	// Method to subscribe to the headers of the payloads served by the service
+	SubscribeNewHeads(id rpc.ID, sub chan<- *types.Header, quitChan chan<- error)
+	// Method to subscribe to the logs matching the filter in the payloads served by the service
+	SubscribeLogs(id rpc.ID, filter eth.ReceiptFilter, sub chan<- []*types.Log, quitChan chan<- error)
 	// Method to unsubscribe from the service
 	Unsubscribe(id rpc.ID)
 	// Backend exposes the server's backend
@@ -76,6 +97,10 @@ type Service struct {
 	Subscriptions map[common.Hash]map[rpc.ID]Subscription
 	// A mapping of subscription params hash to the corresponding subscription params
 	SubscriptionTypes map[common.Hash]eth.SubscriptionSettings
+	// A mapping of rpc.IDs to their newHeads subscriptions
+	HeadSubscriptions map[rpc.ID]HeadSubscription
+	// A mapping of rpc.IDs to their logs subscriptions
+	LogsSubscriptions map[rpc.ID]LogsSubscription
 	// Underlying db
 	db *sqlx.DB
 	// wg for syncing serve processes
@@ -94,34 +119,86 @@ type Service struct {
 	forwardGetStorageAt bool
 	// whether to forward all calls to proxy node if they throw an error locally
 	proxyOnError bool
+	// max number of blocks behind head the fromBlock of an eth_getLogs query can be
+	logsMaxAge uint64
 	// eth node network id
 	nodeNetworkId string
+	// guards applied to debug_traceCall
+	traceGuard debug.GuardConfig
+	// whether to reject subscriptions requesting state data
+	disableStateSubscriptions bool
+	// whether to reject subscriptions requesting storage data
+	disableStorageSubscriptions bool
+	// whether to prefetch the head header and receipts on start
+	warmUpHead bool
+	// max time to wait on shutdown for in-flight backfills to finish before force-closing the subscriptions
+	drainTimeout time.Duration
+	// whether the service is shutting down, after which new subscriptions are rejected
+	stopping bool
+	// number of backfills in flight, accessed atomically
+	backFills int64
+	// how subscriptions with a full channel are handled, and how long a blocked send waits
+	slowConsumerPolicy  SlowConsumerPolicy
+	slowConsumerTimeout time.Duration
 }
 
 // NewServer creates a new Server using an underlying Service struct
 func NewServer(settings *Config) (Server, error) {
 	sap := new(Service)
-	sap.Retriever = eth.NewCIDRetriever(settings.DB)
+	var err error
+	retriever, err := eth.NewCIDRetriever(settings.DB)
+	if err != nil {
+		return nil, err
+	}
+	retriever.QueryTimeout = settings.RetrieverQueryTimeout
+	retriever.LogsMaxResults = settings.LogsMaxResults
+	sap.Retriever = retriever
 	sap.IPLDFetcher = eth.NewIPLDFetcher(settings.DB)
 	sap.Filterer = eth.NewResponseFilterer()
 	sap.db = settings.DB
 	sap.QuitChan = make(chan bool)
 	sap.Subscriptions = make(map[common.Hash]map[rpc.ID]Subscription)
 	sap.SubscriptionTypes = make(map[common.Hash]eth.SubscriptionSettings)
+	sap.HeadSubscriptions = make(map[rpc.ID]HeadSubscription)
+	sap.LogsSubscriptions = make(map[rpc.ID]LogsSubscription)
 	sap.client = settings.Client
 	sap.supportsStateDiffing = settings.SupportStateDiff
 	sap.stateDiffTimeout = settings.StateDiffTimeout
 	sap.forwardEthCalls = settings.ForwardEthCalls
 	sap.forwardGetStorageAt = settings.ForwardGetStorageAt
 	sap.proxyOnError = settings.ProxyOnError
+	sap.logsMaxAge = settings.LogsMaxAge
 	sap.nodeNetworkId = settings.NodeNetworkID
-	var err error
+	sap.disableStateSubscriptions = settings.DisableStateSubscriptions
+	sap.disableStorageSubscriptions = settings.DisableStorageSubscriptions
+	sap.warmUpHead = settings.WarmUpHead
+	sap.drainTimeout = settings.DrainTimeout
+	sap.slowConsumerPolicy = settings.SlowConsumerPolicy
+	sap.slowConsumerTimeout = settings.SlowConsumerTimeout
+	sap.traceGuard = debug.GuardConfig{
+		MaxCallDepth: settings.TraceMaxCallDepth,
+		MaxOpcodes:   settings.TraceMaxOpcodes,
+	}
 	sap.backend, err = eth.NewEthBackend(sap.db, &eth.Config{
+		Client:           settings.Client,
 		ChainConfig:      settings.ChainConfig,
 		VMConfig:         vm.Config{NoBaseFee: true},
 		DefaultSender:    settings.DefaultSender,
 		RPCGasCap:        settings.RPCGasCap,
 		GroupCacheConfig: settings.GroupCache,
+
+		LogsMaxTopicsPerPosition: settings.LogsMaxTopicsPerPosition,
+		LogsMaxTopics:            settings.LogsMaxTopics,
+		LogsMaxBlockRange:        settings.LogsMaxBlockRange,
+		LogsMaxResults:           settings.LogsMaxResults,
+		LogsOrder:                settings.LogsOrder,
+		TxsMaxResults:            settings.TxsMaxResults,
+		BlocksMaxOpenRange:       settings.BlocksMaxOpenRange,
+		BlocksTruncateOpenRange:  settings.BlocksTruncateOpenRange,
+
+		RetrieverQueryTimeout:    settings.RetrieverQueryTimeout,
+		BalanceOnlyAccountDecode: settings.BalanceOnlyAccountDecode,
+		ProxyUncleBlocks:         settings.ProxyUncleBlocks,
 	})
 	return sap, err
 }
@@ -154,22 +231,35 @@ func (sap *Service) APIs() []rpc.API {
 		ForwardGetStorageAt: sap.forwardGetStorageAt,
 		ProxyOnError:        sap.proxyOnError,
 		StateDiffTimeout:    sap.stateDiffTimeout,
+		LogsMaxAge:          sap.logsMaxAge,
 	}
 	ethAPI, err := eth.NewPublicEthAPI(sap.backend, sap.client, conf)
 	if err != nil {
 		log.Fatalf("unable to create public eth api: %v", err)
 	}
 
-	debugTracerAPI := tracers.APIs(&debug.Backend{Backend: *sap.backend})[0]
-
-	return append(apis,
+	apis = append(apis,
 		rpc.API{
 			Namespace: eth.APIName,
 			Version:   eth.APIVersion,
 			Service:   ethAPI,
 			Public:    true,
 		},
-		debugTracerAPI,
+		rpc.API{
+			Namespace: eth.APIName,
+			Version:   eth.APIVersion,
+			Service:   NewPublicEthSubscriptionAPI(sap),
+			Public:    true,
+		},
+	)
+	apis = append(apis, debug.APIs(&debug.Backend{Backend: *sap.backend}, sap.traceGuard)...)
+	return append(apis,
+		rpc.API{
+			Namespace: admin.APIName,
+			Version:   admin.APIVersion,
+			Service:   admin.NewPrivateAdminAPI(sap.backend),
+			Public:    false,
+		},
 	)
 }
 
@@ -177,7 +267,14 @@ func (sap *Service) APIs() []rpc.API {
 // It filters and sends this data to any subscribers to the service
 // This process can also be stood up alone, without an screenAndServePayload attached to a Sync process
 // and it will hang on the WaitGroup indefinitely, allowing the Service to serve historical data requests only
+// If enabled, the head block is warmed up before serving
 func (sap *Service) Serve(wg *sync.WaitGroup, screenAndServePayload <-chan eth.ConvertedPayload) {
+	if sap.warmUpHead {
+		// a failed warm-up only means the first queries are served cold
+		if err := sap.backend.WarmUp(context.Background()); err != nil {
+			log.Warnf("failed to warm up the head block: %v", err)
+		}
+	}
 	sap.serveWg = wg
 	go func() {
 		wg.Add(1)
@@ -196,12 +293,42 @@ func (sap *Service) Serve(wg *sync.WaitGroup, screenAndServePayload <-chan eth.C
 }
 
 // filterAndServe filters the payload according to each subscription type and sends to the subscriptions
+// The subscriptions are only locked while the payload is filtered for them and while slow consumers are closed,
+// not while it is sent, so that waiting on a slow consumer doesn't block subscribing and unsubscribing
 func (sap *Service) filterAndServe(payload eth.ConvertedPayload) {
 	log.Debug("sending eth ipld payload to subscriptions")
-	sap.Lock()
 	sap.serveWg.Add(1)
-	defer sap.Unlock()
 	defer sap.serveWg.Done()
+	sap.Lock()
+	deliveries := sap.filterDeliveries(payload)
+	sap.Unlock()
+
+	slow := sap.deliver(deliveries)
+	if len(slow) == 0 {
+		return
+	}
+	sap.Lock()
+	defer sap.Unlock()
+	for _, d := range slow {
+		// a subscription which unsubscribed while the payload was sent to it is not a slow consumer
+		if sap.subscribed(d.id) {
+			sap.closeSlowConsumer(d.id, d.subType, d.signal)
+		}
+	}
+}
+
+// delivery is the send of a payload to a subscription
+type delivery struct {
+	id      rpc.ID
+	subType string
+	send    func(wait bool) bool // sends the payload, waiting on a full channel if wait is set
+	signal  func()               // signals the subscription to close as a slow consumer
+}
+
+// filterDeliveries filters the payload for each subscription, returning the deliveries of the results
+// filterDeliveries needs to be called with subscription access locked
+func (sap *Service) filterDeliveries(payload eth.ConvertedPayload) []delivery {
+	var deliveries []delivery
 	for ty, subs := range sap.Subscriptions {
 		// Retrieve the subscription parameters for this subscription type
 		subConfig, ok := sap.SubscriptionTypes[ty]
@@ -227,20 +354,140 @@ func (sap *Service) filterAndServe(payload eth.ConvertedPayload) {
 			log.Errorf("eth ipld server rlp encoding error: %v", err)
 			continue
 		}
-		for id, sub := range subs {
-			select {
-			case sub.PayloadChan <- SubscriptionPayload{Data: responseRLP, Err: "", Flag: EmptyFlag, Height: response.BlockNumber.Int64()}:
-				log.Debugf("sending eth ipld server payload to subscription %s", id)
-			default:
-				log.Infof("unable to send eth ipld payload to subscription %s; channel has no receiver", id)
-			}
+		subPayload := SubscriptionPayload{Data: responseRLP, Err: "", Flag: EmptyFlag, Height: response.BlockNumber.Int64()}
+		for _, sub := range subs {
+			deliveries = append(deliveries, sap.payloadDelivery(sub, subPayload))
+		}
+	}
+	header := payload.Block.Header()
+	for _, sub := range sap.HeadSubscriptions {
+		sub := sub
+		deliveries = append(deliveries, delivery{
+			id:      sub.ID,
+			subType: newHeadsSubscriptionType,
+			send: func(wait bool) bool {
+				return sendToSubscription(sap, newHeadsSubscriptionType, sap.slowConsumerPolicy, sub.HeaderChan, header, wait)
+			},
+			signal: func() { sendNonBlockingQuitSignal(sub.QuitChan, sub.ID, errSlowConsumer) },
+		})
+	}
+	for _, sub := range sap.LogsSubscriptions {
+		sub := sub
+		logs := sap.Filterer.FilterLogs(sub.Filter, payload)
+		if len(logs) == 0 {
+			continue
+		}
+		deliveries = append(deliveries, delivery{
+			id:      sub.ID,
+			subType: logsSubscriptionType,
+			send: func(wait bool) bool {
+				return sendToSubscription(sap, logsSubscriptionType, sap.slowConsumerPolicy, sub.LogsChan, logs, wait)
+			},
+			signal: func() { sendNonBlockingQuitSignal(sub.QuitChan, sub.ID, errSlowConsumer) },
+		})
+	}
+	return deliveries
+}
+
+// payloadDelivery returns the delivery of the payload to the subscription under its own slow consumer policy
+func (sap *Service) payloadDelivery(sub Subscription, payload SubscriptionPayload) delivery {
+	return delivery{
+		id:      sub.ID,
+		subType: streamSubscriptionType,
+		send: func(wait bool) bool {
+			return sendToSubscription(sap, streamSubscriptionType, sub.Policy, sub.PayloadChan, payload, wait)
+		},
+		signal: func() { sendNonBlockingQuitSignal(sub.QuitChan, sub.ID, errSlowConsumer) },
+	}
+}
+
+// deliver sends to the subscriptions with room in their channels first, then waits on the full channels concurrently,
+// so that the slow consumers hold the others up by the slow consumer timeout at most; it returns the deliveries
+// whose subscription's channel stayed full
+func (sap *Service) deliver(deliveries []delivery) []delivery {
+	var full []delivery
+	for _, d := range deliveries {
+		if !d.send(false) {
+			full = append(full, d)
+			continue
 		}
+		log.Debugf("sending eth ipld server payload to %s subscription %s", d.subType, d.id)
+	}
+	if len(full) == 0 {
+		return nil
+	}
+
+	sent := make([]bool, len(full))
+	wg := new(sync.WaitGroup)
+	for i, d := range full {
+		wg.Add(1)
+		go func(i int, d delivery) {
+			defer wg.Done()
+			sent[i] = d.send(true)
+		}(i, d)
+	}
+	wg.Wait()
+	var slow []delivery
+	for i, d := range full {
+		if !sent[i] {
+			slow = append(slow, d)
+		}
+	}
+	return slow
+}
+
+// sendToSubscription sends the value on a subscription's channel, returning false if the channel is full; if wait is
+// set a full channel is given the slow consumer timeout to make room under the block policy
+func sendToSubscription[T any](sap *Service, subType string, policy SlowConsumerPolicy, ch chan<- T, value T, wait bool) bool {
+	select {
+	case ch <- value:
+		return true
+	default:
+	}
+	if !wait || policy != BlockSlowConsumers || sap.slowConsumerTimeout <= 0 {
+		return false
+	}
+	prom.SubscriptionBlocked(subType)
+	timer := time.NewTimer(sap.slowConsumerTimeout)
+	defer timer.Stop()
+	select {
+	case ch <- value:
+		return true
+	case <-timer.C:
+		return false
 	}
 }
 
+// subscribed returns whether a subscription with the given id is being served
+// subscribed needs to be called with subscription access locked
+func (sap *Service) subscribed(id rpc.ID) bool {
+	if _, ok := sap.HeadSubscriptions[id]; ok {
+		return true
+	}
+	if _, ok := sap.LogsSubscriptions[id]; ok {
+		return true
+	}
+	for _, subs := range sap.Subscriptions {
+		if _, ok := subs[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// closeSlowConsumer removes the subscription, rather than skipping the payloads it can't keep up with,
+// and signals it to close
+// closeSlowConsumer needs to be called with subscription access locked
+func (sap *Service) closeSlowConsumer(id rpc.ID, subType string, signal func()) {
+	log.Warnf("closing eth ipld server %s subscription %s as a slow consumer", subType, id)
+	prom.SubscriptionDropped(subType)
+	sap.unsubscribe(id)
+	signal()
+}
+
 // Subscribe is used by the API to remotely subscribe to the service loop
 // The params must be rlp serializable and satisfy the SubscriptionSettings() interface
-func (sap *Service) Subscribe(id rpc.ID, sub chan<- SubscriptionPayload, quitChan chan<- bool, params eth.SubscriptionSettings) {
+func (sap *Service) Subscribe(id rpc.ID, sub chan<- SubscriptionPayload, quitChan chan<- error, params eth.SubscriptionSettings) {
 	sap.serveWg.Add(1)
 	defer sap.serveWg.Done()
 	log.Infof("new eth ipld subscription %s", id)
@@ -248,6 +495,34 @@ func (sap *Service) Subscribe(id rpc.ID, sub chan<- SubscriptionPayload, quitCha
 		ID:          id,
 		PayloadChan: sub,
 		QuitChan:    quitChan,
+		Policy:      sap.slowConsumerPolicy,
+	}
+	sap.Lock()
+	stopping := sap.stopping
+	sap.Unlock()
+	if stopping {
+		sendNonBlockingErr(subscription, errServerStopping)
+		sendNonBlockingQuit(subscription)
+		return
+	}
+	if !params.StateFilter.Off && sap.disableStateSubscriptions {
+		sendNonBlockingErr(subscription, errStateSubscriptionsDisabled)
+		sendNonBlockingQuit(subscription)
+		return
+	}
+	if !params.StorageFilter.Off && sap.disableStorageSubscriptions {
+		sendNonBlockingErr(subscription, errStorageSubscriptionsDisabled)
+		sendNonBlockingQuit(subscription)
+		return
+	}
+	if params.SlowConsumerPolicy != "" {
+		policy, err := ParseSlowConsumerPolicy(params.SlowConsumerPolicy)
+		if err != nil {
+			sendNonBlockingErr(subscription, err)
+			sendNonBlockingQuit(subscription)
+			return
+		}
+		subscription.Policy = policy
 	}
 	// Subscription type is defined as the hash of the rlp-serialized subscription settings
 	by, err := rlp.EncodeToBytes(params)
@@ -278,6 +553,31 @@ func (sap *Service) Subscribe(id rpc.ID, sub chan<- SubscriptionPayload, quitCha
 	}
 }
 
+// SubscribeNewHeads is used by the API to subscribe to the headers of the payloads served by the service loop
+func (sap *Service) SubscribeNewHeads(id rpc.ID, sub chan<- *types.Header, quitChan chan<- error) {
+	log.Infof("new eth ipld newHeads subscription %s", id)
+	sap.Lock()
+	sap.HeadSubscriptions[id] = HeadSubscription{
+		ID:         id,
+		HeaderChan: sub,
+		QuitChan:   quitChan,
+	}
+	sap.Unlock()
+}
+
+// SubscribeLogs is used by the API to subscribe to the logs matching the filter in the payloads served by the service loop
+func (sap *Service) SubscribeLogs(id rpc.ID, filter eth.ReceiptFilter, sub chan<- []*types.Log, quitChan chan<- error) {
+	log.Infof("new eth ipld logs subscription %s", id)
+	sap.Lock()
+	sap.LogsSubscriptions[id] = LogsSubscription{
+		ID:       id,
+		Filter:   filter,
+		LogsChan: sub,
+		QuitChan: quitChan,
+	}
+	sap.Unlock()
+}
+
 // sendHistoricalData sends historical data to the requesting subscription
 func (sap *Service) sendHistoricalData(sub Subscription, id rpc.ID, params eth.SubscriptionSettings) error {
 	log.Infof("sending eth ipld historical data to subscription %s", id)
@@ -301,15 +601,16 @@ func (sap *Service) sendHistoricalData(sub Subscription, id rpc.ID, params eth.S
 	}
 	log.Debugf("eth ipld historical data starting block: %d", params.Start.Int64())
 	log.Debugf("eth ipld historical data ending block: %d", endingBlock)
+	sap.serveWg.Add(1)
+	atomic.AddInt64(&sap.backFills, 1)
 	go func() {
-		sap.serveWg.Add(1)
 		defer sap.serveWg.Done()
+		defer atomic.AddInt64(&sap.backFills, -1)
 		for i := startingBlock; i <= endingBlock; i++ {
-			select {
-			case <-sap.QuitChan:
-				log.Infof("ethereum historical data feed to subscription %s closed", id)
-				return
-			default:
+			// on shutdown the backfill ends after the current block, still sending its completion notice
+			if sap.quitting() {
+				log.Infof("ethereum historical data feed to subscription %s closed before block %d", id, i)
+				break
 			}
 			cidWrappers, empty, err := sap.Retriever.Retrieve(params, i)
 			if err != nil {
@@ -330,29 +631,55 @@ func (sap *Service) sendHistoricalData(sub Subscription, id rpc.ID, params eth.S
 					log.Error(err)
 					continue
 				}
-				select {
-				case sub.PayloadChan <- SubscriptionPayload{Data: responseRLP, Err: "", Flag: EmptyFlag, Height: response.BlockNumber.Int64()}:
-					log.Debugf("eth ipld server sending historical data payload to subscription %s", id)
-				default:
-					log.Infof("eth ipld server unable to send backFill payload to subscription %s; channel has no receiver", id)
+				if !sap.sendHistoricalPayload(sub, SubscriptionPayload{Data: responseRLP, Err: "", Flag: EmptyFlag, Height: response.BlockNumber.Int64()}) {
+					return
 				}
+				log.Debugf("eth ipld server sending historical data payload to subscription %s", id)
 			}
 		}
 		// when we are done backfilling send an empty payload signifying so in the msg
-		select {
-		case sub.PayloadChan <- SubscriptionPayload{Data: nil, Err: "", Flag: BackFillCompleteFlag}:
-			log.Debugf("eth ipld server sending backFill completion notice to subscription %s", id)
-		default:
-			log.Infof("eth ipld server unable to send backFill completion notice to subscription %s", id)
+		if !sap.sendHistoricalPayload(sub, SubscriptionPayload{Data: nil, Err: "", Flag: BackFillCompleteFlag}) {
+			return
 		}
+		log.Debugf("eth ipld server sending backFill completion notice to subscription %s", id)
 	}()
 	return nil
 }
 
+// sendHistoricalPayload sends the payload of a backfill to the subscription, closing it as a slow consumer if its
+// channel stays full; the subscription is closed even if it only backfills, and so isn't otherwise being served
+func (sap *Service) sendHistoricalPayload(sub Subscription, payload SubscriptionPayload) bool {
+	d := sap.payloadDelivery(sub, payload)
+	if d.send(true) {
+		return true
+	}
+	sap.Lock()
+	sap.closeSlowConsumer(d.id, d.subType, d.signal)
+	sap.Unlock()
+	return false
+}
+
+// quitting returns whether the service has been signalled to shut down
+func (sap *Service) quitting() bool {
+	select {
+	case <-sap.QuitChan:
+		return true
+	default:
+		return false
+	}
+}
+
 // Unsubscribe is used by the API to remotely unsubscribe to the StateDiffingService loop
 func (sap *Service) Unsubscribe(id rpc.ID) {
 	log.Infof("unsubscribing %s from the eth ipld server", id)
 	sap.Lock()
+	sap.unsubscribe(id)
+	sap.Unlock()
+}
+
+// unsubscribe removes the subscription with the given id
+// unsubscribe needs to be called with subscription access locked
+func (sap *Service) unsubscribe(id rpc.ID) {
 	for ty := range sap.Subscriptions {
 		delete(sap.Subscriptions[ty], id)
 		if len(sap.Subscriptions[ty]) == 0 {
@@ -361,7 +688,8 @@ func (sap *Service) Unsubscribe(id rpc.ID) {
 			delete(sap.SubscriptionTypes, ty)
 		}
 	}
-	sap.Unlock()
+	delete(sap.HeadSubscriptions, id)
+	delete(sap.LogsSubscriptions, id)
 }
 
 // Start is used to begin the service
@@ -375,16 +703,52 @@ func (sap *Service) Start() error {
 }
 
 // Stop is used to close down the service
+// New subscriptions are rejected and in-flight backfills are given up to the drain timeout to finish
+// their current block and send their completion notice, before all subscriptions are closed
 // This is mostly just to satisfy the node.Service interface
 func (sap *Service) Stop() error {
-	log.Infof("stopping eth ipld server")
 	sap.Lock()
+	if sap.stopping {
+		sap.Unlock()
+		return nil
+	}
+	log.Infof("stopping eth ipld server")
+	sap.stopping = true
 	close(sap.QuitChan)
+	sap.Unlock()
+
+	inFlight := atomic.LoadInt64(&sap.backFills)
+	sap.drain()
+	forceClosed := atomic.LoadInt64(&sap.backFills)
+	drained := inFlight - forceClosed
+	if drained < 0 {
+		drained = 0
+	}
+	log.Infof("drained %d eth ipld server backfill subscription(s), force-closing %d", drained, forceClosed)
+
+	sap.Lock()
 	sap.close()
 	sap.Unlock()
 	return nil
 }
 
+// drain waits up to the drain timeout for the serve processes, including in-flight backfills, to finish
+func (sap *Service) drain() {
+	if sap.serveWg == nil || sap.drainTimeout <= 0 {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		sap.serveWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(sap.drainTimeout):
+		log.Warnf("eth ipld server processes did not finish within the drain timeout of %s", sap.drainTimeout)
+	}
+}
+
 // Backend exposes the server's backend
 func (sap *Service) Backend() *eth.Backend {
 	return sap.backend
@@ -401,6 +765,14 @@ func (sap *Service) close() {
 		delete(sap.Subscriptions, subType)
 		delete(sap.SubscriptionTypes, subType)
 	}
+	for id, sub := range sap.HeadSubscriptions {
+		sendNonBlockingQuitSignal(sub.QuitChan, id, nil)
+		delete(sap.HeadSubscriptions, id)
+	}
+	for id, sub := range sap.LogsSubscriptions {
+		sendNonBlockingQuitSignal(sub.QuitChan, id, nil)
+		delete(sap.LogsSubscriptions, id)
+	}
 }
 
 // closeType is used to close all subscriptions of given type
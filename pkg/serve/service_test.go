@@ -0,0 +1,607 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package serve_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/jmoiron/sqlx"
+	"github.com/mailgun/groupcache/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth/test_helpers"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/serve"
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+var _ = Describe("Service", func() {
+	var (
+		db          *sqlx.DB
+		server      serve.Server
+		payloadChan chan eth.ConvertedPayload
+	)
+
+	// newServer creates a server, applying the configure func to its config if provided, and starts serving
+	newServer := func(configure func(config *serve.Config)) serve.Server {
+		config := &serve.Config{
+			DB:          db,
+			ChainConfig: params.TestChainConfig,
+			RPCGasCap:   big.NewInt(10000000000),
+			GroupCache: &shared.GroupCacheConfig{
+				StateDB: shared.GroupConfig{
+					Name:              "serve_test",
+					CacheSizeInMB:     8,
+					CacheExpiryInMins: 60,
+				},
+			},
+		}
+		if configure != nil {
+			configure(config)
+		}
+		s, err := serve.NewServer(config)
+		Expect(err).ToNot(HaveOccurred())
+		payloadChan = make(chan eth.ConvertedPayload)
+		s.Serve(new(sync.WaitGroup), payloadChan)
+		return s
+	}
+
+	subscribe := func(params eth.SubscriptionSettings) (chan serve.SubscriptionPayload, chan error) {
+		payloadChan := make(chan serve.SubscriptionPayload, 1)
+		quitChan := make(chan error, 1)
+		server.Subscribe(rpc.NewID(), payloadChan, quitChan, params)
+		return payloadChan, quitChan
+	}
+
+	stateParams := eth.SubscriptionSettings{
+		Start:         big.NewInt(0),
+		End:           big.NewInt(0),
+		StorageFilter: eth.StorageFilter{Off: true},
+	}
+	storageParams := eth.SubscriptionSettings{
+		Start:       big.NewInt(0),
+		End:         big.NewInt(0),
+		StateFilter: eth.StateFilter{Off: true},
+	}
+
+	BeforeEach(func() {
+		db = shared.SetupDB()
+	})
+	AfterEach(func() {
+		Expect(server.Stop()).To(Succeed())
+		groupcache.DeregisterGroup("serve_test")
+		shared.TearDownDB(db)
+	})
+
+	Describe("Serve", func() {
+		newWarmUpServer := func(warmUpHead bool) serve.Server {
+			return newServer(func(config *serve.Config) {
+				config.WarmUpHead = warmUpHead
+				config.GroupCache.HeaderCacheSize = 8
+			})
+		}
+
+		BeforeEach(func() {
+			indexer := shared.SetupTestStateDiffIndexer(context.Background(), params.TestChainConfig, test_helpers.Genesis.Hash())
+			tx, err := indexer.PushBlock(test_helpers.MockBlock, test_helpers.MockReceipts, test_helpers.MockBlock.Difficulty())
+			Expect(err).ToNot(HaveOccurred())
+			err = tx.Submit(err)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Caches the head header before serving when warm-up is enabled", func() {
+			server = newWarmUpServer(true)
+
+			header, ok := server.Backend().HeaderCache.Get(test_helpers.MockBlock.Hash())
+			Expect(ok).To(BeTrue())
+			Expect(header.Hash()).To(Equal(test_helpers.MockBlock.Hash()))
+		})
+
+		It("Leaves the header cache cold when warm-up is disabled", func() {
+			server = newWarmUpServer(false)
+
+			_, ok := server.Backend().HeaderCache.Get(test_helpers.MockBlock.Hash())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Subscribe", func() {
+		It("Rejects state subscriptions when they are disabled", func() {
+			server = newServer(func(config *serve.Config) {
+				config.DisableStateSubscriptions = true
+			})
+
+			payloadChan, quitChan := subscribe(stateParams)
+			var payload serve.SubscriptionPayload
+			Expect(payloadChan).To(Receive(&payload))
+			Expect(payload.Err).To(ContainSubstring("state subscriptions are disabled"))
+			Expect(quitChan).To(Receive())
+
+			_, quitChan = subscribe(storageParams)
+			Consistently(quitChan, 100*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("Rejects storage subscriptions when they are disabled", func() {
+			server = newServer(func(config *serve.Config) {
+				config.DisableStorageSubscriptions = true
+			})
+
+			payloadChan, quitChan := subscribe(storageParams)
+			var payload serve.SubscriptionPayload
+			Expect(payloadChan).To(Receive(&payload))
+			Expect(payload.Err).To(ContainSubstring("storage subscriptions are disabled"))
+			Expect(quitChan).To(Receive())
+
+			_, quitChan = subscribe(stateParams)
+			Consistently(quitChan, 100*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("Accepts state and storage subscriptions when they are enabled", func() {
+			server = newServer(nil)
+
+			for _, params := range []eth.SubscriptionSettings{stateParams, storageParams} {
+				payloadChan, quitChan := subscribe(params)
+				Consistently(quitChan, 100*time.Millisecond).ShouldNot(Receive())
+				Expect(payloadChan).ToNot(Receive())
+			}
+		})
+	})
+
+	Describe("Stop", func() {
+		var retriever *blockingRetriever
+
+		newDrainingServer := func(drainTimeout time.Duration) serve.Server {
+			s := newServer(func(config *serve.Config) {
+				config.DrainTimeout = drainTimeout
+			})
+			retriever = &blockingRetriever{
+				release:   make(chan struct{}),
+				retrieved: make(chan int64, 10),
+			}
+			s.(*serve.Service).Retriever = retriever
+			return s
+		}
+
+		backFillParams := eth.SubscriptionSettings{
+			BackFill:      true,
+			Start:         big.NewInt(0),
+			End:           big.NewInt(0),
+			StateFilter:   eth.StateFilter{Off: true},
+			StorageFilter: eth.StorageFilter{Off: true},
+		}
+
+		It("Waits for an in-flight backfill to finish its current block and send its completion notice", func() {
+			server = newDrainingServer(time.Minute)
+
+			payloadChan, quitChan := subscribe(backFillParams)
+			Eventually(retriever.retrieved).Should(Receive(Equal(int64(1))))
+
+			stopped := make(chan error, 1)
+			go func() { stopped <- server.Stop() }()
+			Consistently(stopped, 100*time.Millisecond).ShouldNot(Receive())
+
+			close(retriever.release)
+			Eventually(stopped).Should(Receive(BeNil()))
+
+			var payload serve.SubscriptionPayload
+			Expect(payloadChan).To(Receive(&payload))
+			Expect(payload.Flag).To(Equal(serve.BackFillCompleteFlag))
+			Expect(payload.Err).To(BeEmpty())
+			Expect(quitChan).To(Receive())
+			// no block is retrieved after the shutdown signal
+			Expect(retriever.retrieved).ToNot(Receive())
+		})
+
+		It("Force-closes the subscriptions once the drain timeout elapses", func() {
+			server = newDrainingServer(100 * time.Millisecond)
+			defer close(retriever.release)
+
+			_, quitChan := subscribe(backFillParams)
+			Eventually(retriever.retrieved).Should(Receive())
+
+			Expect(server.Stop()).To(Succeed())
+			Expect(quitChan).To(Receive())
+		})
+
+		It("Rejects new subscriptions once stopped", func() {
+			server = newDrainingServer(time.Minute)
+			Expect(server.Stop()).To(Succeed())
+
+			payloadChan, quitChan := subscribe(backFillParams)
+			var payload serve.SubscriptionPayload
+			Expect(payloadChan).To(Receive(&payload))
+			Expect(payload.Err).To(ContainSubstring("shutting down"))
+			Expect(quitChan).To(Receive())
+		})
+	})
+
+	Describe("slow consumers", func() {
+		newSlowConsumerServer := func(policy serve.SlowConsumerPolicy, timeout time.Duration) serve.Server {
+			return newServer(func(config *serve.Config) {
+				config.SlowConsumerPolicy = policy
+				config.SlowConsumerTimeout = timeout
+			})
+		}
+
+		subscriptionCount := func() int {
+			service := server.(*serve.Service)
+			service.Lock()
+			defer service.Unlock()
+			return len(service.Subscriptions) + len(service.HeadSubscriptions) + len(service.LogsSubscriptions)
+		}
+
+		It("Closes a subscription whose channel is full rather than skipping payloads", func() {
+			server = newSlowConsumerServer(serve.CloseSlowConsumers, time.Second)
+
+			// the subscription channel is never read, its single slot holds the first payload
+			subPayloadChan, quitChan := subscribe(storageParams)
+			Expect(subscriptionCount()).To(Equal(1))
+			payloadChan <- test_helpers.MockConvertedPayload
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			Eventually(quitChan).Should(Receive(MatchError(ContainSubstring("slow consumer"))))
+			Eventually(subscriptionCount).Should(Equal(0))
+
+			// later payloads are not delivered to the closed subscription
+			payloadChan <- test_helpers.MockConvertedPayload
+			var payload serve.SubscriptionPayload
+			Expect(subPayloadChan).To(Receive(&payload))
+			Expect(payload.Err).To(BeEmpty())
+			Expect(payload.Height).To(Equal(test_helpers.MockBlock.Number().Int64()))
+			Consistently(subPayloadChan, 100*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("Closes a subscription whose channel stays full for the timeout under the block policy", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, 100*time.Millisecond)
+
+			_, quitChan := subscribe(storageParams)
+			payloadChan <- test_helpers.MockConvertedPayload
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			Eventually(quitChan).Should(Receive())
+			Eventually(subscriptionCount).Should(Equal(0))
+		})
+
+		It("Closes a subscription under its own policy rather than the server's", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, time.Minute)
+
+			params := storageParams
+			params.SlowConsumerPolicy = string(serve.CloseSlowConsumers)
+			_, quitChan := subscribe(params)
+			payloadChan <- test_helpers.MockConvertedPayload
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			Eventually(quitChan).Should(Receive(MatchError(ContainSubstring("slow consumer"))))
+			Eventually(subscriptionCount).Should(Equal(0))
+		})
+
+		It("Rejects a subscription with an unrecognized policy", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, time.Minute)
+
+			params := storageParams
+			params.SlowConsumerPolicy = "drop"
+			subPayloadChan, quitChan := subscribe(params)
+
+			var payload serve.SubscriptionPayload
+			Expect(subPayloadChan).To(Receive(&payload))
+			Expect(payload.Err).To(ContainSubstring("unrecognized slow consumer policy"))
+			Expect(quitChan).To(Receive(BeNil()))
+			Expect(subscriptionCount()).To(Equal(0))
+		})
+
+		It("Waits for a subscription which catches up within the timeout under the block policy", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, time.Minute)
+
+			subPayloadChan, quitChan := subscribe(storageParams)
+			go func() {
+				defer GinkgoRecover()
+				for i := 0; i < 2; i++ {
+					payloadChan <- test_helpers.MockConvertedPayload
+				}
+			}()
+
+			time.Sleep(100 * time.Millisecond)
+			for i := 0; i < 2; i++ {
+				var payload serve.SubscriptionPayload
+				Eventually(subPayloadChan).Should(Receive(&payload))
+				Expect(payload.Err).To(BeEmpty())
+			}
+			Expect(quitChan).ToNot(Receive())
+			Expect(subscriptionCount()).To(Equal(1))
+		})
+
+		It("Closes a newHeads subscription whose channel stays full", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, 100*time.Millisecond)
+
+			headerChan := make(chan *types.Header, 1)
+			quitChan := make(chan error, 1)
+			server.SubscribeNewHeads(rpc.NewID(), headerChan, quitChan)
+			payloadChan <- test_helpers.MockConvertedPayload
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			Eventually(quitChan).Should(Receive())
+			Eventually(subscriptionCount).Should(Equal(0))
+			Expect(headerChan).To(HaveLen(1))
+		})
+
+		It("Closes a logs subscription whose channel stays full", func() {
+			server = newSlowConsumerServer(serve.CloseSlowConsumers, time.Second)
+
+			logsChan := make(chan []*types.Log, 1)
+			quitChan := make(chan error, 1)
+			server.SubscribeLogs(rpc.NewID(), eth.ReceiptFilter{}, logsChan, quitChan)
+			payloadChan <- test_helpers.MockConvertedPayload
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			Eventually(quitChan).Should(Receive())
+			Eventually(subscriptionCount).Should(Equal(0))
+			Expect(logsChan).To(HaveLen(1))
+		})
+
+		It("Closes a backfilling subscription whose channel stays full", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, 100*time.Millisecond)
+			retriever := &blockingRetriever{
+				release:   make(chan struct{}),
+				retrieved: make(chan int64, 10),
+			}
+			close(retriever.release)
+			server.(*serve.Service).Retriever = retriever
+
+			// the subscription's channel is full before the backfill starts, so its completion notice can't be sent
+			subPayloadChan := make(chan serve.SubscriptionPayload, 1)
+			subPayloadChan <- serve.SubscriptionPayload{}
+			quitChan := make(chan error, 1)
+			server.Subscribe(rpc.NewID(), subPayloadChan, quitChan, eth.SubscriptionSettings{
+				BackFill:      true,
+				Start:         big.NewInt(0),
+				End:           big.NewInt(0),
+				StateFilter:   eth.StateFilter{Off: true},
+				StorageFilter: eth.StorageFilter{Off: true},
+			})
+
+			Eventually(quitChan).Should(Receive())
+			Eventually(subscriptionCount).Should(Equal(0))
+			var payload serve.SubscriptionPayload
+			Expect(subPayloadChan).To(Receive(&payload))
+			Expect(payload.Flag).ToNot(Equal(serve.BackFillCompleteFlag))
+		})
+
+		It("Serves the other subscriptions without holding the subscriptions lock while waiting on a slow consumer", func() {
+			server = newSlowConsumerServer(serve.BlockSlowConsumers, 5*time.Second)
+
+			_, slowQuitChan := subscribe(storageParams)
+			headerChan := make(chan *types.Header, 2)
+			server.SubscribeNewHeads(rpc.NewID(), headerChan, make(chan error, 1))
+			payloadChan <- test_helpers.MockConvertedPayload
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			// the second payload is waiting on the slow consumer
+			Eventually(headerChan).Should(HaveLen(2))
+			done := make(chan int, 1)
+			go func() { done <- subscriptionCount() }()
+			Eventually(done, time.Second).Should(Receive(Equal(2)))
+			Expect(slowQuitChan).ToNot(Receive())
+		})
+	})
+
+	Describe("vdb_subscribe stream", func() {
+		It("Sends the error a subscription is closed for to the subscriber", func() {
+			rpcServer := rpc.NewServer()
+			defer rpcServer.Stop()
+			closing := &closingServer{err: errors.New("subscription closed as a slow consumer")}
+			Expect(rpcServer.RegisterName(serve.APIName, serve.NewPublicServerAPI(closing, nil))).To(Succeed())
+			httpServer := httptest.NewServer(rpcServer.WebsocketHandler([]string{"*"}))
+			defer httpServer.Close()
+
+			client, err := rpc.Dial("ws://" + strings.TrimPrefix(httpServer.URL, "http://"))
+			Expect(err).ToNot(HaveOccurred())
+			defer client.Close()
+
+			payloads := make(chan serve.SubscriptionPayload, 1)
+			sub, err := client.Subscribe(context.Background(), serve.APIName, payloads, "stream", eth.SubscriptionSettings{})
+			Expect(err).ToNot(HaveOccurred())
+			defer sub.Unsubscribe()
+
+			var payload serve.SubscriptionPayload
+			Eventually(payloads).Should(Receive(&payload))
+			Expect(payload.Err).To(Equal(closing.err.Error()))
+		})
+	})
+
+	Describe("eth_subscribe newHeads", func() {
+		It("Streams the header of each served payload over websocket", func() {
+			server = newServer(nil)
+
+			rpcServer := rpc.NewServer()
+			defer rpcServer.Stop()
+			Expect(rpcServer.RegisterName(eth.APIName, serve.NewPublicEthSubscriptionAPI(server))).To(Succeed())
+			httpServer := httptest.NewServer(rpcServer.WebsocketHandler([]string{"*"}))
+			defer httpServer.Close()
+
+			client, err := rpc.Dial("ws://" + strings.TrimPrefix(httpServer.URL, "http://"))
+			Expect(err).ToNot(HaveOccurred())
+			defer client.Close()
+
+			headers := make(chan *types.Header, 1)
+			sub, err := client.EthSubscribe(context.Background(), headers, "newHeads")
+			Expect(err).ToNot(HaveOccurred())
+			defer sub.Unsubscribe()
+
+			// the subscription is registered with the service asynchronously
+			service := server.(*serve.Service)
+			Eventually(func() int {
+				service.Lock()
+				defer service.Unlock()
+				return len(service.HeadSubscriptions)
+			}).Should(Equal(1))
+
+			payloadChan <- eth.ConvertedPayload{Block: test_helpers.MockBlock}
+
+			var header *types.Header
+			Eventually(headers).Should(Receive(&header))
+			Expect(header.Hash()).To(Equal(test_helpers.MockBlock.Hash()))
+			Expect(header.Number).To(Equal(test_helpers.MockBlock.Number()))
+		})
+	})
+
+	Describe("eth_subscribe logs", func() {
+		var (
+			rpcServer  *rpc.Server
+			httpServer *httptest.Server
+			client     *rpc.Client
+		)
+
+		BeforeEach(func() {
+			server = newServer(nil)
+
+			rpcServer = rpc.NewServer()
+			Expect(rpcServer.RegisterName(eth.APIName, serve.NewPublicEthSubscriptionAPI(server))).To(Succeed())
+			httpServer = httptest.NewServer(rpcServer.WebsocketHandler([]string{"*"}))
+
+			var err error
+			client, err = rpc.Dial("ws://" + strings.TrimPrefix(httpServer.URL, "http://"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			client.Close()
+			httpServer.Close()
+			rpcServer.Stop()
+		})
+
+		// subscribeAndServe subscribes to logs with the provided criteria, serves the mock payload
+		// and returns the logs delivered to the subscription
+		subscribeAndServe := func(crit map[string]interface{}, expectedCount int) []types.Log {
+			logs := make(chan types.Log, 10)
+			sub, err := client.EthSubscribe(context.Background(), logs, "logs", crit)
+			Expect(err).ToNot(HaveOccurred())
+			defer sub.Unsubscribe()
+
+			// the subscription is registered with the service asynchronously
+			service := server.(*serve.Service)
+			Eventually(func() int {
+				service.Lock()
+				defer service.Unlock()
+				return len(service.LogsSubscriptions)
+			}).Should(Equal(1))
+
+			payloadChan <- test_helpers.MockConvertedPayload
+
+			received := make([]types.Log, expectedCount)
+			for i := range received {
+				Eventually(logs).Should(Receive(&received[i]))
+			}
+			Consistently(logs, 200*time.Millisecond).ShouldNot(Receive())
+			return received
+		}
+
+		It("Delivers only the logs emitted by the subscribed addresses", func() {
+			logs := subscribeAndServe(map[string]interface{}{
+				"address": []common.Address{test_helpers.AnotherAddress1},
+			}, 3)
+
+			for i, l := range logs {
+				Expect(l.Address).To(Equal(test_helpers.AnotherAddress1))
+				Expect(l.Index).To(Equal(uint(2 + i)))
+				Expect(l.TxIndex).To(Equal(uint(2)))
+				Expect(l.TxHash).To(Equal(test_helpers.MockTransactions[2].Hash()))
+				Expect(l.BlockHash).To(Equal(test_helpers.MockBlock.Hash()))
+				Expect(l.BlockNumber).To(Equal(test_helpers.MockBlock.NumberU64()))
+			}
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog3.Topics))
+			Expect(logs[1].Topics).To(Equal(test_helpers.MockLog4.Topics))
+			Expect(logs[2].Topics).To(Equal(test_helpers.MockLog5.Topics))
+		})
+
+		It("Delivers all of the logs when the address list is empty", func() {
+			logs := subscribeAndServe(map[string]interface{}{
+				"address": []common.Address{},
+			}, 6)
+
+			for i, l := range logs {
+				Expect(l.Index).To(Equal(uint(i)))
+			}
+			Expect(logs[0].Address).To(Equal(test_helpers.MockLog1.Address))
+			Expect(logs[5].Address).To(Equal(test_helpers.MockLog6.Address))
+		})
+
+		It("Delivers the logs matching a topic filter with gaps", func() {
+			logs := subscribeAndServe(map[string]interface{}{
+				"topics": []interface{}{
+					nil,
+					[]common.Hash{test_helpers.MockLog1.Topics[1], test_helpers.MockLog4.Topics[1]},
+				},
+			}, 2)
+
+			Expect(logs[0].Index).To(Equal(uint(0)))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog1.Topics))
+			Expect(logs[1].Index).To(Equal(uint(3)))
+			Expect(logs[1].Topics).To(Equal(test_helpers.MockLog4.Topics))
+		})
+
+		It("Delivers the logs matching both the addresses and the topics", func() {
+			logs := subscribeAndServe(map[string]interface{}{
+				"address": []common.Address{test_helpers.AnotherAddress1},
+				"topics":  [][]common.Hash{{test_helpers.MockLog5.Topics[0]}},
+			}, 1)
+
+			Expect(logs[0].Index).To(Equal(uint(4)))
+			Expect(logs[0].Address).To(Equal(test_helpers.AnotherAddress1))
+			Expect(logs[0].Topics).To(Equal(test_helpers.MockLog5.Topics))
+		})
+	})
+})
+
+// blockingRetriever holds the retrieval of each block of a backfill over blocks 1 to 10 until it is released
+type blockingRetriever struct {
+	release   chan struct{}
+	retrieved chan int64
+}
+
+func (r *blockingRetriever) RetrieveFirstBlockNumber() (int64, error) {
+	return 1, nil
+}
+
+func (r *blockingRetriever) RetrieveLastBlockNumber() (int64, error) {
+	return 10, nil
+}
+
+func (r *blockingRetriever) Retrieve(filter eth.SubscriptionSettings, blockNumber int64) ([]eth.CIDWrapper, bool, error) {
+	r.retrieved <- blockNumber
+	<-r.release
+	return nil, true, nil
+}
+
+// closingServer closes each stream subscription with its error as soon as it is made
+type closingServer struct {
+	serve.Server
+	err error
+}
+
+func (s *closingServer) Subscribe(id rpc.ID, sub chan<- serve.SubscriptionPayload, quitChan chan<- error, params eth.SubscriptionSettings) {
+	quitChan <- s.err
+}
@@ -18,8 +18,12 @@ package serve
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/eth"
 )
 
 type Flag int32
@@ -29,11 +33,52 @@ const (
 	BackFillCompleteFlag
 )
 
+// SlowConsumerPolicy determines how a subscription whose channel is full is handled; its payloads are never skipped,
+// the subscription is closed instead
+type SlowConsumerPolicy string
+
+const (
+	// CloseSlowConsumers closes a subscription as soon as a payload can't be sent to it
+	CloseSlowConsumers SlowConsumerPolicy = "close"
+	// BlockSlowConsumers waits up to the slow consumer timeout for a payload to be received before closing the subscription;
+	// a zero timeout closes it immediately
+	BlockSlowConsumers SlowConsumerPolicy = "block"
+)
+
+// ParseSlowConsumerPolicy parses the provided string into a SlowConsumerPolicy, an empty string defaults to blocking
+func ParseSlowConsumerPolicy(policy string) (SlowConsumerPolicy, error) {
+	switch SlowConsumerPolicy(policy) {
+	case "", BlockSlowConsumers:
+		return BlockSlowConsumers, nil
+	case CloseSlowConsumers:
+		return CloseSlowConsumers, nil
+	default:
+		return "", fmt.Errorf("unrecognized slow consumer policy: %s", policy)
+	}
+}
+
 // Subscription holds the information for an individual client subscription to the watcher
+// The quit channel is sent the error the subscription is closed for, or nil if it is closed without one
 type Subscription struct {
 	ID          rpc.ID
 	PayloadChan chan<- SubscriptionPayload
-	QuitChan    chan<- bool
+	QuitChan    chan<- error
+	Policy      SlowConsumerPolicy
+}
+
+// HeadSubscription holds the information for an individual client subscription to the headers of the served payloads
+type HeadSubscription struct {
+	ID         rpc.ID
+	HeaderChan chan<- *types.Header
+	QuitChan   chan<- error
+}
+
+// LogsSubscription holds the information for an individual client subscription to the logs of the served payloads
+type LogsSubscription struct {
+	ID       rpc.ID
+	Filter   eth.ReceiptFilter
+	LogsChan chan<- []*types.Log
+	QuitChan chan<- error
 }
 
 // SubscriptionPayload is the struct for a watcher data subscription payload
@@ -23,10 +23,20 @@ const (
 	DefaultMaxBatchNumber   int64         = 50
 	DefaultStateDiffTimeout time.Duration = 240 * time.Second
 
+	DefaultReplicaRetryInterval time.Duration = 30 * time.Second
+
+	DefaultLogsMaxBlockRange int64 = 10000
+
+	// DefaultHeaderCacheFinalizedDepth is the depth below the highest header seen at which the header cache indexes
+	// canonical headers by number
+	DefaultHeaderCacheFinalizedDepth uint64 = 64
+
 	GcachePoolEnabled             = "GCACHE_POOL_ENABLED"
 	GcachePoolHttpPath            = "GCACHE_POOL_HTTP_PATH"
 	GcachePoolHttpPeers           = "GCACHE_POOL_HTTP_PEERS"
 	GcacheStatedbCacheSize        = "GCACHE_STATEDB_CACHE_SIZE"
 	GcacheStatedbCacheExpiry      = "GCACHE_STATEDB_CACHE_EXPIRY"
 	GcacheStatedbLogStatsInterval = "GCACHE_STATEDB_LOG_STATS_INTERVAL"
+	GcacheHeaderCacheSize         = "GCACHE_HEADER_CACHE_SIZE"
+	GcacheHeaderCacheDepth        = "GCACHE_HEADER_CACHE_FINALIZED_DEPTH"
 )
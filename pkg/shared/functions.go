@@ -41,6 +41,25 @@ func HandleZeroAddr(to common.Address) string {
 	return to.Hex()
 }
 
+// MissingHeightsToGaps collapses a sorted slice of block heights into the gaps of consecutive heights it covers
+func MissingHeightsToGaps(heights []uint64) []Gap {
+	gaps := make([]Gap, 0)
+	for _, height := range heights {
+		if len(gaps) > 0 {
+			last := &gaps[len(gaps)-1]
+			if height <= last.Stop {
+				continue
+			}
+			if height == last.Stop+1 {
+				last.Stop = height
+				continue
+			}
+		}
+		gaps = append(gaps, Gap{Start: height, Stop: height})
+	}
+	return gaps
+}
+
 // Rollback sql transaction and log any error
 func Rollback(tx *sqlx.Tx) {
 	if err := tx.Rollback(); err != nil {
@@ -0,0 +1,165 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/statediff/indexer/database/sql/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/log"
+)
+
+// ReplicaMode determines how connections are distributed across a database and its read replicas
+type ReplicaMode string
+
+const (
+	// RoundRobinReplicaMode distributes new connections evenly across all available databases
+	RoundRobinReplicaMode ReplicaMode = "round-robin"
+	// PrimaryFallbackReplicaMode connects to the first available database, in the order they were provided
+	PrimaryFallbackReplicaMode ReplicaMode = "primary-fallback"
+)
+
+// ParseReplicaMode parses the provided string into a ReplicaMode, an empty string defaults to round-robin
+func ParseReplicaMode(mode string) (ReplicaMode, error) {
+	switch ReplicaMode(mode) {
+	case "", RoundRobinReplicaMode:
+		return RoundRobinReplicaMode, nil
+	case PrimaryFallbackReplicaMode:
+		return PrimaryFallbackReplicaMode, nil
+	default:
+		return "", fmt.Errorf("unrecognized database replica mode: %s", mode)
+	}
+}
+
+var errNoReplicas = errors.New("no databases configured")
+
+// ReplicaConnector implements driver.Connector, opening new connections across a set of databases.
+// A database that fails to connect is dropped from the rotation until the retry interval elapses;
+// it is only tried before then if none of the other databases can be connected to.
+type ReplicaConnector struct {
+	connectors    []driver.Connector
+	mode          ReplicaMode
+	retryInterval time.Duration
+
+	mu        sync.Mutex
+	next      int
+	downUntil []time.Time
+}
+
+// NewReplicaConnector creates a new ReplicaConnector for the provided connectors
+func NewReplicaConnector(connectors []driver.Connector, mode ReplicaMode, retryInterval time.Duration) *ReplicaConnector {
+	return &ReplicaConnector{
+		connectors:    connectors,
+		mode:          mode,
+		retryInterval: retryInterval,
+		downUntil:     make([]time.Time, len(connectors)),
+	}
+}
+
+// Connect implements driver.Connector
+func (rc *ReplicaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	err := errNoReplicas
+	for _, i := range rc.candidates() {
+		var conn driver.Conn
+		conn, err = rc.connectors[i].Connect(ctx)
+		if err == nil {
+			rc.markUp(i)
+			return conn, nil
+		}
+		log.Warnf("database %d unavailable, dropping it from rotation: %v", i, err)
+		rc.markDown(i)
+	}
+	return nil, err
+}
+
+// Driver implements driver.Connector
+func (rc *ReplicaConnector) Driver() driver.Driver {
+	return rc.connectors[0].Driver()
+}
+
+// candidates returns the indexes of the databases in the order they should be tried
+func (rc *ReplicaConnector) candidates() []int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	n := len(rc.connectors)
+	start := 0
+	if rc.mode == RoundRobinReplicaMode && n > 0 {
+		start = rc.next
+		rc.next = (rc.next + 1) % n
+	}
+	now := time.Now()
+	available := make([]int, 0, n)
+	down := make([]int, 0)
+	for j := 0; j < n; j++ {
+		i := (start + j) % n
+		if now.Before(rc.downUntil[i]) {
+			down = append(down, i)
+			continue
+		}
+		available = append(available, i)
+	}
+	return append(available, down...)
+}
+
+func (rc *ReplicaConnector) markDown(i int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.downUntil[i] = time.Now().Add(rc.retryInterval)
+}
+
+func (rc *ReplicaConnector) markUp(i int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.downUntil[i] = time.Time{}
+}
+
+// NewReplicaDB creates a single db handle whose connection pool is spread across the provided databases
+func NewReplicaDB(connectStrings []string, config postgres.Config, mode ReplicaMode, retryInterval time.Duration) (*sqlx.DB, error) {
+	connectors := make([]driver.Connector, len(connectStrings))
+	for i, connectString := range connectStrings {
+		connector, err := pq.NewConnector(connectString)
+		if err != nil {
+			return nil, postgres.ErrDBConnectionFailed(err)
+		}
+		connectors[i] = connector
+	}
+	db := sqlx.NewDb(sql.OpenDB(NewReplicaConnector(connectors, mode, retryInterval)), "postgres")
+	if err := db.Ping(); err != nil {
+		return nil, postgres.ErrDBConnectionFailed(err)
+	}
+	if config.MaxConns > 0 {
+		db.SetMaxOpenConns(config.MaxConns)
+	}
+	if config.MaxIdle > 0 {
+		db.SetMaxIdleConns(config.MaxIdle)
+	}
+	if config.MaxConnLifetime > 0 {
+		db.SetConnMaxLifetime(config.MaxConnLifetime)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,139 @@
+// VulcanizeDB
+// Copyright © 2022 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package shared_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cerc-io/ipld-eth-server/v4/pkg/shared"
+)
+
+// mockConnector is a driver.Connector for a mock database which counts the connections made to it
+type mockConnector struct {
+	connections int
+	down        bool
+}
+
+func (mc *mockConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if mc.down {
+		return nil, errors.New("connection refused")
+	}
+	mc.connections++
+	return mockConn{}, nil
+}
+
+func (mc *mockConnector) Driver() driver.Driver {
+	return nil
+}
+
+type mockConn struct{}
+
+func (mockConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (mockConn) Close() error                              { return nil }
+func (mockConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+var _ = Describe("ReplicaConnector", func() {
+	var (
+		ctx       = context.Background()
+		primary   *mockConnector
+		replica   *mockConnector
+		connector *shared.ReplicaConnector
+	)
+
+	connect := func(n int) {
+		for i := 0; i < n; i++ {
+			_, err := connector.Connect(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	}
+
+	BeforeEach(func() {
+		primary = &mockConnector{}
+		replica = &mockConnector{}
+	})
+
+	Describe("round-robin", func() {
+		BeforeEach(func() {
+			connector = shared.NewReplicaConnector([]driver.Connector{primary, replica}, shared.RoundRobinReplicaMode, time.Minute)
+		})
+
+		It("Distributes connections across the databases", func() {
+			connect(10)
+			Expect(primary.connections).To(Equal(5))
+			Expect(replica.connections).To(Equal(5))
+		})
+
+		It("Drops a database that goes down from the rotation", func() {
+			connect(2)
+			replica.down = true
+			connect(6)
+			Expect(primary.connections).To(Equal(7))
+			Expect(replica.connections).To(Equal(1))
+
+			// the replica is not retried until the retry interval has elapsed
+			replica.down = false
+			connect(4)
+			Expect(primary.connections).To(Equal(11))
+			Expect(replica.connections).To(Equal(1))
+		})
+
+		It("Returns the rotation to a database once the retry interval has elapsed", func() {
+			connector = shared.NewReplicaConnector([]driver.Connector{primary, replica}, shared.RoundRobinReplicaMode, time.Millisecond)
+			replica.down = true
+			connect(2)
+			Expect(primary.connections).To(Equal(2))
+
+			replica.down = false
+			time.Sleep(5 * time.Millisecond)
+			connect(4)
+			Expect(replica.connections).To(Equal(2))
+		})
+
+		It("Returns an error if none of the databases are available", func() {
+			primary.down = true
+			replica.down = true
+			_, err := connector.Connect(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("primary-fallback", func() {
+		BeforeEach(func() {
+			connector = shared.NewReplicaConnector([]driver.Connector{primary, replica}, shared.PrimaryFallbackReplicaMode, time.Minute)
+		})
+
+		It("Connects to the primary database while it is available", func() {
+			connect(4)
+			Expect(primary.connections).To(Equal(4))
+			Expect(replica.connections).To(Equal(0))
+		})
+
+		It("Falls back to the replica when the primary goes down", func() {
+			connect(2)
+			primary.down = true
+			connect(3)
+			Expect(primary.connections).To(Equal(2))
+			Expect(replica.connections).To(Equal(3))
+		})
+	})
+})
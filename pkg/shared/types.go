@@ -35,4 +35,15 @@ type GroupConfig struct {
 type GroupCacheConfig struct {
 	Pool    PoolConfig
 	StateDB GroupConfig
+
+	// Number of decoded headers kept in the backend's LRU header cache (0 disables it)
+	HeaderCacheSize int
+	// Depth below the highest header seen at which canonical headers are cached by number (0 uses the default)
+	HeaderCacheFinalizedDepth uint64
+}
+
+// Gap is an inclusive range of block heights missing from, or insufficiently validated in, the database
+type Gap struct {
+	Start uint64
+	Stop  uint64
 }